@@ -43,6 +43,7 @@ var defaultImages = map[string]string{
 	"prometheus":                 "",
 	"alertmanager":               "",
 	"thanos":                     obopo.DefaultThanosImage,
+	"oauth-proxy":                "quay.io/openshift/origin-oauth-proxy:4.17",
 	"ui-dashboards":              "quay.io/openshift-observability-ui/console-dashboards-plugin:v0.4.2",
 	"ui-troubleshooting-panel":   "quay.io/openshift-observability-ui/troubleshooting-panel-console-plugin:v0.4.4",
 	"ui-distributed-tracing-pf4": "quay.io/openshift-observability-ui/distributed-tracing-console-plugin:v0.3.2",
@@ -52,6 +53,8 @@ var defaultImages = map[string]string{
 	"ui-logging-pf5":             "quay.io/openshift-observability-ui/logging-view-plugin:v6.1.5",
 	"ui-logging":                 "quay.io/openshift-observability-ui/logging-view-plugin:v6.2.0",
 	"korrel8r":                   "quay.io/korrel8r/korrel8r:0.9.1",
+	"loki-proxy":                 "registry.access.redhat.com/ubi9/nginx-124:9.6",
+	"kube-rbac-proxy":            "quay.io/brancz/kube-rbac-proxy:v0.19.1",
 	"health-analyzer":            "quay.io/openshiftanalytics/cluster-health-analyzer:v1.1.1-rc.0",
 	"ui-monitoring-pf5":          "quay.io/openshift-observability-ui/monitoring-console-plugin:v0.4.4",
 	"ui-monitoring":              "quay.io/openshift-observability-ui/monitoring-console-plugin:v0.5.3",
@@ -164,6 +167,7 @@ func main() {
 			operator.WithAlertmanagerImage(imgMap["alertmanager"]),
 			operator.WithThanosSidecarImage(imgMap["thanos"]),
 			operator.WithThanosQuerierImage(imgMap["thanos"]),
+			operator.WithOAuthProxyImage(imgMap["oauth-proxy"]),
 			operator.WithUIPluginImages(imgMap),
 			operator.WithObservabilityInstaller(operator.ObservabilityInstallerConfiguration{
 				COONamespace:     os.Getenv("NAMESPACE"),
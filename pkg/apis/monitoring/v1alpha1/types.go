@@ -8,6 +8,7 @@ import (
 	monv1 "github.com/rhobs/obo-prometheus-operator/pkg/apis/monitoring/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -250,6 +251,11 @@ type PrometheusConfig struct {
 	// Configure TLS options for the Prometheus web server.
 	// +optional
 	WebTLSConfig *WebTLSConfig `json:"webTLSConfig,omitempty"`
+	// Configure TLS options for the gRPC server (StoreAPI) of the Thanos
+	// sidecar. ThanosQueriers selecting this MonitoringStack need a matching
+	// grpcClientTLSConfig.
+	// +optional
+	ThanosSidecarTLSConfig *GRPCServerTLSConfig `json:"thanosSidecarTLSConfig,omitempty"`
 }
 
 type AlertmanagerConfig struct {
@@ -269,8 +275,8 @@ type AlertmanagerConfig struct {
 	WebTLSConfig *WebTLSConfig `json:"webTLSConfig,omitempty"`
 }
 
-// NamespaceSelector is a selector for selecting either all namespaces or a
-// list of namespaces.
+// NamespaceSelector is a selector for selecting either all namespaces, a
+// list of namespaces or the namespaces matching a label selector.
 // +k8s:openapi-gen=true
 type NamespaceSelector struct {
 	// Boolean describing whether all namespaces are selected in contrast to a
@@ -278,6 +284,10 @@ type NamespaceSelector struct {
 	Any bool `json:"any,omitempty"`
 	// List of namespace names.
 	MatchNames []string `json:"matchNames,omitempty"`
+	// Label selector for namespaces. The namespaces matching the selector
+	// are selected in addition to the namespaces listed in matchNames.
+	// +optional
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
 }
 
 // ThanosQuerier outlines the Thanos querier components, managed by this stack
@@ -294,13 +304,15 @@ type ThanosQuerier struct {
 	Status ThanosQuerierStatus `json:"status,omitempty"`
 }
 
-func (t ThanosQuerier) MatchesNamespace(namespace string) bool {
+// MatchesNamespaceWithLabels returns true if the namespace with the given name
+// and labels is selected by the ThanosQuerier.
+func (t ThanosQuerier) MatchesNamespaceWithLabels(namespace string, namespaceLabels map[string]string) bool {
 	namespaceSelector := t.Spec.NamespaceSelector
 	if namespaceSelector.Any {
 		return true
 	}
 
-	if len(namespaceSelector.MatchNames) == 0 {
+	if len(namespaceSelector.MatchNames) == 0 && namespaceSelector.LabelSelector == nil {
 		return t.Namespace == namespace
 	}
 
@@ -310,6 +322,14 @@ func (t ThanosQuerier) MatchesNamespace(namespace string) bool {
 		}
 	}
 
+	if namespaceSelector.LabelSelector != nil && namespaceLabels != nil {
+		selector, err := metav1.LabelSelectorAsSelector(namespaceSelector.LabelSelector)
+		if err != nil {
+			return false
+		}
+		return selector.Matches(labels.Set(namespaceLabels))
+	}
+
 	return false
 }
 
@@ -350,11 +370,217 @@ type ThanosQuerierSpec struct {
 	// webTLSConfig configures the TLS options for the Thanos web server.
 	// +optional
 	WebTLSConfig *WebTLSConfig `json:"webTLSConfig,omitempty"`
+
+	// grpcClientTLSConfig configures the TLS options used by Thanos Querier
+	// to connect to the Thanos sidecars of the selected MonitoringStacks.
+	// +optional
+	GRPCClientTLSConfig *GRPCClientTLSConfig `json:"grpcClientTLSConfig,omitempty"`
+
+	// queryConfig configures the query engine of Thanos Querier.
+	// +optional
+	QueryConfig *ThanosQueryConfig `json:"queryConfig,omitempty"`
+
+	// ui configures how the Thanos Querier UI and HTTP API are exposed
+	// outside of the cluster.
+	// By default, they are only reachable through the in-cluster service.
+	// +optional
+	UI *ThanosQuerierUIConfig `json:"ui,omitempty"`
+
+	// persesDatasource registers the Thanos Querier as a Perses datasource.
+	// It requires the Perses operator CRDs to be installed.
+	// By default, no datasource is created.
+	// +optional
+	PersesDatasource *ThanosQuerierPersesDatasource `json:"persesDatasource,omitempty"`
+}
+
+// PersesDatasourceScope defines the type of Perses datasource created for a
+// ThanosQuerier.
+// +kubebuilder:validation:Enum=Global;Namespace
+type PersesDatasourceScope string
+
+const (
+	// GlobalPersesDatasource creates a PersesGlobalDatasource available to all projects.
+	GlobalPersesDatasource PersesDatasourceScope = "Global"
+	// NamespacePersesDatasource creates a PersesDatasource in the namespace of the ThanosQuerier.
+	NamespacePersesDatasource PersesDatasourceScope = "Namespace"
+)
+
+// ThanosQuerierPersesDatasource defines the Perses datasource created for a
+// ThanosQuerier.
+type ThanosQuerierPersesDatasource struct {
+	// scope defines whether a PersesGlobalDatasource or a PersesDatasource
+	// in the namespace of the ThanosQuerier is created.
+	// +kubebuilder:default=Namespace
+	// +optional
+	Scope PersesDatasourceScope `json:"scope,omitempty"`
+
+	// displayName is the name of the datasource shown in Perses.
+	// Defaults to the namespace and name of the ThanosQuerier.
+	// +optional
+	DisplayName string `json:"displayName,omitempty"`
+
+	// default marks the datasource as the default Prometheus datasource of
+	// its scope.
+	// +optional
+	Default bool `json:"default,omitempty"`
+
+	// instanceSelector selects the Perses instances in which the datasource
+	// is created. By default, the datasource is created in all instances.
+	// +optional
+	InstanceSelector *metav1.LabelSelector `json:"instanceSelector,omitempty"`
+}
+
+// UIExposureType is the type of resource used to expose a UI outside of the
+// cluster.
+// +kubebuilder:validation:Enum=Route;Ingress;HTTPRoute
+type UIExposureType string
+
+const (
+	// RouteExposure exposes the UI with an OpenShift Route.
+	RouteExposure UIExposureType = "Route"
+	// IngressExposure exposes the UI with a Kubernetes Ingress.
+	IngressExposure UIExposureType = "Ingress"
+	// HTTPRouteExposure exposes the UI with a Gateway API HTTPRoute.
+	HTTPRouteExposure UIExposureType = "HTTPRoute"
+)
+
+// ThanosQuerierUIConfig defines how the Thanos Querier UI is exposed.
+// +kubebuilder:validation:XValidation:rule="self.type == 'Route' || has(self.host)",message="host is required for Ingress and HTTPRoute"
+// +kubebuilder:validation:XValidation:rule="self.type != 'HTTPRoute' || (has(self.httpRoute) && size(self.httpRoute.parentRefs) > 0)",message="httpRoute.parentRefs is required for HTTPRoute"
+type ThanosQuerierUIConfig struct {
+	// type of the resource exposing the UI.
+	// Route requires OpenShift and HTTPRoute requires the Gateway API CRDs.
+	// +kubebuilder:validation:Required
+	Type UIExposureType `json:"type"`
+
+	// host is the hostname under which the UI is served.
+	// It is optional for Route, in which case OpenShift generates one.
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// ingress configures the Ingress resource when type is Ingress.
+	// +optional
+	Ingress *IngressConfig `json:"ingress,omitempty"`
+
+	// httpRoute configures the HTTPRoute resource when type is HTTPRoute.
+	// +optional
+	HTTPRoute *HTTPRouteConfig `json:"httpRoute,omitempty"`
+
+	// oauthProxy protects the UI with the OpenShift OAuth proxy. Only users
+	// and bearer tokens allowed to get the Thanos Querier service can access
+	// the UI.
+	// Only supported on OpenShift.
+	// +optional
+	OAuthProxy bool `json:"oauthProxy,omitempty"`
+}
+
+// IngressConfig defines the options of an Ingress resource.
+type IngressConfig struct {
+	// ingressClassName is the name of the IngressClass used by the Ingress.
+	// +optional
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+
+	// tlsSecretName is the name of the secret holding the TLS certificate
+	// served by the ingress controller for the host.
+	// +optional
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+
+	// annotations are added to the Ingress. They can configure the ingress
+	// controller, e.g. to connect over HTTPS to the Thanos Querier when the
+	// OAuth proxy or webTLSConfig is enabled. They take precedence over the
+	// annotations set by the operator.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// HTTPRouteConfig defines the options of a Gateway API HTTPRoute resource.
+type HTTPRouteConfig struct {
+	// parentRefs are the Gateways the HTTPRoute is attached to.
+	// +kubebuilder:validation:MinItems=1
+	ParentRefs []GatewayReference `json:"parentRefs"`
+}
+
+// GatewayReference references a Gateway (or one of its listeners).
+type GatewayReference struct {
+	// name of the Gateway.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// namespace of the Gateway. Defaults to the namespace of the route.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// sectionName is the name of the Gateway listener.
+	// +optional
+	SectionName string `json:"sectionName,omitempty"`
+}
+
+// DeduplicationFunc is the algorithm used to deduplicate the series of
+// highly-available replicas.
+// +kubebuilder:validation:Enum=penalty;chain
+type DeduplicationFunc string
+
+const (
+	// PenaltyDeduplication switches between replicas when gaps are detected.
+	PenaltyDeduplication DeduplicationFunc = "penalty"
+	// ChainDeduplication merges the samples of all replicas.
+	ChainDeduplication DeduplicationFunc = "chain"
+)
+
+// PromQLEngine is the engine used to evaluate PromQL queries.
+// +kubebuilder:validation:Enum=prometheus;thanos
+type PromQLEngine string
+
+const (
+	// PrometheusPromQLEngine is the upstream Prometheus PromQL engine.
+	PrometheusPromQLEngine PromQLEngine = "prometheus"
+	// ThanosPromQLEngine is the Thanos PromQL engine.
+	ThanosPromQLEngine PromQLEngine = "thanos"
+)
+
+// ThanosQueryConfig defines the query tuning options of Thanos Querier. The
+// options which aren't supported by the configured Thanos image are rejected.
+type ThanosQueryConfig struct {
+	// timeout is the maximum time to process a query.
+	// +optional
+	Timeout *monv1.Duration `json:"timeout,omitempty"`
+
+	// maxConcurrent is the maximum number of queries processed concurrently.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxConcurrent *int32 `json:"maxConcurrent,omitempty"`
+
+	// partialResponse defines whether partial responses are enabled by
+	// default when a StoreAPI endpoint is unavailable.
+	// +optional
+	PartialResponse *bool `json:"partialResponse,omitempty"`
+
+	// lookbackDelta is the maximum duration to look back for samples when
+	// evaluating instant vectors.
+	// +optional
+	LookbackDelta *monv1.Duration `json:"lookbackDelta,omitempty"`
+
+	// deduplicationFunc is the algorithm used to deduplicate the series of
+	// highly-available replicas.
+	// Requires Thanos v0.35.0 or later.
+	// +optional
+	DeduplicationFunc DeduplicationFunc `json:"deduplicationFunc,omitempty"`
+
+	// promqlEngine is the default engine used to evaluate PromQL queries.
+	// Requires Thanos v0.31.0 or later.
+	// +optional
+	PromQLEngine PromQLEngine `json:"promqlEngine,omitempty"`
 }
 
 // ThanosQuerierStatus defines the observed state of ThanosQuerier.
 // It should always be reconstructable from the state of the cluster and/or outside world.
-type ThanosQuerierStatus struct{}
+type ThanosQuerierStatus struct {
+	// Conditions provide status information about the ThanosQuerier
+	// +listType=atomic
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+}
 
 // SecretKeySelector selects a key of a secret.
 type SecretKeySelector struct {
@@ -380,3 +606,53 @@ type WebTLSConfig struct {
 	// +kubebuilder:validation:Required
 	CertificateAuthority SecretKeySelector `json:"certificateAuthority"`
 }
+
+// GRPCServerTLSConfig contains configuration to enable TLS on a gRPC server.
+// The certificates are either read from secrets or issued by the OpenShift
+// service CA operator.
+// +kubebuilder:validation:XValidation:rule="has(self.serviceCA) && self.serviceCA ? !has(self.privateKey) && !has(self.certificate) && !has(self.certificateAuthority) : has(self.privateKey) && has(self.certificate)",message="Either serviceCA or privateKey and certificate must be set"
+type GRPCServerTLSConfig struct {
+	// Use a serving certificate issued by the OpenShift service CA operator.
+	// Only supported on OpenShift.
+	// +optional
+	ServiceCA bool `json:"serviceCA,omitempty"`
+	// Reference to the TLS private key for the gRPC server.
+	// +optional
+	PrivateKey *SecretKeySelector `json:"privateKey,omitempty"`
+	// Reference to the TLS public certificate for the gRPC server.
+	// +optional
+	Certificate *SecretKeySelector `json:"certificate,omitempty"`
+	// Reference to the root Certificate Authority used to verify the client
+	// certificates. When set, clients must present a certificate signed by
+	// this CA.
+	// +optional
+	CertificateAuthority *SecretKeySelector `json:"certificateAuthority,omitempty"`
+}
+
+// GRPCClientTLSConfig contains configuration to enable TLS on gRPC client
+// connections.
+// +kubebuilder:validation:XValidation:rule="has(self.serviceCA) && self.serviceCA ? !has(self.certificateAuthority) : has(self.certificateAuthority)",message="Exactly one of serviceCA or certificateAuthority must be set"
+// +kubebuilder:validation:XValidation:rule="has(self.privateKey) == has(self.certificate)",message="privateKey and certificate must be set together"
+type GRPCClientTLSConfig struct {
+	// Verify the server certificates with the OpenShift service CA bundle.
+	// Only supported on OpenShift.
+	// +optional
+	ServiceCA bool `json:"serviceCA,omitempty"`
+	// Reference to the root Certificate Authority used to verify the server
+	// certificates.
+	// +optional
+	CertificateAuthority *SecretKeySelector `json:"certificateAuthority,omitempty"`
+	// Reference to the TLS private key presented to the servers for mutual
+	// TLS.
+	// +optional
+	PrivateKey *SecretKeySelector `json:"privateKey,omitempty"`
+	// Reference to the TLS public certificate presented to the servers for
+	// mutual TLS.
+	// +optional
+	Certificate *SecretKeySelector `json:"certificate,omitempty"`
+	// Server name used to verify the hostname of the server certificates.
+	// By default, the hostname of each resolved sidecar endpoint is verified,
+	// which matches the certificates issued by the OpenShift service CA.
+	// +optional
+	ServerName string `json:"serverName,omitempty"`
+}
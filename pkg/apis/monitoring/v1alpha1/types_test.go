@@ -0,0 +1,81 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestThanosQuerierMatchesNamespaceWithLabels(t *testing.T) {
+	tenantLabels := map[string]string{"tenant": "a"}
+
+	tests := []struct {
+		name              string
+		namespaceSelector NamespaceSelector
+		namespace         string
+		namespaceLabels   map[string]string
+		expected          bool
+	}{
+		{
+			name:      "default selects the querier namespace",
+			namespace: "querier-ns",
+			expected:  true,
+		},
+		{
+			name:      "default doesn't select other namespaces",
+			namespace: "other",
+			expected:  false,
+		},
+		{
+			name:              "any",
+			namespaceSelector: NamespaceSelector{Any: true},
+			namespace:         "other",
+			expected:          true,
+		},
+		{
+			name:              "match names",
+			namespaceSelector: NamespaceSelector{MatchNames: []string{"other"}},
+			namespace:         "other",
+			expected:          true,
+		},
+		{
+			name: "label selector matches",
+			namespaceSelector: NamespaceSelector{
+				LabelSelector: &metav1.LabelSelector{MatchLabels: tenantLabels},
+			},
+			namespace:       "tenant-a",
+			namespaceLabels: tenantLabels,
+			expected:        true,
+		},
+		{
+			name: "label selector doesn't match",
+			namespaceSelector: NamespaceSelector{
+				LabelSelector: &metav1.LabelSelector{MatchLabels: tenantLabels},
+			},
+			namespace:       "querier-ns",
+			namespaceLabels: map[string]string{"tenant": "b"},
+			expected:        false,
+		},
+		{
+			name: "label selector and match names",
+			namespaceSelector: NamespaceSelector{
+				MatchNames:    []string{"other"},
+				LabelSelector: &metav1.LabelSelector{MatchLabels: tenantLabels},
+			},
+			namespace:       "other",
+			namespaceLabels: map[string]string{"tenant": "b"},
+			expected:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			querier := ThanosQuerier{
+				ObjectMeta: metav1.ObjectMeta{Name: "querier", Namespace: "querier-ns"},
+				Spec:       ThanosQuerierSpec{NamespaceSelector: tt.namespaceSelector},
+			}
+			assert.Equal(t, tt.expected, querier.MatchesNamespaceWithLabels(tt.namespace, tt.namespaceLabels))
+		})
+	}
+}
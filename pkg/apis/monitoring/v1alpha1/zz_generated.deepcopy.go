@@ -68,6 +68,128 @@ func (in *Condition) DeepCopy() *Condition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GRPCClientTLSConfig) DeepCopyInto(out *GRPCClientTLSConfig) {
+	*out = *in
+	if in.CertificateAuthority != nil {
+		in, out := &in.CertificateAuthority, &out.CertificateAuthority
+		*out = new(SecretKeySelector)
+		**out = **in
+	}
+	if in.PrivateKey != nil {
+		in, out := &in.PrivateKey, &out.PrivateKey
+		*out = new(SecretKeySelector)
+		**out = **in
+	}
+	if in.Certificate != nil {
+		in, out := &in.Certificate, &out.Certificate
+		*out = new(SecretKeySelector)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GRPCClientTLSConfig.
+func (in *GRPCClientTLSConfig) DeepCopy() *GRPCClientTLSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(GRPCClientTLSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GRPCServerTLSConfig) DeepCopyInto(out *GRPCServerTLSConfig) {
+	*out = *in
+	if in.PrivateKey != nil {
+		in, out := &in.PrivateKey, &out.PrivateKey
+		*out = new(SecretKeySelector)
+		**out = **in
+	}
+	if in.Certificate != nil {
+		in, out := &in.Certificate, &out.Certificate
+		*out = new(SecretKeySelector)
+		**out = **in
+	}
+	if in.CertificateAuthority != nil {
+		in, out := &in.CertificateAuthority, &out.CertificateAuthority
+		*out = new(SecretKeySelector)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GRPCServerTLSConfig.
+func (in *GRPCServerTLSConfig) DeepCopy() *GRPCServerTLSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(GRPCServerTLSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayReference) DeepCopyInto(out *GatewayReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayReference.
+func (in *GatewayReference) DeepCopy() *GatewayReference {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPRouteConfig) DeepCopyInto(out *HTTPRouteConfig) {
+	*out = *in
+	if in.ParentRefs != nil {
+		in, out := &in.ParentRefs, &out.ParentRefs
+		*out = make([]GatewayReference, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPRouteConfig.
+func (in *HTTPRouteConfig) DeepCopy() *HTTPRouteConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPRouteConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressConfig) DeepCopyInto(out *IngressConfig) {
+	*out = *in
+	if in.IngressClassName != nil {
+		in, out := &in.IngressClassName, &out.IngressClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressConfig.
+func (in *IngressConfig) DeepCopy() *IngressConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MonitoringStack) DeepCopyInto(out *MonitoringStack) {
 	*out = *in
@@ -203,6 +325,11 @@ func (in *NamespaceSelector) DeepCopyInto(out *NamespaceSelector) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceSelector.
@@ -257,6 +384,11 @@ func (in *PrometheusConfig) DeepCopyInto(out *PrometheusConfig) {
 		*out = new(WebTLSConfig)
 		**out = **in
 	}
+	if in.ThanosSidecarTLSConfig != nil {
+		in, out := &in.ThanosSidecarTLSConfig, &out.ThanosSidecarTLSConfig
+		*out = new(GRPCServerTLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusConfig.
@@ -290,7 +422,7 @@ func (in *ThanosQuerier) DeepCopyInto(out *ThanosQuerier) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosQuerier.
@@ -343,6 +475,26 @@ func (in *ThanosQuerierList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ThanosQuerierPersesDatasource) DeepCopyInto(out *ThanosQuerierPersesDatasource) {
+	*out = *in
+	if in.InstanceSelector != nil {
+		in, out := &in.InstanceSelector, &out.InstanceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosQuerierPersesDatasource.
+func (in *ThanosQuerierPersesDatasource) DeepCopy() *ThanosQuerierPersesDatasource {
+	if in == nil {
+		return nil
+	}
+	out := new(ThanosQuerierPersesDatasource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ThanosQuerierSpec) DeepCopyInto(out *ThanosQuerierSpec) {
 	*out = *in
@@ -358,6 +510,26 @@ func (in *ThanosQuerierSpec) DeepCopyInto(out *ThanosQuerierSpec) {
 		*out = new(WebTLSConfig)
 		**out = **in
 	}
+	if in.GRPCClientTLSConfig != nil {
+		in, out := &in.GRPCClientTLSConfig, &out.GRPCClientTLSConfig
+		*out = new(GRPCClientTLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.QueryConfig != nil {
+		in, out := &in.QueryConfig, &out.QueryConfig
+		*out = new(ThanosQueryConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UI != nil {
+		in, out := &in.UI, &out.UI
+		*out = new(ThanosQuerierUIConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PersesDatasource != nil {
+		in, out := &in.PersesDatasource, &out.PersesDatasource
+		*out = new(ThanosQuerierPersesDatasource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosQuerierSpec.
@@ -373,6 +545,13 @@ func (in *ThanosQuerierSpec) DeepCopy() *ThanosQuerierSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ThanosQuerierStatus) DeepCopyInto(out *ThanosQuerierStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosQuerierStatus.
@@ -385,6 +564,66 @@ func (in *ThanosQuerierStatus) DeepCopy() *ThanosQuerierStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ThanosQuerierUIConfig) DeepCopyInto(out *ThanosQuerierUIConfig) {
+	*out = *in
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = new(IngressConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HTTPRoute != nil {
+		in, out := &in.HTTPRoute, &out.HTTPRoute
+		*out = new(HTTPRouteConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosQuerierUIConfig.
+func (in *ThanosQuerierUIConfig) DeepCopy() *ThanosQuerierUIConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ThanosQuerierUIConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ThanosQueryConfig) DeepCopyInto(out *ThanosQueryConfig) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(monitoringv1.Duration)
+		**out = **in
+	}
+	if in.MaxConcurrent != nil {
+		in, out := &in.MaxConcurrent, &out.MaxConcurrent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PartialResponse != nil {
+		in, out := &in.PartialResponse, &out.PartialResponse
+		*out = new(bool)
+		**out = **in
+	}
+	if in.LookbackDelta != nil {
+		in, out := &in.LookbackDelta, &out.LookbackDelta
+		*out = new(monitoringv1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosQueryConfig.
+func (in *ThanosQueryConfig) DeepCopy() *ThanosQueryConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ThanosQueryConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WebTLSConfig) DeepCopyInto(out *WebTLSConfig) {
 	*out = *in
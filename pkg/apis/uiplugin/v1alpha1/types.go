@@ -6,7 +6,9 @@ package v1alpha1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -20,6 +22,8 @@ import (
 // +kubebuilder:validation:XValidation:rule="self.spec.type != 'DistributedTracing' || self.metadata.name == 'distributed-tracing'",message="UIPlugin name must be 'distributed-tracing' if type is DistributedTracing"
 // +kubebuilder:validation:XValidation:rule="self.spec.type != 'Dashboards' || self.metadata.name == 'dashboards'",message="UIPlugin name must be 'dashboards' if type is Dashboards"
 // +kubebuilder:validation:XValidation:rule="self.spec.type != 'Monitoring' || self.metadata.name == 'monitoring'",message="UIPlugin name must be 'monitoring' if type is Monitoring"
+// +kubebuilder:validation:XValidation:rule="self.spec.type != 'Custom' || size(self.metadata.name) <= 56",message="UIPlugin name must be at most 56 characters if type is Custom"
+// +kubebuilder:validation:XValidation:rule="self.spec.type != 'Custom' || has(self.spec.custom)",message="custom configuration is required if type is Custom"
 type UIPlugin struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -37,7 +41,7 @@ type UIPluginList struct {
 	Items           []UIPlugin `json:"items"`
 }
 
-// +kubebuilder:validation:Enum=Dashboards;TroubleshootingPanel;DistributedTracing;Logging;Monitoring
+// +kubebuilder:validation:Enum=Dashboards;TroubleshootingPanel;DistributedTracing;Logging;Monitoring;Custom
 type UIPluginType string
 
 const (
@@ -52,6 +56,9 @@ const (
 
 	// TypeLogging deploys the Logging View Plugin for OpenShift Console.
 	TypeLogging UIPluginType = "Logging"
+
+	// TypeCustom deploys a console plugin provided by the user.
+	TypeCustom UIPluginType = "Custom"
 )
 
 // DeploymentConfig contains options allowing the customization of the deployment hosting the UI Plugin.
@@ -69,6 +76,74 @@ type DeploymentConfig struct {
 	// +kubebuilder:validation:Optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Pod Tolerations",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:selector:core:v1:Toleration"}
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Replicas is the number of pods of the plugin.
+	//
+	// It also applies to the korrel8r and authorization proxy deployments
+	// of the plugin unless they define their own replicas. The cluster
+	// health analyzer always runs a single replica. A PodDisruptionBudget is
+	// created for the deployments running more than one replica.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Replicas",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:podCount"}
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Resources defines the resource requests and limits of the plugin
+	// containers.
+	//
+	// It also applies to the korrel8r, cluster health analyzer, Perses and
+	// authorization proxy containers unless they define their own resources.
+	//
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Resource Requirements",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:resourceRequirements"}
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// TopologySpreadConstraints defines how the pods are spread across the
+	// topology domains.
+	//
+	// It also applies to the korrel8r, cluster health analyzer and
+	// authorization proxy pods unless they define their own constraints.
+	//
+	// +optional
+	// +listType=atomic
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// PriorityClassName is the name of the priority class of the plugin,
+	// korrel8r, cluster health analyzer and authorization proxy pods.
+	//
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Priority Class Name"
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// PodDisruptionBudget configures the PodDisruptionBudgets of the
+	// deployments running more than one replica.
+	//
+	// When not defined, at least one pod is kept available.
+	//
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Pod Disruption Budget"
+	PodDisruptionBudget *PodDisruptionBudgetConfig `json:"podDisruptionBudget,omitempty"`
+}
+
+// PodDisruptionBudgetConfig defines the disruptions allowed for the pods of
+// a deployment.
+//
+// +kubebuilder:validation:XValidation:rule="!(has(self.minAvailable) && has(self.maxUnavailable))", message="minAvailable and maxUnavailable are mutually exclusive"
+type PodDisruptionBudgetConfig struct {
+	// MinAvailable is the number or percentage of pods which must remain
+	// available.
+	//
+	// +optional
+	// +kubebuilder:validation:XIntOrString
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// MaxUnavailable is the number or percentage of pods which can be
+	// unavailable.
+	//
+	// +optional
+	// +kubebuilder:validation:XIntOrString
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
 }
 
 // TroubleshootingPanelConfig contains options for configuring the Troubleshooting Panel  plugin
@@ -82,9 +157,207 @@ type TroubleshootingPanelConfig struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="OCP Console Query Timeout",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:ocpConsoleTimeout"}
 	// +kubebuilder:validation:Pattern:="^([0-9]+)([sm]{1})$"
 	Timeout string `json:"timeout,omitempty"`
+
+	// Stores overrides the backends queried by korrel8r for the given domains.
+	//
+	// Domains which aren't listed use the default OpenShift platform stores.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=domain
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Korrel8r Stores"
+	Stores []Korrel8rStore `json:"stores,omitempty"`
+
+	// Rules references ConfigMaps containing additional korrel8r rule files.
+	//
+	// The ConfigMaps must exist in the namespace where the operator deploys the
+	// korrel8r service. Every key of a ConfigMap is included as a rule file
+	// alongside the default rules.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Korrel8r Rules"
+	Rules []Korrel8rRulesReference `json:"rules,omitempty"`
+
+	// Korrel8r configures the korrel8r deployment backing the troubleshooting panel.
+	//
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Korrel8r"
+	Korrel8r *Korrel8rConfig `json:"korrel8r,omitempty"`
+}
+
+// Korrel8rConfig contains options for configuring the korrel8r deployment.
+type Korrel8rConfig struct {
+	// Replicas is the number of korrel8r pods.
+	//
+	// When not defined, the replicas of the plugin deployment are used.
+	// A PodDisruptionBudget is created when more than one replica is requested.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Replicas",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:podCount"}
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Resources defines the resource requests and limits of the korrel8r container.
+	//
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Resource Requirements",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:resourceRequirements"}
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// TopologySpreadConstraints defines how the korrel8r pods are spread across the topology domains.
+	//
+	// +optional
+	// +listType=atomic
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// Verbosity is the log verbosity of korrel8r. Higher values log more details.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=9
+	Verbosity *int32 `json:"verbosity,omitempty"`
+
+	// Timeout is the maximum duration of the requests sent by korrel8r to the stores.
+	//
+	// The value is expected to be a sequence of digits followed by a unit suffix, which can be 's' (seconds)
+	// or 'm' (minutes).
+	//
+	// +optional
+	// +kubebuilder:validation:Pattern:="^([0-9]+)([sm]{1})$"
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// Korrel8rRulesReference references a ConfigMap containing korrel8r rule files.
+//
+// +structType=atomic
+type Korrel8rRulesReference struct {
+	// Name of the ConfigMap.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// Korrel8rDomain is a korrel8r domain whose store can be configured.
+//
+// +kubebuilder:validation:Enum=metric;alert;log;netflow;trace
+type Korrel8rDomain string
+
+const (
+	Korrel8rMetricDomain  Korrel8rDomain = "metric"
+	Korrel8rAlertDomain   Korrel8rDomain = "alert"
+	Korrel8rLogDomain     Korrel8rDomain = "log"
+	Korrel8rNetflowDomain Korrel8rDomain = "netflow"
+	Korrel8rTraceDomain   Korrel8rDomain = "trace"
+)
+
+// Korrel8rStore defines the backend queried by korrel8r for a domain.
+//
+// +kubebuilder:validation:XValidation:rule="[has(self.monitoringStack), has(self.thanosQuerier), has(self.lokiStack), has(self.tempoStack)].filter(x, x).size() == 1",message="exactly one of monitoringStack, thanosQuerier, lokiStack or tempoStack must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.monitoringStack) || self.domain in ['metric', 'alert']",message="monitoringStack is only supported for the metric and alert domains"
+// +kubebuilder:validation:XValidation:rule="!has(self.thanosQuerier) || self.domain == 'metric'",message="thanosQuerier is only supported for the metric domain"
+// +kubebuilder:validation:XValidation:rule="!has(self.lokiStack) || self.domain in ['log', 'netflow']",message="lokiStack is only supported for the log and netflow domains"
+// +kubebuilder:validation:XValidation:rule="!has(self.tempoStack) || self.domain == 'trace'",message="tempoStack is only supported for the trace domain"
+type Korrel8rStore struct {
+	// Domain is the korrel8r domain served by the store.
+	//
+	// +kubebuilder:validation:Required
+	Domain Korrel8rDomain `json:"domain"`
+
+	// MonitoringStack references a MonitoringStack (from the monitoring.rhobs API group)
+	// serving the metric or alert domain.
+	//
+	// +kubebuilder:validation:Optional
+	MonitoringStack *NamespacedReference `json:"monitoringStack,omitempty"`
+
+	// ThanosQuerier references a ThanosQuerier (from the monitoring.rhobs API group)
+	// serving the metric domain.
+	//
+	// +kubebuilder:validation:Optional
+	ThanosQuerier *NamespacedReference `json:"thanosQuerier,omitempty"`
+
+	// LokiStack references a LokiStack serving the log or netflow domain.
+	//
+	// +kubebuilder:validation:Optional
+	LokiStack *NamespacedReference `json:"lokiStack,omitempty"`
+
+	// TempoStack references a TempoStack serving the trace domain.
+	//
+	// +kubebuilder:validation:Optional
+	TempoStack *TempoStackReference `json:"tempoStack,omitempty"`
+
+	// CertificateAuthority selects the certificate authority used to verify the
+	// certificate of the store.
+	//
+	// Defaults to the OpenShift service CA.
+	//
+	// +kubebuilder:validation:Optional
+	CertificateAuthority *CertificateAuthorityReference `json:"certificateAuthority,omitempty"`
+}
+
+// NamespacedReference references a namespaced resource.
+//
+// +structType=atomic
+type NamespacedReference struct {
+	// Name of the resource.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace of the resource.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+}
+
+// TempoStackReference references a TempoStack and the tenant to query.
+//
+// +structType=atomic
+type TempoStackReference struct {
+	// Name of the TempoStack resource.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace of the TempoStack resource.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+
+	// Tenant is the TempoStack tenant to query.
+	//
+	// Defaults to "platform".
+	//
+	// +kubebuilder:validation:Optional
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// CertificateAuthorityReference selects a certificate authority bundle stored
+// in a ConfigMap or a Secret of the namespace where the operator deploys the
+// UI plugins.
+//
+// +kubebuilder:validation:XValidation:rule="!(has(self.configMap) && has(self.secret))",message="only one of configMap or secret can be set"
+// +structType=atomic
+type CertificateAuthorityReference struct {
+	// ConfigMap containing the certificate authority bundle.
+	//
+	// +kubebuilder:validation:Optional
+	ConfigMap *corev1.ConfigMapKeySelector `json:"configMap,omitempty"`
+
+	// Secret containing the certificate authority bundle.
+	//
+	// +kubebuilder:validation:Optional
+	Secret *corev1.SecretKeySelector `json:"secret,omitempty"`
 }
 
 // DistributedTracingConfig contains options for configuring the Distributed Tracing plugin
+//
+// +kubebuilder:validation:XValidation:rule="!has(self.namespaces) || !has(self.instances) || self.instances.all(i, i.__namespace__ in self.namespaces)",message="instances must be in the allowed namespaces"
 type DistributedTracingConfig struct {
 	// Timeout is the maximum duration before a query timeout.
 	//
@@ -95,9 +368,79 @@ type DistributedTracingConfig struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="OCP Console Query Timeout",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:ocpConsoleTimeout"}
 	// +kubebuilder:validation:Pattern:="^([0-9]+)([sm]{1})$"
 	Timeout string `json:"timeout,omitempty"`
+
+	// Instances lists the Tempo instances which are available in the
+	// console. The first instance is selected by default.
+	//
+	// When empty, all the instances which the user can access are listed.
+	//
+	// +optional
+	// +listType=atomic
+	// +kubebuilder:validation:MaxItems=50
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Tempo Instances"
+	Instances []TempoInstanceReference `json:"instances,omitempty"`
+
+	// Namespaces restricts the Tempo instances listed in the console to
+	// the given namespaces. The instances must be in these namespaces.
+	//
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:MaxItems=100
+	// +kubebuilder:validation:items:MaxLength=63
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Namespaces"
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// QueryLimit is the default maximum number of traces returned by a
+	// query.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Query Limit"
+	QueryLimit int32 `json:"queryLimit,omitempty"`
+
+	// DefaultTimeRange is the time range of the queries selected by
+	// default, e.g. "30m", "1h" or "7d".
+	//
+	// +optional
+	// +kubebuilder:validation:Pattern:="^([0-9]+)([mhd]{1})$"
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Default Time Range"
+	DefaultTimeRange string `json:"defaultTimeRange,omitempty"`
+}
+
+// TempoInstanceReference references a TempoStack or TempoMonolithic
+// instance.
+type TempoInstanceReference struct {
+	// Kind of the Tempo instance.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=TempoStack;TempoMonolithic
+	Kind string `json:"kind"`
+
+	// Name of the Tempo instance.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace of the Tempo instance.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	Namespace string `json:"namespace"`
+
+	// Tenants restricts the tenants of a multi-tenant instance which are
+	// available in the console. The first tenant is selected by default.
+	//
+	// +optional
+	// +listType=set
+	Tenants []string `json:"tenants,omitempty"`
 }
 
 // LoggingConfig contains options for configuring the logging console plugin.
+//
+// +kubebuilder:validation:XValidation:rule="!(has(self.lokiStack) && has(self.lokiStacks) && size(self.lokiStacks) > 0)",message="lokiStack and lokiStacks are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!has(self.loki) || !(has(self.lokiStack) || (has(self.lokiStacks) && size(self.lokiStacks) > 0))",message="loki is mutually exclusive with lokiStack and lokiStacks"
 type LoggingConfig struct {
 	// LokiStack points to the LokiStack instance of which logs should be displayed.
 	// It always references a LokiStack in the "openshift-logging" namespace.
@@ -105,6 +448,33 @@ type LoggingConfig struct {
 	// +kubebuilder:validation:Optional
 	LokiStack *LokiStackReference `json:"lokiStack"`
 
+	// LokiStacks lists the LokiStack instances which users can select as
+	// the source of logs in the console.
+	//
+	// The first LokiStack is selected by default. It is mutually exclusive
+	// with lokiStack and requires a logging plugin image which supports
+	// multiple LokiStacks.
+	//
+	// +optional
+	// +listType=atomic
+	// +kubebuilder:validation:MaxItems=20
+	// +kubebuilder:validation:XValidation:rule="self.all(x, self.exists_one(y, y.name == x.name && (has(y.__namespace__) ? y.__namespace__ : 'openshift-logging') == (has(x.__namespace__) ? x.__namespace__ : 'openshift-logging')))",message="lokiStacks must reference distinct LokiStacks"
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="LokiStacks"
+	LokiStacks []LoggingLokiStack `json:"lokiStacks,omitempty"`
+
+	// Loki configures a Loki instance reachable by URL as the source of
+	// logs, instead of a LokiStack.
+	//
+	// The operator deploys a proxy in its namespace which forwards the
+	// queries of the console to the Loki URL with the configured
+	// credentials. It is mutually exclusive with lokiStack and lokiStacks.
+	// Only the users allowed to read the application logs, e.g. with the
+	// cluster-logging-application-view cluster role, can query Loki.
+	//
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Loki"
+	Loki *LokiReference `json:"loki,omitempty"`
+
 	// LogsLimit is the max number of entries returned for a query.
 	//
 	// +kubebuilder:validation:Minimum=0
@@ -154,7 +524,144 @@ type LokiStackReference struct {
 	Namespace string `json:"namespace,omitempty"`
 }
 
+// LoggingLokiStack references a LokiStack which can be selected as the
+// source of logs in the logging console plugin.
+type LoggingLokiStack struct {
+	// Name of the LokiStack resource.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	Name string `json:"name"`
+
+	// Namespace of the LokiStack resource.
+	//
+	// Defaults to "openshift-logging".
+	//
+	// +optional
+	// +kubebuilder:validation:MaxLength=63
+	Namespace string `json:"namespace,omitempty"`
+
+	// DisplayName is the name of the LokiStack displayed in the console.
+	//
+	// Defaults to <namespace>/<name>.
+	//
+	// +optional
+	DisplayName string `json:"displayName,omitempty"`
+
+	// Tenants lists the tenants of the LokiStack which are offered in the
+	// console. It doesn't restrict the access to the logs, which depends on
+	// the permissions of the user.
+	//
+	// Defaults to all the tenants.
+	//
+	// +optional
+	// +listType=set
+	Tenants []LoggingTenant `json:"tenants,omitempty"`
+}
+
+// LokiReference configures the access to a Loki instance reachable by URL.
+//
+// The secrets must exist in the namespace where the operator is deployed.
+//
+// +kubebuilder:validation:XValidation:rule="[has(self.bearerToken), has(self.basicAuth)].filter(x, x).size() <= 1",message="bearerToken and basicAuth are mutually exclusive"
+type LokiReference struct {
+	// URL of the Loki API, e.g. https://loki.example.com.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^https?://[^\s"'$;{}]+$`
+	URL string `json:"url"`
+
+	// TenantID is the tenant sent to Loki in the tenant header.
+	//
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_.|-]+$`
+	TenantID string `json:"tenantID,omitempty"`
+
+	// TenantHeader is the name of the HTTP header carrying the tenant.
+	//
+	// +optional
+	// +kubebuilder:default="X-Scope-OrgID"
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9-]+$`
+	TenantHeader string `json:"tenantHeader,omitempty"`
+
+	// BearerToken references the key of a secret containing the bearer
+	// token sent to Loki.
+	//
+	// +optional
+	BearerToken *corev1.SecretKeySelector `json:"bearerToken,omitempty"`
+
+	// BasicAuth configures the basic authentication credentials sent to
+	// Loki.
+	//
+	// +optional
+	BasicAuth *LokiBasicAuth `json:"basicAuth,omitempty"`
+
+	// TLS configures the TLS connection to Loki, including client
+	// certificates for mutual TLS.
+	//
+	// +optional
+	TLS *LokiTLSConfig `json:"tls,omitempty"`
+}
+
+// LokiBasicAuth references the basic authentication credentials.
+type LokiBasicAuth struct {
+	// Username references the key of a secret containing the username.
+	//
+	// +kubebuilder:validation:Required
+	Username corev1.SecretKeySelector `json:"username"`
+
+	// Password references the key of a secret containing the password.
+	//
+	// +kubebuilder:validation:Required
+	Password corev1.SecretKeySelector `json:"password"`
+}
+
+// LokiTLSConfig configures the TLS connection to Loki.
+//
+// +kubebuilder:validation:XValidation:rule="has(self.cert) == has(self.key)",message="cert and key must be set together"
+type LokiTLSConfig struct {
+	// CA references the key of a secret containing the CA certificate used
+	// to verify the certificate of Loki.
+	//
+	// Defaults to the system CA certificates.
+	//
+	// +optional
+	CA *corev1.SecretKeySelector `json:"ca,omitempty"`
+
+	// Cert references the key of a secret containing the client
+	// certificate.
+	//
+	// +optional
+	Cert *corev1.SecretKeySelector `json:"cert,omitempty"`
+
+	// Key references the key of a secret containing the private key of the
+	// client certificate.
+	//
+	// +optional
+	Key *corev1.SecretKeySelector `json:"key,omitempty"`
+
+	// InsecureSkipVerify disables the verification of the certificate of
+	// Loki.
+	//
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// LoggingTenant is a tenant of a LokiStack in openshift-logging mode.
+//
+// +kubebuilder:validation:Enum=application;infrastructure;audit
+type LoggingTenant string
+
+const (
+	ApplicationLoggingTenant    LoggingTenant = "application"
+	InfrastructureLoggingTenant LoggingTenant = "infrastructure"
+	AuditLoggingTenant          LoggingTenant = "audit"
+)
+
 // MonitoringConfig contains options for configuring the monitoring console plugin.
+//
+// +kubebuilder:validation:XValidation:rule="!(has(self.backends) && has(self.acm) && self.acm.enabled)",message="backends and acm are mutually exclusive"
 type MonitoringConfig struct {
 	// ACM points to the alertmanager and thanosQuerier instance services of which it should create a proxy to.
 	//
@@ -177,6 +684,33 @@ type MonitoringConfig struct {
 	//
 	// +kubebuilder:validation:Optional
 	ClusterHealthAnalyzer *ClusterHealthAnalyzerReference `json:"clusterHealthAnalyzer,omitempty"`
+
+	// Backends points the alerting and metrics views of the plugin to a
+	// MonitoringStack or a ThanosQuerier managed by the operator.
+	//
+	// Users must be allowed to access the "api" subresource of the
+	// referenced resources. It is mutually exclusive with acm.
+	//
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Monitoring Backends"
+	Backends *MonitoringBackendsReference `json:"backends,omitempty"`
+}
+
+// MonitoringBackendsReference references the MonitoringStack and
+// ThanosQuerier queried by the monitoring console plugin.
+//
+// +kubebuilder:validation:XValidation:rule="has(self.monitoringStack) || has(self.thanosQuerier)",message="at least one of monitoringStack or thanosQuerier must be set"
+type MonitoringBackendsReference struct {
+	// MonitoringStack provides the Alertmanager and, unless thanosQuerier
+	// is set, the Prometheus queried by the plugin.
+	//
+	// +optional
+	MonitoringStack *NamespacedReference `json:"monitoringStack,omitempty"`
+
+	// ThanosQuerier provides the metrics queried by the plugin.
+	//
+	// +optional
+	ThanosQuerier *NamespacedReference `json:"thanosQuerier,omitempty"`
 }
 
 // AdvancedClusterManagementReference is used to configure references to the alertmanager and thanosQuerier that should be used
@@ -221,27 +755,289 @@ type ThanosQuerierReference struct {
 
 // PersesReference is used to configure a reference to a perses service that should be used
 // by the monitoring console plugin.
+//
+// +kubebuilder:validation:XValidation:rule="!has(self.replicas) || self.replicas <= 1 || (has(self.storage) && has(self.storage.sql))", message="Running more than one Perses replica requires a SQL database"
 type PersesReference struct {
 	// Indicates if perses-related feature(s) should be enabled
 	//
 	// +kubebuilder:validation:Required
 	Enabled bool `json:"enabled"`
-}
 
-// IncidentsReference is used to configure if the incidents feature flag should be enabled.
-type IncidentsReference struct {
-	// Indicates if incidents-related feature(s) should be enabled.
+	// Dashboards enables or disables the dashboards of the catalog shipped
+	// by the operator.
 	//
-	// +kubebuilder:validation:Required
-	Enabled bool `json:"enabled"`
-}
-
-// ClusterHealthAnalyzerReference is used to configure the cluster health analyzer features.
-type ClusterHealthAnalyzerReference struct {
-	// Indicates if the cluster-health-analyzer features should be enabled.
+	// Dashboards which aren't listed keep their default state.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Dashboards"
+	Dashboards []PersesCatalogDashboard `json:"dashboards,omitempty"`
+
+	// APM configures the span metrics queried by the Application
+	// Performance Monitoring (APM) dashboard.
+	//
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="APM Dashboard"
+	APM *APMDashboardConfig `json:"apm,omitempty"`
+
+	// Storage defines where Perses stores the dashboards and datasources
+	// created by the users.
+	//
+	// When not defined, the data is stored on the local filesystem of the
+	// Perses pod and lost when the pod restarts.
+	//
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Storage"
+	Storage *PersesStorage `json:"storage,omitempty"`
+
+	// Replicas is the number of Perses pods.
+	//
+	// More than one replica requires a SQL database.
+	//
+	// +optional
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Replicas",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:podCount"}
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Resources defines the resource requests and limits of the Perses container.
+	//
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Resource Requirements",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:resourceRequirements"}
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// NodeSelector defines the nodes on which the Perses pods are scheduled.
+	//
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Node Selector",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:nodeSelector"}
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations defines the tolerations of the Perses pods.
+	//
+	// +optional
+	// +listType=atomic
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Pod Tolerations",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:selector:core:v1:Toleration"}
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity defines the scheduling constraints of the Perses pods.
+	//
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+}
+
+// SpanMetricsConvention is the naming convention of the span metrics.
+//
+// +kubebuilder:validation:Enum=OpenTelemetry;Tempo
+type SpanMetricsConvention string
+
+const (
+	// OpenTelemetrySpanMetrics is the convention of the span metrics
+	// connector of the OpenTelemetry collector. The metrics are scraped
+	// from the collectors.
+	OpenTelemetrySpanMetrics SpanMetricsConvention = "OpenTelemetry"
+	// TempoSpanMetrics is the convention of the Tempo metrics-generator.
+	TempoSpanMetrics SpanMetricsConvention = "Tempo"
+)
+
+// APMDashboardConfig configures the span metrics queried by the APM dashboard.
+type APMDashboardConfig struct {
+	// Convention is the naming convention of the span metrics.
+	//
+	// OpenTelemetry matches the `<namespace>_calls_total` and
+	// `<namespace>_duration_milliseconds_bucket` metrics labelled with
+	// `service_name` generated by the span metrics connector of the
+	// OpenTelemetry collector.
+	//
+	// Tempo matches the `<namespace>_calls_total` and
+	// `<namespace>_latency_bucket` metrics labelled with `service`
+	// generated by the Tempo metrics-generator.
+	//
+	// +optional
+	// +kubebuilder:default=OpenTelemetry
+	Convention SpanMetricsConvention `json:"convention,omitempty"`
+
+	// SpanMetricsNamespace is the namespace prefixing the names of the span
+	// metrics.
+	//
+	// It defaults to `traces_span_metrics` with the OpenTelemetry
+	// convention and to `traces_spanmetrics` with the Tempo convention.
+	//
+	// +optional
+	// +kubebuilder:validation:Pattern:="^[a-zA-Z_][a-zA-Z0-9_.]*$"
+	SpanMetricsNamespace string `json:"spanMetricsNamespace,omitempty"`
+
+	// Datasource is the name of the PersesDatasource in the operator
+	// namespace queried by the dashboard.
+	//
+	// When not defined, the default datasource is used.
+	//
+	// +optional
+	Datasource string `json:"datasource,omitempty"`
+}
+
+// PersesStorage defines the storage of the Perses database.
+//
+// +kubebuilder:validation:XValidation:rule="!(has(self.persistentVolumeClaim) && has(self.sql))", message="persistentVolumeClaim and sql are mutually exclusive"
+type PersesStorage struct {
+	// PersistentVolumeClaim defines the volume claim in which the Perses
+	// file database is stored.
+	//
+	// +optional
+	PersistentVolumeClaim *corev1.PersistentVolumeClaimSpec `json:"persistentVolumeClaim,omitempty"`
+
+	// SQL configures Perses to store its data in a MySQL-compatible database.
+	//
+	// +optional
+	SQL *PersesSQLStorage `json:"sql,omitempty"`
+}
+
+// PersesSQLStorage references the SQL database used by Perses.
+type PersesSQLStorage struct {
+	// Secret is the name of a secret in the operator namespace holding the
+	// `address` (host:port), `username` and `password` keys used to connect
+	// to the database.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Secret string `json:"secret"`
+
+	// Database is the name of the database.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Database string `json:"database"`
+}
+
+// PersesCatalogDashboardName is the name of a dashboard of the catalog.
+//
+// +kubebuilder:validation:Enum=accelerators;apm;kubernetes-workloads;monitoring-stack;thanos;opentelemetry-collector;tempo
+type PersesCatalogDashboardName string
+
+const (
+	AcceleratorsDashboard           PersesCatalogDashboardName = "accelerators"
+	APMDashboard                    PersesCatalogDashboardName = "apm"
+	KubernetesWorkloadsDashboard    PersesCatalogDashboardName = "kubernetes-workloads"
+	MonitoringStackDashboard        PersesCatalogDashboardName = "monitoring-stack"
+	ThanosDashboard                 PersesCatalogDashboardName = "thanos"
+	OpenTelemetryCollectorDashboard PersesCatalogDashboardName = "opentelemetry-collector"
+	TempoDashboard                  PersesCatalogDashboardName = "tempo"
+)
+
+// PersesCatalogDashboard enables or disables a dashboard of the catalog.
+type PersesCatalogDashboard struct {
+	// Name of the dashboard.
+	//
+	// +kubebuilder:validation:Required
+	Name PersesCatalogDashboardName `json:"name"`
+
+	// Indicates if the dashboard should be deployed.
+	//
+	// +kubebuilder:validation:Required
+	Enabled bool `json:"enabled"`
+}
+
+// IncidentsReference is used to configure if the incidents feature flag should be enabled.
+type IncidentsReference struct {
+	// Indicates if incidents-related feature(s) should be enabled.
+	//
+	// +kubebuilder:validation:Required
+	Enabled bool `json:"enabled"`
+}
+
+// ClusterHealthAnalyzerReference is used to configure the cluster health analyzer features.
+type ClusterHealthAnalyzerReference struct {
+	// Indicates if the cluster-health-analyzer features should be enabled.
 	//
 	// +kubebuilder:validation:Required
 	Enabled bool `json:"enabled"`
+
+	// Components references ConfigMaps in the operator namespace which
+	// define additional components evaluated by the cluster health analyzer.
+	//
+	// Each key of a ConfigMap holds a component tree using the same format
+	// as the default tree. A top-level component with the same name as an
+	// existing one replaces it, other components are added. ConfigMaps are
+	// merged in the order in which they are listed.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Components"
+	Components []HealthAnalyzerComponentsReference `json:"components,omitempty"`
+
+	// Backends points the cluster health analyzer to the Alertmanager and
+	// Prometheus of a MonitoringStack or to a ThanosQuerier managed by the
+	// operator instead of the platform monitoring stack.
+	//
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Backends"
+	Backends *MonitoringBackendsReference `json:"backends,omitempty"`
+
+	// Replicas is the number of cluster health analyzer pods.
+	//
+	// The cluster health analyzer has no leader election: running more than
+	// one replica would compute the incidents several times.
+	//
+	// +optional
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:XValidation:rule="self <= 1",message="the cluster health analyzer doesn't support more than one replica"
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Replicas",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:podCount"}
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Resources defines the resource requests and limits of the cluster health analyzer container.
+	//
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Resource Requirements",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:resourceRequirements"}
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// NodeSelector defines the nodes on which the cluster health analyzer pods are scheduled.
+	//
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Node Selector",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:nodeSelector"}
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations defines the tolerations of the cluster health analyzer pods.
+	//
+	// +optional
+	// +listType=atomic
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Pod Tolerations",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:selector:core:v1:Toleration"}
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// TopologySpreadConstraints defines how the cluster health analyzer pods are spread across the topology domains.
+	//
+	// +optional
+	// +listType=atomic
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// LogLevel is the log level of the cluster health analyzer.
+	//
+	// +optional
+	// +kubebuilder:validation:Enum=debug;info;warn;error
+	LogLevel string `json:"logLevel,omitempty"`
+
+	// Interval is the period at which the cluster health analyzer evaluates
+	// the alerts and the components.
+	//
+	// The value is expected to be a sequence of digits followed by a unit suffix, which can be 's' (seconds)
+	// or 'm' (minutes).
+	//
+	// +optional
+	// +kubebuilder:validation:Pattern:="^([0-9]+)([sm]{1})$"
+	Interval string `json:"interval,omitempty"`
+}
+
+// HealthAnalyzerComponentsReference references a ConfigMap containing
+// component definitions for the cluster health analyzer.
+//
+// +structType=atomic
+type HealthAnalyzerComponentsReference struct {
+	// Name of the ConfigMap.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
 }
 
 // UIPluginSpec is the specification for desired state of UIPlugin.
@@ -280,6 +1076,369 @@ type UIPluginSpec struct {
 	//
 	// +kubebuilder:validation:Optional
 	Monitoring *MonitoringConfig `json:"monitoring,omitempty"`
+
+	// Custom contains configuration for a console plugin provided by the user.
+	//
+	// It only applies to UIPlugin Type: Custom.
+	//
+	// +kubebuilder:validation:Optional
+	Custom *CustomPluginConfig `json:"custom,omitempty"`
+
+	// ProxyAuthorization defines the permissions required to send requests
+	// through the backend proxies of the plugin.
+	//
+	// An entry replaces the permission which the operator checks for the
+	// same proxy, e.g. for the monitoring backends.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=alias
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Proxy Authorization"
+	ProxyAuthorization []ProxyAuthorization `json:"proxyAuthorization,omitempty"`
+
+	// Exposure exposes the backend services of the plugin (korrel8r, Perses
+	// and the cluster health analyzer) outside of the cluster.
+	//
+	// It only applies when the plugin runs in standalone mode, on clusters
+	// without the OpenShift console.
+	//
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Exposure"
+	Exposure *ExposureConfig `json:"exposure,omitempty"`
+}
+
+// ExposureType is the kind of resource exposing the backend services.
+//
+// +kubebuilder:validation:Enum=Ingress;HTTPRoute
+type ExposureType string
+
+const (
+	// IngressExposure exposes the backend services with Ingresses.
+	IngressExposure ExposureType = "Ingress"
+	// HTTPRouteExposure exposes the backend services with Gateway API
+	// HTTPRoutes. BackendTLSPolicies configure the Gateways to connect over
+	// HTTPS.
+	HTTPRouteExposure ExposureType = "HTTPRoute"
+)
+
+// ExposureConfig defines how the backend services of a plugin are exposed
+// outside of the cluster.
+//
+// +kubebuilder:validation:XValidation:rule="self.type == 'Ingress' || !has(self.ingress)",message="ingress can only be set when type is Ingress"
+// +kubebuilder:validation:XValidation:rule="self.type == 'HTTPRoute' || !has(self.httpRoute)",message="httpRoute can only be set when type is HTTPRoute"
+type ExposureConfig struct {
+	// Type is the kind of resource exposing the backend services.
+	//
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Type"
+	Type ExposureType `json:"type"`
+
+	// Domain is the DNS domain of the exposed services. Every backend
+	// service is exposed at the `<service>.<domain>` host (e.g.
+	// `korrel8r.example.com`).
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Domain"
+	Domain string `json:"domain"`
+
+	// Ingress configures the Ingresses when type is Ingress.
+	//
+	// +optional
+	Ingress *ExposureIngressConfig `json:"ingress,omitempty"`
+
+	// HTTPRoute configures the HTTPRoutes when type is HTTPRoute.
+	//
+	// +optional
+	HTTPRoute *ExposureHTTPRouteConfig `json:"httpRoute,omitempty"`
+
+	// Authorization defines the permission which the clients must have to
+	// access the exposed services.
+	//
+	// The backend services trust the requests forwarded by the console
+	// and can't be exposed without authentication: the Ingresses and
+	// HTTPRoutes target an authorization proxy instead.
+	//
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Authorization"
+	Authorization ExposureAuthorization `json:"authorization"`
+}
+
+// ExposureAuthorization defines the permission required to access the
+// exposed backend services.
+//
+// The requests must carry the bearer token of a Kubernetes user or service
+// account. The authorization proxy authenticates the token with a
+// TokenReview and checks with a SubjectAccessReview that the client is
+// allowed to access the resource before forwarding the request over HTTPS.
+// The verb of the review is derived from the HTTP method of the request:
+// get for GET, create for POST, update for PUT, patch for PATCH and delete
+// for DELETE.
+type ExposureAuthorization struct {
+	// Group is the API group of the resource.
+	//
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// Resource is the resource which the client must be allowed to access.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Resource string `json:"resource"`
+
+	// Subresource is the subresource which the client must be allowed to
+	// access.
+	//
+	// +optional
+	Subresource string `json:"subresource,omitempty"`
+
+	// Name is the name of the resource which the client must be allowed to
+	// access.
+	//
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Namespace is the namespace of the resource.
+	//
+	// Without namespace, the access is checked cluster-wide.
+	//
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// ExposureIngressConfig configures the Ingresses exposing the backend
+// services.
+type ExposureIngressConfig struct {
+	// IngressClassName is the name of the IngressClass of the Ingresses.
+	//
+	// +optional
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+
+	// TLSSecretName is the name of the secret in the namespace of the
+	// operator containing the TLS certificate of the hosts.
+	//
+	// +optional
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+
+	// Annotations are added to the Ingresses. The backend services are
+	// served over HTTPS: the `nginx.ingress.kubernetes.io/backend-protocol`
+	// annotation is set to `HTTPS` unless overridden, other ingress
+	// controllers need their own annotations.
+	//
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ExposureHTTPRouteConfig configures the HTTPRoutes exposing the backend
+// services.
+type ExposureHTTPRouteConfig struct {
+	// ParentRefs are the Gateways to which the HTTPRoutes are attached.
+	//
+	// +kubebuilder:validation:MinItems=1
+	// +listType=atomic
+	ParentRefs []ExposureParentReference `json:"parentRefs"`
+}
+
+// ExposureParentReference references a Gateway.
+type ExposureParentReference struct {
+	// Name is the name of the Gateway.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the Gateway.
+	//
+	// Defaults to the namespace of the operator.
+	//
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// SectionName is the name of the Gateway listener.
+	//
+	// +optional
+	SectionName string `json:"sectionName,omitempty"`
+}
+
+// ProxyAuthorization defines the permission required to use a backend proxy
+// of the plugin.
+//
+// The requests sent through the proxy are forwarded by an authorization proxy
+// which checks with a SubjectAccessReview that the user is allowed to access
+// the resource. The verb of the review is derived from the HTTP method of the
+// request: get for GET, create for POST, update for PUT, patch for PATCH and
+// delete for DELETE.
+//
+// +kubebuilder:validation:XValidation:rule="!(has(self.namespace) && has(self.namespaceQueryParameter))", message="namespace and namespaceQueryParameter are mutually exclusive"
+type ProxyAuthorization struct {
+	// Alias is the alias of the proxy (e.g. `perses`, `backend` or the
+	// alias of a custom proxy).
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=128
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9-_]+$`
+	Alias string `json:"alias"`
+
+	// Group is the API group of the resource.
+	//
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// Resource is the resource which the user must be allowed to access.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Resource string `json:"resource"`
+
+	// Subresource is the subresource which the user must be allowed to
+	// access.
+	//
+	// +optional
+	Subresource string `json:"subresource,omitempty"`
+
+	// Name is the name of the resource which the user must be allowed to
+	// access.
+	//
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Namespace is the namespace of the resource.
+	//
+	// When neither namespace nor namespaceQueryParameter are defined, the
+	// access is checked cluster-wide.
+	//
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// NamespaceQueryParameter is the query parameter of the requests which
+	// contains the namespace of the resource. It enforces the permission per
+	// namespace.
+	//
+	// +optional
+	NamespaceQueryParameter string `json:"namespaceQueryParameter,omitempty"`
+}
+
+// CustomPluginConfig contains options for deploying a console plugin provided
+// by the user.
+//
+// The operator deploys the plugin image with a Deployment and a Service
+// named custom-<UIPlugin name> in its namespace, provisions a serving
+// certificate mounted at /var/serving-cert and registers the plugin with the
+// console.
+type CustomPluginConfig struct {
+	// Image is the container image of the plugin.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Image"
+	Image string `json:"image"`
+
+	// DisplayName is the name of the plugin displayed in the console.
+	//
+	// Defaults to the name of the UIPlugin.
+	//
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Display Name"
+	DisplayName string `json:"displayName,omitempty"`
+
+	// Port is the HTTPS port on which the plugin serves its assets.
+	//
+	// +optional
+	// +kubebuilder:default=9443
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Port"
+	Port int32 `json:"port,omitempty"`
+
+	// Args are the arguments of the plugin container.
+	//
+	// +optional
+	// +listType=atomic
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Arguments"
+	Args []string `json:"args,omitempty"`
+
+	// Proxies are the backend services which the console proxies for the
+	// plugin.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=alias
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Proxies"
+	Proxies []CustomPluginProxy `json:"proxies,omitempty"`
+
+	// Rules are the permissions granted to the service account of the
+	// plugin in the namespace of the operator, where the plugin is deployed.
+	// Rules granting access to secrets, using wildcards or the bind,
+	// escalate and impersonate verbs are rejected.
+	//
+	// +optional
+	// +listType=atomic
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="RBAC Rules"
+	Rules []rbacv1.PolicyRule `json:"rules,omitempty"`
+
+	// Config is the configuration data of the plugin.
+	//
+	// Every key is mounted as a file in the /etc/plugin/config directory of
+	// the plugin container.
+	//
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Configuration"
+	Config map[string]string `json:"config,omitempty"`
+}
+
+// CustomPluginProxy defines a backend service proxied by the console for a
+// custom plugin.
+type CustomPluginProxy struct {
+	// Alias is the name of the proxy, used in the proxy endpoint path:
+	// /api/proxy/plugin/<plugin>/<alias>.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=128
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9-_]+$`
+	Alias string `json:"alias"`
+
+	// Service is the in-cluster service to proxy to.
+	//
+	// +kubebuilder:validation:Required
+	Service CustomPluginProxyService `json:"service"`
+
+	// Authorize indicates whether the console forwards the bearer token of
+	// the logged-in user to the service.
+	//
+	// +optional
+	Authorize bool `json:"authorize,omitempty"`
+
+	// CACertificate is the PEM-encoded CA bundle used to verify the
+	// certificate of the service.
+	//
+	// Defaults to the service CA of the cluster.
+	//
+	// +optional
+	CACertificate string `json:"caCertificate,omitempty"`
+}
+
+// CustomPluginProxyService references the service of a proxy.
+type CustomPluginProxyService struct {
+	// Name of the service.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace of the service.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+
+	// Port of the service.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
 }
 
 // UIPluginStatus defines the observed state of UIPlugin.
@@ -288,8 +1447,98 @@ type UIPluginStatus struct {
 	// Conditions provide status information about the plugin.
 	// +listType=atomic
 	Conditions []metav1.Condition `json:"conditions"`
+
+	// CompatibilitySource indicates whether the compatibility matrix entry
+	// used for the plugin is built into the operator or comes from the
+	// compatibility matrix ConfigMap.
+	//
+	// +optional
+	CompatibilitySource CompatibilitySource `json:"compatibilitySource,omitempty"`
+
+	// Image is the container image of the plugin resolved from the
+	// compatibility matrix for the cluster version.
+	//
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Features lists the plugin features enabled for the cluster version
+	// and the plugin configuration. It is empty for the plugin types
+	// without features (Dashboards and Custom).
+	//
+	// +optional
+	// +listType=atomic
+	Features []string `json:"features,omitempty"`
+
+	// SupportLevel is the support level of the plugin for the cluster
+	// version.
+	//
+	// +optional
+	// +kubebuilder:validation:Enum=DevPreview;TechPreview;GeneralAvailability;Experimental-SSA
+	SupportLevel string `json:"supportLevel,omitempty"`
+
+	// ConsoleRegistration indicates whether the plugin is enabled in the
+	// `Console` resource of the console operator.
+	//
+	// +optional
+	ConsoleRegistration ConsoleRegistrationState `json:"consoleRegistration,omitempty"`
+
+	// Components reports which components of the plugin are active.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Components []UIPluginComponentStatus `json:"components,omitempty"`
+}
+
+// ConsoleRegistrationState is the registration state of the plugin in the
+// OpenShift console.
+//
+// +kubebuilder:validation:Enum=Registered;NotRegistered;ConsoleUnavailable
+type ConsoleRegistrationState string
+
+const (
+	// ConsoleRegistered means that the plugin is enabled in the console.
+	ConsoleRegistered ConsoleRegistrationState = "Registered"
+	// ConsoleNotRegistered means that the plugin isn't enabled in the console.
+	ConsoleNotRegistered ConsoleRegistrationState = "NotRegistered"
+	// ConsoleUnavailable means that the cluster has no console: the plugin
+	// runs in standalone mode and only its backend services are deployed.
+	ConsoleUnavailable ConsoleRegistrationState = "ConsoleUnavailable"
+)
+
+// UIPluginComponentStatus is the status of a component of the plugin.
+type UIPluginComponentStatus struct {
+	// Name of the component (e.g. `console-plugin`, `korrel8r`, `perses`
+	// or `health-analyzer`).
+	Name string `json:"name"`
+
+	// Active indicates whether the component is deployed.
+	Active bool `json:"active"`
+
+	// Host is the host name exposing the component outside of the cluster.
+	//
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// Message explains why the component isn't active or exposed.
+	//
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
+// CompatibilitySource is the origin of a compatibility matrix entry.
+//
+// +kubebuilder:validation:Enum=BuiltIn;ConfigMap
+type CompatibilitySource string
+
+const (
+	// BuiltInCompatibilitySource is used for entries compiled into the operator.
+	BuiltInCompatibilitySource CompatibilitySource = "BuiltIn"
+	// ConfigMapCompatibilitySource is used for entries loaded from the
+	// compatibility matrix ConfigMap.
+	ConfigMapCompatibilitySource CompatibilitySource = "ConfigMap"
+)
+
 type ConditionStatus string
 
 // +required
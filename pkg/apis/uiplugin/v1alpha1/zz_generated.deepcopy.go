@@ -22,10 +22,27 @@ package v1alpha1
 
 import (
 	"k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APMDashboardConfig) DeepCopyInto(out *APMDashboardConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APMDashboardConfig.
+func (in *APMDashboardConfig) DeepCopy() *APMDashboardConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(APMDashboardConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AdvancedClusterManagementReference) DeepCopyInto(out *AdvancedClusterManagementReference) {
 	*out = *in
@@ -58,9 +75,75 @@ func (in *AlertmanagerReference) DeepCopy() *AlertmanagerReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateAuthorityReference) DeepCopyInto(out *CertificateAuthorityReference) {
+	*out = *in
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateAuthorityReference.
+func (in *CertificateAuthorityReference) DeepCopy() *CertificateAuthorityReference {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateAuthorityReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterHealthAnalyzerReference) DeepCopyInto(out *ClusterHealthAnalyzerReference) {
 	*out = *in
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]HealthAnalyzerComponentsReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Backends != nil {
+		in, out := &in.Backends, &out.Backends
+		*out = new(MonitoringBackendsReference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterHealthAnalyzerReference.
@@ -73,6 +156,76 @@ func (in *ClusterHealthAnalyzerReference) DeepCopy() *ClusterHealthAnalyzerRefer
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomPluginConfig) DeepCopyInto(out *CustomPluginConfig) {
+	*out = *in
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Proxies != nil {
+		in, out := &in.Proxies, &out.Proxies
+		*out = make([]CustomPluginProxy, len(*in))
+		copy(*out, *in)
+	}
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]rbacv1.PolicyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomPluginConfig.
+func (in *CustomPluginConfig) DeepCopy() *CustomPluginConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomPluginConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomPluginProxy) DeepCopyInto(out *CustomPluginProxy) {
+	*out = *in
+	out.Service = in.Service
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomPluginProxy.
+func (in *CustomPluginProxy) DeepCopy() *CustomPluginProxy {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomPluginProxy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomPluginProxyService) DeepCopyInto(out *CustomPluginProxyService) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomPluginProxyService.
+func (in *CustomPluginProxyService) DeepCopy() *CustomPluginProxyService {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomPluginProxyService)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeploymentConfig) DeepCopyInto(out *DeploymentConfig) {
 	*out = *in
@@ -90,6 +243,28 @@ func (in *DeploymentConfig) DeepCopyInto(out *DeploymentConfig) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentConfig.
@@ -105,6 +280,18 @@ func (in *DeploymentConfig) DeepCopy() *DeploymentConfig {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DistributedTracingConfig) DeepCopyInto(out *DistributedTracingConfig) {
 	*out = *in
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make([]TempoInstanceReference, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DistributedTracingConfig.
@@ -117,6 +304,124 @@ func (in *DistributedTracingConfig) DeepCopy() *DistributedTracingConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExposureAuthorization) DeepCopyInto(out *ExposureAuthorization) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExposureAuthorization.
+func (in *ExposureAuthorization) DeepCopy() *ExposureAuthorization {
+	if in == nil {
+		return nil
+	}
+	out := new(ExposureAuthorization)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExposureConfig) DeepCopyInto(out *ExposureConfig) {
+	*out = *in
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = new(ExposureIngressConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HTTPRoute != nil {
+		in, out := &in.HTTPRoute, &out.HTTPRoute
+		*out = new(ExposureHTTPRouteConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	out.Authorization = in.Authorization
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExposureConfig.
+func (in *ExposureConfig) DeepCopy() *ExposureConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ExposureConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExposureHTTPRouteConfig) DeepCopyInto(out *ExposureHTTPRouteConfig) {
+	*out = *in
+	if in.ParentRefs != nil {
+		in, out := &in.ParentRefs, &out.ParentRefs
+		*out = make([]ExposureParentReference, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExposureHTTPRouteConfig.
+func (in *ExposureHTTPRouteConfig) DeepCopy() *ExposureHTTPRouteConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ExposureHTTPRouteConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExposureIngressConfig) DeepCopyInto(out *ExposureIngressConfig) {
+	*out = *in
+	if in.IngressClassName != nil {
+		in, out := &in.IngressClassName, &out.IngressClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExposureIngressConfig.
+func (in *ExposureIngressConfig) DeepCopy() *ExposureIngressConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ExposureIngressConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExposureParentReference) DeepCopyInto(out *ExposureParentReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExposureParentReference.
+func (in *ExposureParentReference) DeepCopy() *ExposureParentReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ExposureParentReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HealthAnalyzerComponentsReference) DeepCopyInto(out *HealthAnalyzerComponentsReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthAnalyzerComponentsReference.
+func (in *HealthAnalyzerComponentsReference) DeepCopy() *HealthAnalyzerComponentsReference {
+	if in == nil {
+		return nil
+	}
+	out := new(HealthAnalyzerComponentsReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IncidentsReference) DeepCopyInto(out *IncidentsReference) {
 	*out = *in
@@ -132,6 +437,98 @@ func (in *IncidentsReference) DeepCopy() *IncidentsReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Korrel8rConfig) DeepCopyInto(out *Korrel8rConfig) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Verbosity != nil {
+		in, out := &in.Verbosity, &out.Verbosity
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Korrel8rConfig.
+func (in *Korrel8rConfig) DeepCopy() *Korrel8rConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(Korrel8rConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Korrel8rRulesReference) DeepCopyInto(out *Korrel8rRulesReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Korrel8rRulesReference.
+func (in *Korrel8rRulesReference) DeepCopy() *Korrel8rRulesReference {
+	if in == nil {
+		return nil
+	}
+	out := new(Korrel8rRulesReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Korrel8rStore) DeepCopyInto(out *Korrel8rStore) {
+	*out = *in
+	if in.MonitoringStack != nil {
+		in, out := &in.MonitoringStack, &out.MonitoringStack
+		*out = new(NamespacedReference)
+		**out = **in
+	}
+	if in.ThanosQuerier != nil {
+		in, out := &in.ThanosQuerier, &out.ThanosQuerier
+		*out = new(NamespacedReference)
+		**out = **in
+	}
+	if in.LokiStack != nil {
+		in, out := &in.LokiStack, &out.LokiStack
+		*out = new(NamespacedReference)
+		**out = **in
+	}
+	if in.TempoStack != nil {
+		in, out := &in.TempoStack, &out.TempoStack
+		*out = new(TempoStackReference)
+		**out = **in
+	}
+	if in.CertificateAuthority != nil {
+		in, out := &in.CertificateAuthority, &out.CertificateAuthority
+		*out = new(CertificateAuthorityReference)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Korrel8rStore.
+func (in *Korrel8rStore) DeepCopy() *Korrel8rStore {
+	if in == nil {
+		return nil
+	}
+	out := new(Korrel8rStore)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LoggingConfig) DeepCopyInto(out *LoggingConfig) {
 	*out = *in
@@ -140,6 +537,18 @@ func (in *LoggingConfig) DeepCopyInto(out *LoggingConfig) {
 		*out = new(LokiStackReference)
 		**out = **in
 	}
+	if in.LokiStacks != nil {
+		in, out := &in.LokiStacks, &out.LokiStacks
+		*out = make([]LoggingLokiStack, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Loki != nil {
+		in, out := &in.Loki, &out.Loki
+		*out = new(LokiReference)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingConfig.
@@ -152,6 +561,73 @@ func (in *LoggingConfig) DeepCopy() *LoggingConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoggingLokiStack) DeepCopyInto(out *LoggingLokiStack) {
+	*out = *in
+	if in.Tenants != nil {
+		in, out := &in.Tenants, &out.Tenants
+		*out = make([]LoggingTenant, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingLokiStack.
+func (in *LoggingLokiStack) DeepCopy() *LoggingLokiStack {
+	if in == nil {
+		return nil
+	}
+	out := new(LoggingLokiStack)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LokiBasicAuth) DeepCopyInto(out *LokiBasicAuth) {
+	*out = *in
+	in.Username.DeepCopyInto(&out.Username)
+	in.Password.DeepCopyInto(&out.Password)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LokiBasicAuth.
+func (in *LokiBasicAuth) DeepCopy() *LokiBasicAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(LokiBasicAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LokiReference) DeepCopyInto(out *LokiReference) {
+	*out = *in
+	if in.BearerToken != nil {
+		in, out := &in.BearerToken, &out.BearerToken
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BasicAuth != nil {
+		in, out := &in.BasicAuth, &out.BasicAuth
+		*out = new(LokiBasicAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(LokiTLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LokiReference.
+func (in *LokiReference) DeepCopy() *LokiReference {
+	if in == nil {
+		return nil
+	}
+	out := new(LokiReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LokiStackReference) DeepCopyInto(out *LokiStackReference) {
 	*out = *in
@@ -167,6 +643,61 @@ func (in *LokiStackReference) DeepCopy() *LokiStackReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LokiTLSConfig) DeepCopyInto(out *LokiTLSConfig) {
+	*out = *in
+	if in.CA != nil {
+		in, out := &in.CA, &out.CA
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Cert != nil {
+		in, out := &in.Cert, &out.Cert
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Key != nil {
+		in, out := &in.Key, &out.Key
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LokiTLSConfig.
+func (in *LokiTLSConfig) DeepCopy() *LokiTLSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(LokiTLSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitoringBackendsReference) DeepCopyInto(out *MonitoringBackendsReference) {
+	*out = *in
+	if in.MonitoringStack != nil {
+		in, out := &in.MonitoringStack, &out.MonitoringStack
+		*out = new(NamespacedReference)
+		**out = **in
+	}
+	if in.ThanosQuerier != nil {
+		in, out := &in.ThanosQuerier, &out.ThanosQuerier
+		*out = new(NamespacedReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringBackendsReference.
+func (in *MonitoringBackendsReference) DeepCopy() *MonitoringBackendsReference {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitoringBackendsReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MonitoringConfig) DeepCopyInto(out *MonitoringConfig) {
 	*out = *in
@@ -178,7 +709,7 @@ func (in *MonitoringConfig) DeepCopyInto(out *MonitoringConfig) {
 	if in.Perses != nil {
 		in, out := &in.Perses, &out.Perses
 		*out = new(PersesReference)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Incidents != nil {
 		in, out := &in.Incidents, &out.Incidents
@@ -188,7 +719,12 @@ func (in *MonitoringConfig) DeepCopyInto(out *MonitoringConfig) {
 	if in.ClusterHealthAnalyzer != nil {
 		in, out := &in.ClusterHealthAnalyzer, &out.ClusterHealthAnalyzer
 		*out = new(ClusterHealthAnalyzerReference)
-		**out = **in
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Backends != nil {
+		in, out := &in.Backends, &out.Backends
+		*out = new(MonitoringBackendsReference)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -202,9 +738,83 @@ func (in *MonitoringConfig) DeepCopy() *MonitoringConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespacedReference) DeepCopyInto(out *NamespacedReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacedReference.
+func (in *NamespacedReference) DeepCopy() *NamespacedReference {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespacedReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PersesCatalogDashboard) DeepCopyInto(out *PersesCatalogDashboard) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PersesCatalogDashboard.
+func (in *PersesCatalogDashboard) DeepCopy() *PersesCatalogDashboard {
+	if in == nil {
+		return nil
+	}
+	out := new(PersesCatalogDashboard)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PersesReference) DeepCopyInto(out *PersesReference) {
 	*out = *in
+	if in.Dashboards != nil {
+		in, out := &in.Dashboards, &out.Dashboards
+		*out = make([]PersesCatalogDashboard, len(*in))
+		copy(*out, *in)
+	}
+	if in.APM != nil {
+		in, out := &in.APM, &out.APM
+		*out = new(APMDashboardConfig)
+		**out = **in
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(PersesStorage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PersesReference.
@@ -217,6 +827,121 @@ func (in *PersesReference) DeepCopy() *PersesReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PersesSQLStorage) DeepCopyInto(out *PersesSQLStorage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PersesSQLStorage.
+func (in *PersesSQLStorage) DeepCopy() *PersesSQLStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(PersesSQLStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PersesStorage) DeepCopyInto(out *PersesStorage) {
+	*out = *in
+	if in.PersistentVolumeClaim != nil {
+		in, out := &in.PersistentVolumeClaim, &out.PersistentVolumeClaim
+		*out = new(v1.PersistentVolumeClaimSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SQL != nil {
+		in, out := &in.SQL, &out.SQL
+		*out = new(PersesSQLStorage)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PersesStorage.
+func (in *PersesStorage) DeepCopy() *PersesStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(PersesStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDisruptionBudgetConfig) DeepCopyInto(out *PodDisruptionBudgetConfig) {
+	*out = *in
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDisruptionBudgetConfig.
+func (in *PodDisruptionBudgetConfig) DeepCopy() *PodDisruptionBudgetConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PodDisruptionBudgetConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProxyAuthorization) DeepCopyInto(out *ProxyAuthorization) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxyAuthorization.
+func (in *ProxyAuthorization) DeepCopy() *ProxyAuthorization {
+	if in == nil {
+		return nil
+	}
+	out := new(ProxyAuthorization)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TempoInstanceReference) DeepCopyInto(out *TempoInstanceReference) {
+	*out = *in
+	if in.Tenants != nil {
+		in, out := &in.Tenants, &out.Tenants
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TempoInstanceReference.
+func (in *TempoInstanceReference) DeepCopy() *TempoInstanceReference {
+	if in == nil {
+		return nil
+	}
+	out := new(TempoInstanceReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TempoStackReference) DeepCopyInto(out *TempoStackReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TempoStackReference.
+func (in *TempoStackReference) DeepCopy() *TempoStackReference {
+	if in == nil {
+		return nil
+	}
+	out := new(TempoStackReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ThanosQuerierReference) DeepCopyInto(out *ThanosQuerierReference) {
 	*out = *in
@@ -235,6 +960,23 @@ func (in *ThanosQuerierReference) DeepCopy() *ThanosQuerierReference {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TroubleshootingPanelConfig) DeepCopyInto(out *TroubleshootingPanelConfig) {
 	*out = *in
+	if in.Stores != nil {
+		in, out := &in.Stores, &out.Stores
+		*out = make([]Korrel8rStore, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]Korrel8rRulesReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Korrel8r != nil {
+		in, out := &in.Korrel8r, &out.Korrel8r
+		*out = new(Korrel8rConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TroubleshootingPanelConfig.
@@ -274,6 +1016,21 @@ func (in *UIPlugin) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UIPluginComponentStatus) DeepCopyInto(out *UIPluginComponentStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UIPluginComponentStatus.
+func (in *UIPluginComponentStatus) DeepCopy() *UIPluginComponentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UIPluginComponentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UIPluginList) DeepCopyInto(out *UIPluginList) {
 	*out = *in
@@ -317,12 +1074,12 @@ func (in *UIPluginSpec) DeepCopyInto(out *UIPluginSpec) {
 	if in.TroubleshootingPanel != nil {
 		in, out := &in.TroubleshootingPanel, &out.TroubleshootingPanel
 		*out = new(TroubleshootingPanelConfig)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.DistributedTracing != nil {
 		in, out := &in.DistributedTracing, &out.DistributedTracing
 		*out = new(DistributedTracingConfig)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Logging != nil {
 		in, out := &in.Logging, &out.Logging
@@ -334,6 +1091,21 @@ func (in *UIPluginSpec) DeepCopyInto(out *UIPluginSpec) {
 		*out = new(MonitoringConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Custom != nil {
+		in, out := &in.Custom, &out.Custom
+		*out = new(CustomPluginConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProxyAuthorization != nil {
+		in, out := &in.ProxyAuthorization, &out.ProxyAuthorization
+		*out = make([]ProxyAuthorization, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exposure != nil {
+		in, out := &in.Exposure, &out.Exposure
+		*out = new(ExposureConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UIPluginSpec.
@@ -356,6 +1128,16 @@ func (in *UIPluginStatus) DeepCopyInto(out *UIPluginStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Features != nil {
+		in, out := &in.Features, &out.Features
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]UIPluginComponentStatus, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UIPluginStatus.
@@ -23,6 +23,10 @@ const (
 	AlertmanagerUserFSGroupID            = int64(65535)
 
 	prometheusSecretsMountPoint = "/etc/prometheus/secrets"
+
+	// servingCertSecretNameAnnotation instructs the OpenShift service CA
+	// operator to generate a serving certificate for the annotated service.
+	servingCertSecretNameAnnotation = "service.beta.openshift.io/serving-cert-secret-name"
 )
 
 var (
@@ -246,6 +250,10 @@ func newPrometheus(
 		prometheus.Spec.Secrets = append(prometheus.Spec.Secrets, tlsConfig.CertificateAuthority.Name)
 	}
 
+	if ms.Spec.PrometheusConfig.ThanosSidecarTLSConfig != nil {
+		prometheus.Spec.Thanos.GRPCServerTLSConfig = thanosSidecarGRPCServerTLSConfig(ms)
+	}
+
 	if prometheusCfg.Image != "" {
 		prometheus.Spec.CommonPrometheusFields.Image = ptr.To(prometheusCfg.Image)
 	}
@@ -285,6 +293,60 @@ func newPrometheus(
 	return prometheus
 }
 
+// thanosSidecarGRPCServerTLSConfig returns the TLS configuration of the Thanos
+// sidecar gRPC server. When the OpenShift service CA is used, the certificate
+// is read from the secret generated for the sidecar service.
+func thanosSidecarGRPCServerTLSConfig(ms *stack.MonitoringStack) *monv1.TLSConfig {
+	tlsConfig := ms.Spec.PrometheusConfig.ThanosSidecarTLSConfig
+
+	if tlsConfig.ServiceCA {
+		secretName := thanosSidecarServingCertSecretName(ms)
+		return &monv1.TLSConfig{
+			SafeTLSConfig: monv1.SafeTLSConfig{
+				Cert: monv1.SecretOrConfigMap{
+					Secret: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+						Key:                  corev1.TLSCertKey,
+					},
+				},
+				KeySecret: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+					Key:                  corev1.TLSPrivateKeyKey,
+				},
+			},
+		}
+	}
+
+	grpcTLSConfig := &monv1.TLSConfig{
+		SafeTLSConfig: monv1.SafeTLSConfig{
+			Cert: monv1.SecretOrConfigMap{
+				Secret: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: tlsConfig.Certificate.Name},
+					Key:                  tlsConfig.Certificate.Key,
+				},
+			},
+			KeySecret: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: tlsConfig.PrivateKey.Name},
+				Key:                  tlsConfig.PrivateKey.Key,
+			},
+		},
+	}
+	if tlsConfig.CertificateAuthority != nil {
+		grpcTLSConfig.CA = monv1.SecretOrConfigMap{
+			Secret: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: tlsConfig.CertificateAuthority.Name},
+				Key:                  tlsConfig.CertificateAuthority.Key,
+			},
+		}
+	}
+
+	return grpcTLSConfig
+}
+
+func thanosSidecarServingCertSecretName(ms *stack.MonitoringStack) string {
+	return ms.Name + "-thanos-sidecar-tls"
+}
+
 func storageForPVC(pvc *corev1.PersistentVolumeClaimSpec) *monv1.StorageSpec {
 	if pvc == nil {
 		return nil
@@ -376,14 +438,23 @@ func newPrometheusService(ms *stack.MonitoringStack) *corev1.Service {
 
 func newThanosSidecarService(ms *stack.MonitoringStack) *corev1.Service {
 	name := ms.Name + "-thanos-sidecar"
+
+	var annotations map[string]string
+	if tlsConfig := ms.Spec.PrometheusConfig.ThanosSidecarTLSConfig; tlsConfig != nil && tlsConfig.ServiceCA {
+		annotations = map[string]string{
+			servingCertSecretNameAnnotation: thanosSidecarServingCertSecretName(ms),
+		}
+	}
+
 	return &corev1.Service{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: corev1.SchemeGroupVersion.String(),
 			Kind:       "Service",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: ms.Namespace,
+			Name:        name,
+			Namespace:   ms.Namespace,
+			Annotations: annotations,
 		},
 		Spec: corev1.ServiceSpec{
 			// NOTE: Setting this to "None" makes a "headless service" (no virtual
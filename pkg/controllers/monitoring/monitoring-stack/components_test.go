@@ -113,3 +113,65 @@ func TestNewAdditionalScrapeConfigsSecret(t *testing.T) {
 		})
 	}
 }
+
+func TestThanosSidecarGRPCServerTLS(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		tlsConfig  *stack.GRPCServerTLSConfig
+		expected   *monv1.TLSConfig
+		annotation string
+	}{
+		{
+			name: "no-tls",
+		},
+		{
+			name:      "service-ca",
+			tlsConfig: &stack.GRPCServerTLSConfig{ServiceCA: true},
+			expected: &monv1.TLSConfig{
+				SafeTLSConfig: monv1.SafeTLSConfig{
+					Cert: monv1.SecretOrConfigMap{
+						Secret: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "ms-thanos-sidecar-tls"}, Key: "tls.crt"},
+					},
+					KeySecret: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "ms-thanos-sidecar-tls"}, Key: "tls.key"},
+				},
+			},
+			annotation: "ms-thanos-sidecar-tls",
+		},
+		{
+			name: "mtls",
+			tlsConfig: &stack.GRPCServerTLSConfig{
+				CertificateAuthority: &stack.SecretKeySelector{Name: "grpc-ca", Key: "ca.crt"},
+				Certificate:          &stack.SecretKeySelector{Name: "grpc-tls", Key: "tls.crt"},
+				PrivateKey:           &stack.SecretKeySelector{Name: "grpc-tls", Key: "tls.key"},
+			},
+			expected: &monv1.TLSConfig{
+				SafeTLSConfig: monv1.SafeTLSConfig{
+					CA: monv1.SecretOrConfigMap{
+						Secret: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "grpc-ca"}, Key: "ca.crt"},
+					},
+					Cert: monv1.SecretOrConfigMap{
+						Secret: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "grpc-tls"}, Key: "tls.crt"},
+					},
+					KeySecret: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "grpc-tls"}, Key: "tls.key"},
+				},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ms := &stack.MonitoringStack{
+				ObjectMeta: metav1.ObjectMeta{Name: "ms", Namespace: "ns"},
+				Spec: stack.MonitoringStackSpec{
+					PrometheusConfig: &stack.PrometheusConfig{ThanosSidecarTLSConfig: tc.tlsConfig},
+				},
+			}
+
+			// The Prometheus operator configures the gRPC server of the
+			// sidecar container from the TLS configuration.
+			prometheus := newPrometheus(ms, "ms-prometheus", "ms-self-scrape", ThanosConfiguration{}, PrometheusConfiguration{})
+			assert.DeepEqual(t, prometheus.Spec.Thanos.GRPCServerTLSConfig, tc.expected)
+
+			service := newThanosSidecarService(ms)
+			assert.Equal(t, service.Annotations[servingCertSecretNameAnnotation], tc.annotation)
+		})
+	}
+}
@@ -2,31 +2,134 @@ package thanos_querier
 
 import (
 	"fmt"
+	"hash/fnv"
+	"strings"
 
 	monv1 "github.com/rhobs/obo-prometheus-operator/pkg/apis/monitoring/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/utils/ptr"
 
 	msoapi "github.com/rhobs/observability-operator/pkg/apis/monitoring/v1alpha1"
 	"github.com/rhobs/observability-operator/pkg/reconciler"
 )
 
+const (
+	grpcTLSAssetsMountPath = "/etc/thanos/tls-assets/grpc"
+
+	// injectCABundleAnnotation instructs the OpenShift service CA operator to
+	// inject the service CA bundle into the annotated ConfigMap.
+	injectCABundleAnnotation = "service.beta.openshift.io/inject-cabundle"
+	serviceCABundleKey       = "service-ca.crt"
+
+	// servingCertSecretNameAnnotation instructs the OpenShift service CA
+	// operator to generate a serving certificate for the annotated service.
+	servingCertSecretNameAnnotation = "service.beta.openshift.io/serving-cert-secret-name"
+
+	// The annotations of cluster-scoped resources reference the ThanosQuerier
+	// since they can't have a namespaced owner.
+	querierNamespaceAnnotation = "monitoring.rhobs/thanos-querier-namespace"
+	querierNameAnnotation      = "monitoring.rhobs/thanos-querier-name"
+)
+
 func thanosComponentReconcilers(
 	thanos *msoapi.ThanosQuerier,
 	sidecarUrls []string,
 	thanosCfg ThanosConfiguration,
 	tlsHashes map[string]string,
+	caps clusterCapabilities,
 ) []reconciler.Reconciler {
 	name := "thanos-querier-" + thanos.Name
-	return []reconciler.Reconciler{
-		reconciler.NewUpdater(newServiceAccount(name, thanos.Namespace), thanos),
+	reconcilers := []reconciler.Reconciler{
+		reconciler.NewUpdater(newServiceAccount(name, thanos), thanos),
 		reconciler.NewUpdater(newThanosQuerierDeployment(name, thanos, sidecarUrls, thanosCfg, tlsHashes), thanos),
-		reconciler.NewUpdater(newService(name, thanos.Namespace), thanos),
+		reconciler.NewUpdater(newService(name, thanos), thanos),
 		reconciler.NewUpdater(newServiceMonitor(name, thanos.Namespace, thanos), thanos),
 		reconciler.NewOptionalUpdater(newHttpConfConfigMap(name, thanos), thanos, thanos.Spec.WebTLSConfig != nil),
+		reconciler.NewOptionalUpdater(newServiceCAConfigMap(name, thanos.Namespace), thanos,
+			thanos.Spec.GRPCClientTLSConfig != nil && thanos.Spec.GRPCClientTLSConfig.ServiceCA),
 	}
+
+	reconcilers = append(reconcilers, uiComponentReconcilers(name, thanos, caps)...)
+	return append(reconcilers, persesComponentReconcilers(name, thanos, caps)...)
+}
+
+// clusterComponentCleanup returns the reconcilers removing the cluster-scoped
+// resources of the ThanosQuerier which aren't garbage collected.
+func clusterComponentCleanup(thanos *msoapi.ThanosQuerier, caps clusterCapabilities) []reconciler.Reconciler {
+	name := "thanos-querier-" + thanos.Name
+	reconcilers := []reconciler.Reconciler{
+		reconciler.NewDeleter(newOAuthProxyClusterRoleBinding(name, thanos)),
+	}
+
+	return append(reconcilers, persesComponentCleanup(thanos, caps)...)
+}
+
+// needsFinalizer returns true when the ThanosQuerier has cluster-scoped
+// resources to remove on deletion.
+func needsFinalizer(thanos *msoapi.ThanosQuerier) bool {
+	return (thanos.Spec.UI != nil && thanos.Spec.UI.OAuthProxy) ||
+		persesDatasourceScope(thanos) == msoapi.GlobalPersesDatasource
+}
+
+// clusterScopedName returns a unique name for a cluster-scoped resource of
+// the ThanosQuerier. Concatenating the namespace and the name isn't enough
+// (e.g. a-b/c and a/b-c) so a hash of both is appended.
+func clusterScopedName(prefix string, thanos *msoapi.ThanosQuerier) string {
+	h := fnv.New32a()
+	h.Write([]byte(thanos.Namespace + "/" + thanos.Name))
+	suffix := fmt.Sprintf("-%08x", h.Sum32())
+
+	name := fmt.Sprintf("%s-%s-%s", prefix, thanos.Namespace, thanos.Name)
+	if len(name)+len(suffix) > validation.DNS1123SubdomainMaxLength {
+		name = strings.TrimRight(name[:validation.DNS1123SubdomainMaxLength-len(suffix)], "-.")
+	}
+
+	return name + suffix
+}
+
+// querierAnnotations returns the annotations referencing the ThanosQuerier
+// of a cluster-scoped resource.
+func querierAnnotations(thanos *msoapi.ThanosQuerier) map[string]string {
+	return map[string]string{
+		querierNamespaceAnnotation: thanos.Namespace,
+		querierNameAnnotation:      thanos.Name,
+	}
+}
+
+// querierReference returns the ThanosQuerier which created the cluster-scoped
+// resource.
+func querierReference(obj metav1.Object) (types.NamespacedName, bool) {
+	namespace, name := obj.GetAnnotations()[querierNamespaceAnnotation], obj.GetAnnotations()[querierNameAnnotation]
+	if namespace == "" || name == "" {
+		return types.NamespacedName{}, false
+	}
+	return types.NamespacedName{Namespace: namespace, Name: name}, true
+}
+
+// newServiceCAConfigMap returns a ConfigMap in which the OpenShift service CA
+// operator injects the CA bundle used to verify the sidecar certificates.
+func newServiceCAConfigMap(name string, namespace string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      serviceCAConfigMapName(name),
+			Namespace: namespace,
+			Annotations: map[string]string{
+				injectCABundleAnnotation: "true",
+			},
+		},
+	}
+}
+
+func serviceCAConfigMapName(name string) string {
+	return fmt.Sprintf("%s-service-ca", name)
 }
 
 func newHttpConfConfigMap(name string, thanos *msoapi.ThanosQuerier) *corev1.ConfigMap {
@@ -75,10 +178,15 @@ func newThanosQuerierDeployment(
 		args = append(args, fmt.Sprintf("--query.replica-label=%s", rl))
 	}
 
+	args = append(args, queryConfigArgs(spec.Spec.QueryConfig)...)
+
 	if spec.Spec.WebTLSConfig != nil {
 		args = append(args, "--http.config=/etc/thanos/tls-assets/web-http-conf-cm/http.conf")
 	}
 
+	grpcVolumes, grpcVolumeMounts, grpcArgs := grpcClientTLSConfig(name, spec.Spec.GRPCClientTLSConfig)
+	args = append(args, grpcArgs...)
+
 	thanos := &appsv1.Deployment{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: appsv1.SchemeGroupVersion.String(),
@@ -103,6 +211,7 @@ func newThanosQuerierDeployment(
 					Labels:    componentLabels(name),
 				},
 				Spec: corev1.PodSpec{
+					ServiceAccountName: name,
 					Containers: []corev1.Container{
 						{
 							Name:  "thanos-querier",
@@ -189,6 +298,16 @@ func newThanosQuerierDeployment(
 				ReadOnly:  true,
 			},
 		}...)
+	}
+
+	thanos.Spec.Template.Spec.Volumes = append(thanos.Spec.Template.Spec.Volumes, grpcVolumes...)
+	thanos.Spec.Template.Spec.Containers[0].VolumeMounts = append(thanos.Spec.Template.Spec.Containers[0].VolumeMounts, grpcVolumeMounts...)
+
+	if spec.Spec.UI != nil && spec.Spec.UI.OAuthProxy {
+		addOAuthProxy(&thanos.Spec.Template.Spec, name, spec, thanosCfg.OAuthProxyImage)
+	}
+
+	if len(tlsHashes) > 0 {
 		tlsAnnotations := map[string]string{}
 		for name, hash := range tlsHashes {
 			tlsAnnotations[fmt.Sprintf("monitoring.openshift.io/%s-hash", name)] = hash
@@ -199,33 +318,133 @@ func newThanosQuerierDeployment(
 	return thanos
 }
 
-func newServiceAccount(name string, namespace string) *corev1.ServiceAccount {
+func queryConfigArgs(queryConfig *msoapi.ThanosQueryConfig) []string {
+	if queryConfig == nil {
+		return nil
+	}
+
+	var args []string
+	if queryConfig.Timeout != nil {
+		args = append(args, fmt.Sprintf("--query.timeout=%s", *queryConfig.Timeout))
+	}
+	if queryConfig.MaxConcurrent != nil {
+		args = append(args, fmt.Sprintf("--query.max-concurrent=%d", *queryConfig.MaxConcurrent))
+	}
+	if queryConfig.PartialResponse != nil {
+		if *queryConfig.PartialResponse {
+			args = append(args, "--query.partial-response")
+		} else {
+			args = append(args, "--no-query.partial-response")
+		}
+	}
+	if queryConfig.LookbackDelta != nil {
+		args = append(args, fmt.Sprintf("--query.lookback-delta=%s", *queryConfig.LookbackDelta))
+	}
+	if queryConfig.DeduplicationFunc != "" {
+		args = append(args, fmt.Sprintf("--deduplication.func=%s", queryConfig.DeduplicationFunc))
+	}
+	if queryConfig.PromQLEngine != "" {
+		args = append(args, fmt.Sprintf("--query.promql-engine=%s", queryConfig.PromQLEngine))
+	}
+
+	return args
+}
+
+// grpcClientTLSConfig returns the volumes, volume mounts and arguments
+// required by Thanos Querier to connect to the sidecars over TLS.
+func grpcClientTLSConfig(name string, tlsConfig *msoapi.GRPCClientTLSConfig) ([]corev1.Volume, []corev1.VolumeMount, []string) {
+	if tlsConfig == nil {
+		return nil, nil, nil
+	}
+
+	var (
+		volumes      []corev1.Volume
+		volumeMounts []corev1.VolumeMount
+		args         = []string{"--grpc-client-tls-secure"}
+	)
+
+	if tlsConfig.ServiceCA {
+		volumes = append(volumes, corev1.Volume{
+			Name: "thanos-grpc-tls-ca",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: serviceCAConfigMapName(name),
+					},
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      "thanos-grpc-tls-ca",
+			MountPath: grpcTLSAssetsMountPath + "/ca",
+			ReadOnly:  true,
+		})
+		args = append(args, fmt.Sprintf("--grpc-client-tls-ca=%s/ca/%s", grpcTLSAssetsMountPath, serviceCABundleKey))
+	}
+
+	for _, asset := range []struct {
+		selector *msoapi.SecretKeySelector
+		name     string
+		flag     string
+	}{
+		{selector: tlsConfig.CertificateAuthority, name: "ca", flag: "--grpc-client-tls-ca"},
+		{selector: tlsConfig.Certificate, name: "cert", flag: "--grpc-client-tls-cert"},
+		{selector: tlsConfig.PrivateKey, name: "key", flag: "--grpc-client-tls-key"},
+	} {
+		if asset.selector == nil {
+			continue
+		}
+		volumes = append(volumes, corev1.Volume{
+			Name: "thanos-grpc-tls-" + asset.name,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: asset.selector.Name,
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      "thanos-grpc-tls-" + asset.name,
+			MountPath: fmt.Sprintf("%s/%s", grpcTLSAssetsMountPath, asset.name),
+			ReadOnly:  true,
+		})
+		args = append(args, fmt.Sprintf("%s=%s/%s/%s", asset.flag, grpcTLSAssetsMountPath, asset.name, asset.selector.Key))
+	}
+
+	if tlsConfig.ServerName != "" {
+		args = append(args, fmt.Sprintf("--grpc-client-server-name=%s", tlsConfig.ServerName))
+	}
+
+	return volumes, volumeMounts, args
+}
+
+func newServiceAccount(name string, thanos *msoapi.ThanosQuerier) *corev1.ServiceAccount {
 	return &corev1.ServiceAccount{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: corev1.SchemeGroupVersion.String(),
 			Kind:       "ServiceAccount",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: namespace,
+			Name:        name,
+			Namespace:   thanos.Namespace,
+			Annotations: oauthRedirectAnnotations(name, thanos.Spec.UI),
 		},
 	}
 }
 
-func newService(name string, namespace string) *corev1.Service {
-	return &corev1.Service{
+func newService(name string, thanos *msoapi.ThanosQuerier) *corev1.Service {
+	service := &corev1.Service{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: corev1.SchemeGroupVersion.String(),
 			Kind:       "Service",
 		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
-			Namespace: namespace,
+			Namespace: thanos.Namespace,
 		},
 		Spec: corev1.ServiceSpec{
 			Ports: []corev1.ServicePort{
 				{
-					Port: 10902,
+					Port: webPort,
 					Name: "http",
 				},
 			},
@@ -235,6 +454,18 @@ func newService(name string, namespace string) *corev1.Service {
 			Type: "ClusterIP",
 		},
 	}
+
+	if thanos.Spec.UI != nil && thanos.Spec.UI.OAuthProxy {
+		service.Annotations = map[string]string{
+			servingCertSecretNameAnnotation: oauthProxyTLSSecretName(name),
+		}
+		service.Spec.Ports = append(service.Spec.Ports, corev1.ServicePort{
+			Port: oauthProxyPort,
+			Name: oauthProxyPortName,
+		})
+	}
+
+	return service
 }
 
 func newServiceMonitor(name string, namespace string, thanos *msoapi.ThanosQuerier) *monv1.ServiceMonitor {
@@ -0,0 +1,357 @@
+package thanos_querier
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	routev1 "github.com/openshift/api/route/v1"
+	monv1 "github.com/rhobs/obo-prometheus-operator/pkg/apis/monitoring/v1"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
+
+	msoapi "github.com/rhobs/observability-operator/pkg/apis/monitoring/v1alpha1"
+)
+
+func TestGetEndpointUrl(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		tlsConfig *msoapi.GRPCClientTLSConfig
+		expected  string
+	}{
+		{
+			name:     "no-tls",
+			expected: "dnssrv+_grpc._tcp.ms-thanos-sidecar.ns.svc.cluster.local",
+		},
+		{
+			name:      "tls",
+			tlsConfig: &msoapi.GRPCClientTLSConfig{ServiceCA: true},
+			expected:  "dnssrvnoa+_grpc._tcp.ms-thanos-sidecar.ns.svc.cluster.local",
+		},
+		{
+			name: "tls-with-server-name",
+			tlsConfig: &msoapi.GRPCClientTLSConfig{
+				CertificateAuthority: &msoapi.SecretKeySelector{Name: "grpc-tls", Key: "ca.crt"},
+				ServerName:           "thanos-sidecar",
+			},
+			expected: "dnssrv+_grpc._tcp.ms-thanos-sidecar.ns.svc.cluster.local",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, getEndpointUrl("ms-thanos-sidecar", "ns", tc.tlsConfig), tc.expected)
+		})
+	}
+}
+
+func TestGRPCClientTLSArgs(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		tlsConfig *msoapi.GRPCClientTLSConfig
+		expected  []string
+	}{
+		{
+			name: "no-tls",
+		},
+		{
+			name:      "service-ca",
+			tlsConfig: &msoapi.GRPCClientTLSConfig{ServiceCA: true},
+			expected: []string{
+				"--grpc-client-tls-secure",
+				"--grpc-client-tls-ca=/etc/thanos/tls-assets/grpc/ca/service-ca.crt",
+			},
+		},
+		{
+			name: "mtls",
+			tlsConfig: &msoapi.GRPCClientTLSConfig{
+				CertificateAuthority: &msoapi.SecretKeySelector{Name: "grpc-tls", Key: "ca.crt"},
+				Certificate:          &msoapi.SecretKeySelector{Name: "grpc-tls", Key: "tls.crt"},
+				PrivateKey:           &msoapi.SecretKeySelector{Name: "grpc-tls", Key: "tls.key"},
+				ServerName:           "thanos-sidecar",
+			},
+			expected: []string{
+				"--grpc-client-tls-secure",
+				"--grpc-client-tls-ca=/etc/thanos/tls-assets/grpc/ca/ca.crt",
+				"--grpc-client-tls-cert=/etc/thanos/tls-assets/grpc/cert/tls.crt",
+				"--grpc-client-tls-key=/etc/thanos/tls-assets/grpc/key/tls.key",
+				"--grpc-client-server-name=thanos-sidecar",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			volumes, volumeMounts, args := grpcClientTLSConfig("thanos-querier-querier", tc.tlsConfig)
+			assert.DeepEqual(t, args, tc.expected)
+			assert.Equal(t, len(volumes), len(volumeMounts))
+		})
+	}
+}
+
+func TestThanosQuerierDeploymentGRPCClientTLS(t *testing.T) {
+	querier := &msoapi.ThanosQuerier{
+		ObjectMeta: metav1.ObjectMeta{Name: "querier", Namespace: "ns"},
+		Spec: msoapi.ThanosQuerierSpec{
+			GRPCClientTLSConfig: &msoapi.GRPCClientTLSConfig{
+				CertificateAuthority: &msoapi.SecretKeySelector{Name: "grpc-ca", Key: "ca.crt"},
+				Certificate:          &msoapi.SecretKeySelector{Name: "grpc-tls", Key: "tls.crt"},
+				PrivateKey:           &msoapi.SecretKeySelector{Name: "grpc-tls", Key: "tls.key"},
+			},
+		},
+	}
+
+	deployment := newThanosQuerierDeployment("thanos-querier-querier", querier, []string{getEndpointUrl("ms-thanos-sidecar", "ns", querier.Spec.GRPCClientTLSConfig)}, ThanosConfiguration{}, nil)
+	pod := deployment.Spec.Template.Spec
+	container := pod.Containers[0]
+
+	for _, arg := range []string{
+		"--endpoint=dnssrvnoa+_grpc._tcp.ms-thanos-sidecar.ns.svc.cluster.local",
+		"--grpc-client-tls-secure",
+		"--grpc-client-tls-ca=/etc/thanos/tls-assets/grpc/ca/ca.crt",
+		"--grpc-client-tls-cert=/etc/thanos/tls-assets/grpc/cert/tls.crt",
+		"--grpc-client-tls-key=/etc/thanos/tls-assets/grpc/key/tls.key",
+	} {
+		assert.Assert(t, slices.Contains(container.Args, arg), "missing argument %s", arg)
+	}
+
+	// Every file referenced by the arguments is mounted from its secret.
+	for asset, secret := range map[string]string{"ca": "grpc-ca", "cert": "grpc-tls", "key": "grpc-tls"} {
+		volumeName := "thanos-grpc-tls-" + asset
+		assert.Assert(t, slices.ContainsFunc(container.VolumeMounts, func(m corev1.VolumeMount) bool {
+			return m.Name == volumeName && m.MountPath == "/etc/thanos/tls-assets/grpc/"+asset
+		}), "missing volume mount %s", volumeName)
+		assert.Assert(t, slices.ContainsFunc(pod.Volumes, func(v corev1.Volume) bool {
+			return v.Name == volumeName && v.Secret != nil && v.Secret.SecretName == secret
+		}), "missing volume %s", volumeName)
+	}
+}
+
+func TestQueryConfigArgs(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		queryConfig *msoapi.ThanosQueryConfig
+		expected    []string
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name: "all-options",
+			queryConfig: &msoapi.ThanosQueryConfig{
+				Timeout:           ptr.To(monv1.Duration("2m")),
+				MaxConcurrent:     ptr.To(int32(40)),
+				PartialResponse:   ptr.To(false),
+				LookbackDelta:     ptr.To(monv1.Duration("15m")),
+				DeduplicationFunc: msoapi.ChainDeduplication,
+				PromQLEngine:      msoapi.ThanosPromQLEngine,
+			},
+			expected: []string{
+				"--query.timeout=2m",
+				"--query.max-concurrent=40",
+				"--no-query.partial-response",
+				"--query.lookback-delta=15m",
+				"--deduplication.func=chain",
+				"--query.promql-engine=thanos",
+			},
+		},
+		{
+			name: "partial-response",
+			queryConfig: &msoapi.ThanosQueryConfig{
+				PartialResponse: ptr.To(true),
+			},
+			expected: []string{
+				"--query.partial-response",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.DeepEqual(t, queryConfigArgs(tc.queryConfig), tc.expected)
+		})
+	}
+}
+
+func TestNewRouteTermination(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		spec     msoapi.ThanosQuerierSpec
+		port     string
+		expected routev1.TLSTerminationType
+	}{
+		{
+			name:     "plain-http",
+			spec:     msoapi.ThanosQuerierSpec{UI: &msoapi.ThanosQuerierUIConfig{Type: msoapi.RouteExposure}},
+			port:     "http",
+			expected: routev1.TLSTerminationEdge,
+		},
+		{
+			name: "web-tls",
+			spec: msoapi.ThanosQuerierSpec{
+				UI:           &msoapi.ThanosQuerierUIConfig{Type: msoapi.RouteExposure},
+				WebTLSConfig: &msoapi.WebTLSConfig{},
+			},
+			port:     "http",
+			expected: routev1.TLSTerminationPassthrough,
+		},
+		{
+			name: "oauth-proxy",
+			spec: msoapi.ThanosQuerierSpec{
+				UI:           &msoapi.ThanosQuerierUIConfig{Type: msoapi.RouteExposure, OAuthProxy: true},
+				WebTLSConfig: &msoapi.WebTLSConfig{},
+			},
+			port:     "oauth-proxy",
+			expected: routev1.TLSTerminationReencrypt,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			route := newRoute("thanos-querier-tq", &msoapi.ThanosQuerier{Spec: tc.spec})
+			assert.Equal(t, route.Spec.TLS.Termination, tc.expected)
+			assert.Equal(t, route.Spec.Port.TargetPort.StrVal, tc.port)
+		})
+	}
+}
+
+func TestNewIngressBackendProtocol(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		spec        msoapi.ThanosQuerierSpec
+		annotations map[string]string
+	}{
+		{
+			name: "plain-http",
+			spec: msoapi.ThanosQuerierSpec{UI: &msoapi.ThanosQuerierUIConfig{Type: msoapi.IngressExposure, Host: "thanos.example.com"}},
+		},
+		{
+			name: "web-tls",
+			spec: msoapi.ThanosQuerierSpec{
+				UI:           &msoapi.ThanosQuerierUIConfig{Type: msoapi.IngressExposure, Host: "thanos.example.com"},
+				WebTLSConfig: &msoapi.WebTLSConfig{},
+			},
+			annotations: map[string]string{
+				"nginx.ingress.kubernetes.io/backend-protocol": "HTTPS",
+				"route.openshift.io/termination":               "passthrough",
+			},
+		},
+		{
+			name: "oauth-proxy",
+			spec: msoapi.ThanosQuerierSpec{
+				UI: &msoapi.ThanosQuerierUIConfig{
+					Type:       msoapi.IngressExposure,
+					Host:       "thanos.example.com",
+					OAuthProxy: true,
+					Ingress: &msoapi.IngressConfig{
+						Annotations: map[string]string{"haproxy.org/server-ssl": "true"},
+					},
+				},
+			},
+			annotations: map[string]string{
+				"nginx.ingress.kubernetes.io/backend-protocol": "HTTPS",
+				"route.openshift.io/termination":               "reencrypt",
+				"haproxy.org/server-ssl":                       "true",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ingress := newIngress("thanos-querier-tq", &msoapi.ThanosQuerier{Spec: tc.spec})
+			assert.DeepEqual(t, ingress.Annotations, tc.annotations)
+		})
+	}
+}
+
+func TestOAuthProxyAuthorization(t *testing.T) {
+	querier := &msoapi.ThanosQuerier{
+		ObjectMeta: metav1.ObjectMeta{Name: "querier", Namespace: "ns"},
+		Spec: msoapi.ThanosQuerierSpec{
+			UI: &msoapi.ThanosQuerierUIConfig{Type: msoapi.RouteExposure, OAuthProxy: true},
+		},
+	}
+
+	pod := corev1.PodSpec{}
+	addOAuthProxy(&pod, "thanos-querier-querier", querier, "")
+	args := pod.Containers[0].Args
+
+	// Bearer tokens are authorized like browser sessions.
+	for _, arg := range []string{
+		`-openshift-sar={"namespace":"ns","resource":"services","resourceName":"thanos-querier-querier","verb":"get"}`,
+		`-openshift-delegate-urls={"/":{"name":"thanos-querier-querier","namespace":"ns","resource":"services","verb":"get"}}`,
+	} {
+		assert.Assert(t, slices.Contains(args, arg), "missing argument %s", arg)
+	}
+
+	crb := newOAuthProxyClusterRoleBinding("thanos-querier-querier", querier)
+	assert.Equal(t, crb.RoleRef.Name, "system:auth-delegator")
+	assert.Equal(t, crb.Subjects[0].Name, "thanos-querier-querier")
+	assert.Equal(t, crb.Subjects[0].Namespace, "ns")
+
+	owner, ok := querierReference(crb)
+	assert.Assert(t, ok)
+	assert.Equal(t, owner, types.NamespacedName{Namespace: "ns", Name: "querier"})
+}
+
+func TestClusterScopedName(t *testing.T) {
+	newQuerier := func(namespace, name string) *msoapi.ThanosQuerier {
+		return &msoapi.ThanosQuerier{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	}
+
+	// The concatenation of the namespace and the name is ambiguous.
+	assert.Assert(t, clusterScopedName("prefix", newQuerier("a-b", "c")) != clusterScopedName("prefix", newQuerier("a", "b-c")))
+
+	name := clusterScopedName("prefix", newQuerier(strings.Repeat("n", 63), strings.Repeat("q", 253)))
+	assert.Equal(t, len(validation.IsDNS1123Subdomain(name)), 0, name)
+}
+
+func TestPersesGlobalDatasourceOwner(t *testing.T) {
+	querier := &msoapi.ThanosQuerier{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "tq"}}
+
+	owner, ok := querierReference(newPersesGlobalDatasource(querier))
+	assert.Assert(t, ok)
+	assert.Equal(t, owner, types.NamespacedName{Namespace: "ns", Name: "tq"})
+}
+
+func TestNewPersesDatasourceSpec(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		spec        msoapi.ThanosQuerierSpec
+		url         string
+		displayName string
+		tls         bool
+	}{
+		{
+			name:        "defaults",
+			spec:        msoapi.ThanosQuerierSpec{PersesDatasource: &msoapi.ThanosQuerierPersesDatasource{}},
+			url:         "http://thanos-querier-tq.ns.svc.cluster.local:10902",
+			displayName: "Thanos Querier ns/tq",
+		},
+		{
+			name: "web-tls",
+			spec: msoapi.ThanosQuerierSpec{
+				PersesDatasource: &msoapi.ThanosQuerierPersesDatasource{DisplayName: "Federated metrics"},
+				WebTLSConfig: &msoapi.WebTLSConfig{
+					CertificateAuthority: msoapi.SecretKeySelector{Name: "web-tls", Key: "ca.crt"},
+				},
+			},
+			url:         "https://thanos-querier-tq.ns.svc.cluster.local:10902",
+			displayName: "Federated metrics",
+			tls:         true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			querier := &msoapi.ThanosQuerier{
+				ObjectMeta: metav1.ObjectMeta{Name: "tq", Namespace: "ns"},
+				Spec:       tc.spec,
+			}
+			spec := newPersesDatasourceSpec("thanos-querier-tq", querier)
+
+			assert.Equal(t, spec.Config.Display.Name, tc.displayName)
+			proxy := spec.Config.Plugin.Spec.(map[string]interface{})["proxy"].(map[string]interface{})
+			assert.Equal(t, proxy["spec"].(map[string]interface{})["url"], tc.url)
+			if !tc.tls {
+				assert.Assert(t, spec.Client == nil)
+				return
+			}
+			assert.Equal(t, *spec.Client.TLS.CaCert.Name, "web-tls")
+			assert.Equal(t, *spec.Client.TLS.CaCert.Namespace, "ns")
+			assert.Equal(t, spec.Client.TLS.CaCert.CertPath, "ca.crt")
+		})
+	}
+}
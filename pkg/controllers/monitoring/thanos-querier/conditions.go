@@ -0,0 +1,45 @@
+package thanos_querier
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	msoapi "github.com/rhobs/observability-operator/pkg/apis/monitoring/v1alpha1"
+)
+
+const (
+	ReconciledReason              = "ThanosQuerierReconciled"
+	FailedToReconcileReason       = "FailedToReconcile"
+	SuccessfullyReconciledMessage = "Thanos Querier is successfully reconciled"
+)
+
+// updateConditions returns the status conditions of the ThanosQuerier given
+// the reconciliation error (if any).
+func updateConditions(querier *msoapi.ThanosQuerier, recError error) []msoapi.Condition {
+	return []msoapi.Condition{
+		updateReconciled(querier.Status.Conditions, querier.Generation, recError),
+	}
+}
+
+func updateReconciled(conditions []msoapi.Condition, generation int64, recError error) msoapi.Condition {
+	rc := msoapi.Condition{
+		Type:               msoapi.ReconciledCondition,
+		Status:             msoapi.ConditionTrue,
+		Reason:             ReconciledReason,
+		Message:            SuccessfullyReconciledMessage,
+		ObservedGeneration: generation,
+	}
+	if recError != nil {
+		rc.Status = msoapi.ConditionFalse
+		rc.Reason = FailedToReconcileReason
+		rc.Message = recError.Error()
+	}
+
+	rc.LastTransitionTime = metav1.Now()
+	for _, c := range conditions {
+		if c.Type == rc.Type && c.Status == rc.Status {
+			rc.LastTransitionTime = c.LastTransitionTime
+		}
+	}
+
+	return rc
+}
@@ -19,21 +19,28 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	routev1 "github.com/openshift/api/route/v1"
+	persesv1alpha2 "github.com/rhobs/perses-operator/api/v1alpha2"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/rand"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	msoapi "github.com/rhobs/observability-operator/pkg/apis/monitoring/v1alpha1"
 )
@@ -43,21 +50,37 @@ type resourceManager struct {
 	scheme *runtime.Scheme
 	logger logr.Logger
 	thanos ThanosConfiguration
+	caps   clusterCapabilities
 }
 
 type ThanosConfiguration struct {
-	Image string
+	Image           string
+	OAuthProxyImage string
 }
 
 // Options allows for controller options to be set
 type Options struct {
-	Thanos ThanosConfiguration
+	Thanos           ThanosConfiguration
+	OpenShiftEnabled bool
+}
+
+// clusterCapabilities describes the optional APIs available in the cluster.
+type clusterCapabilities struct {
+	openShift  bool
+	gatewayAPI bool
+	perses     bool
 }
 
 const (
 	thanosTLSPrivateKeySecretNameField           = ".spec.webTLSConfig.privateKey.name"
 	thanosTLSCertificateSecretNameField          = ".spec.webTLSConfig.certificate.name"
 	thanosTLSCertificateAuthoritySecretNameField = ".spec.webTLSConfig.certificateAuthority.name"
+
+	thanosGRPCClientTLSPrivateKeySecretNameField           = ".spec.grpcClientTLSConfig.privateKey.name"
+	thanosGRPCClientTLSCertificateSecretNameField          = ".spec.grpcClientTLSConfig.certificate.name"
+	thanosGRPCClientTLSCertificateAuthoritySecretNameField = ".spec.grpcClientTLSConfig.certificateAuthority.name"
+
+	finalizerName = "monitoring.rhobs/thanos-querier-finalizer"
 )
 
 // RBAC for watching monitoring stacks
@@ -68,6 +91,23 @@ const (
 //+kubebuilder:rbac:groups=monitoring.rhobs,resources=thanosqueriers/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=monitoring.rhobs,resources=thanosqueriers/finalizers,verbs=update
 
+// RBAC for watching namespaces
+//+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+
+// RBAC for registering Perses datasources
+//+kubebuilder:rbac:groups=perses.dev,resources=persesdatasources;persesglobaldatasources,verbs=list;watch;create;update;patch;delete
+
+// RBAC for exposing the Thanos Querier UI
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=route.openshift.io,resources=routes,verbs=list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=route.openshift.io,resources=routes/custom-host,verbs=create;update;patch
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=list;watch;create;update;patch;delete
+
+// RBAC for delegating the authentication and authorization of the OAuth proxy
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings,verbs=list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+//+kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
 // RBAC for managing deployments
 //+kubebuilder:rbac:groups=apps,resources=deployments,verbs=list;watch;create;update;patch;delete
 
@@ -82,11 +122,26 @@ const (
 func RegisterWithManager(mgr ctrl.Manager, opts Options) error {
 	logger := ctrl.Log.WithName("thanos-querier")
 
+	gatewayAPI, err := hasKind(mgr, schema.GroupVersionKind{Group: gatewayv1.GroupName, Version: gatewayv1.GroupVersion.Version, Kind: "HTTPRoute"})
+	if err != nil {
+		return err
+	}
+
+	perses, err := hasKind(mgr, persesv1alpha2.GroupVersion.WithKind("PersesDatasource"))
+	if err != nil {
+		return err
+	}
+
 	rm := &resourceManager{
 		Client: mgr.GetClient(),
 		scheme: mgr.GetScheme(),
 		logger: logger,
 		thanos: opts.Thanos,
+		caps: clusterCapabilities{
+			openShift:  opts.OpenShiftEnabled,
+			gatewayAPI: gatewayAPI,
+			perses:     perses,
+		},
 	}
 
 	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &msoapi.ThanosQuerier{}, thanosTLSPrivateKeySecretNameField, func(rawObj client.Object) []string {
@@ -122,13 +177,71 @@ func RegisterWithManager(mgr ctrl.Manager, opts Options) error {
 		return err
 	}
 
-	p := predicate.GenerationChangedPredicate{}
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&msoapi.ThanosQuerier{}).
-		Owns(&appsv1.Deployment{}).WithEventFilter(p).
-		Owns(&corev1.ServiceAccount{}).WithEventFilter(p).
-		Owns(&corev1.Service{}).WithEventFilter(p).
-		Owns(&corev1.ConfigMap{}).WithEventFilter(p).
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &msoapi.ThanosQuerier{}, thanosGRPCClientTLSPrivateKeySecretNameField, func(rawObj client.Object) []string {
+		// Extract the secret name from the spec, if one is provided
+		cr := rawObj.(*msoapi.ThanosQuerier)
+		if cr.Spec.GRPCClientTLSConfig == nil || cr.Spec.GRPCClientTLSConfig.PrivateKey == nil {
+			return nil
+		}
+		return []string{cr.Spec.GRPCClientTLSConfig.PrivateKey.Name}
+	}); err != nil {
+		return err
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &msoapi.ThanosQuerier{}, thanosGRPCClientTLSCertificateSecretNameField, func(rawObj client.Object) []string {
+		// Extract the secret name from the spec, if one is provided
+		cr := rawObj.(*msoapi.ThanosQuerier)
+		if cr.Spec.GRPCClientTLSConfig == nil || cr.Spec.GRPCClientTLSConfig.Certificate == nil {
+			return nil
+		}
+		return []string{cr.Spec.GRPCClientTLSConfig.Certificate.Name}
+	}); err != nil {
+		return err
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &msoapi.ThanosQuerier{}, thanosGRPCClientTLSCertificateAuthoritySecretNameField, func(rawObj client.Object) []string {
+		// Extract the secret name from the spec, if one is provided
+		cr := rawObj.(*msoapi.ThanosQuerier)
+		if cr.Spec.GRPCClientTLSConfig == nil || cr.Spec.GRPCClientTLSConfig.CertificateAuthority == nil {
+			return nil
+		}
+		return []string{cr.Spec.GRPCClientTLSConfig.CertificateAuthority.Name}
+	}); err != nil {
+		return err
+	}
+
+	// The predicates are set per watch (instead of using WithEventFilter)
+	// because Namespace and MonitoringStack label changes don't bump the
+	// generation.
+	generationChanged := builder.WithPredicates(predicate.GenerationChangedPredicate{})
+	ctrlBuilder := ctrl.NewControllerManagedBy(mgr).
+		For(&msoapi.ThanosQuerier{}, generationChanged).
+		Owns(&appsv1.Deployment{}, generationChanged).
+		Owns(&corev1.ServiceAccount{}, generationChanged).
+		Owns(&corev1.Service{}, generationChanged).
+		Owns(&corev1.ConfigMap{}, generationChanged).
+		Owns(&networkingv1.Ingress{}, generationChanged)
+
+	if rm.caps.openShift {
+		ctrlBuilder.Owns(&routev1.Route{}, generationChanged)
+	}
+
+	if rm.caps.gatewayAPI {
+		ctrlBuilder.Owns(&gatewayv1.HTTPRoute{}, generationChanged)
+	}
+
+	if rm.caps.perses {
+		ctrlBuilder.
+			Owns(&persesv1alpha2.PersesDatasource{}, generationChanged).
+			// The global datasource is cluster-scoped and can't be owned.
+			Watches(
+				&persesv1alpha2.PersesGlobalDatasource{},
+				handler.EnqueueRequestsFromMapFunc(rm.findQuerierForGlobalDatasource),
+				generationChanged,
+			)
+	}
+
+	return ctrlBuilder.
 		Watches(
 			&msoapi.MonitoringStack{},
 			handler.EnqueueRequestsFromMapFunc(rm.findQueriersForMonitoringStack),
@@ -137,11 +250,25 @@ func RegisterWithManager(mgr ctrl.Manager, opts Options) error {
 		Watches(
 			&corev1.Secret{},
 			handler.EnqueueRequestsFromMapFunc(rm.findQueriersForTLSSecrets),
-			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+			generationChanged,
+		).
+		Watches(
+			&corev1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(rm.findQueriersForNamespace),
+			builder.WithPredicates(predicate.LabelChangedPredicate{}),
 		).
 		Complete(rm)
 }
 
+// hasKind returns whether the API server serves the given kind.
+func hasKind(mgr ctrl.Manager, gvk schema.GroupVersionKind) (bool, error) {
+	_, err := mgr.GetRESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
 func (rm resourceManager) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := rm.logger.WithValues("querier", req.NamespacedName)
 	logger.Info("Reconciling Thanos Querier")
@@ -155,30 +282,69 @@ func (rm resourceManager) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		return ctrl.Result{}, err
 	}
 
+	// Check if the querier is being deleted
+	if !querier.DeletionTimestamp.IsZero() {
+		logger.V(6).Info("removing cluster scoped resources")
+
+		for _, reconciler := range clusterComponentCleanup(querier, rm.caps) {
+			if err := reconciler.Reconcile(ctx, rm, rm.scheme); err != nil {
+				logger.Error(err, "failed to cleanup thanos querier")
+			}
+		}
+
+		if controllerutil.ContainsFinalizer(querier, finalizerName) {
+			patch := client.MergeFrom(querier.DeepCopy())
+			controllerutil.RemoveFinalizer(querier, finalizerName)
+			if err := rm.Patch(ctx, querier, patch); err != nil {
+				return ctrl.Result{}, client.IgnoreNotFound(err)
+			}
+		}
+
+		return ctrl.Result{}, nil
+	}
+
+	if needsFinalizer(querier) && !controllerutil.ContainsFinalizer(querier, finalizerName) {
+		patch := client.MergeFrom(querier.DeepCopy())
+		controllerutil.AddFinalizer(querier, finalizerName)
+		if err := rm.Patch(ctx, querier, patch); err != nil {
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+	}
+
+	if err := validateQueryConfig(querier.Spec.QueryConfig, rm.thanos.Image); err != nil {
+		logger.Info("invalid query configuration", "err", err)
+		return rm.updateStatus(ctx, querier, err), nil
+	}
+
+	if err := validateUIConfig(querier, rm.caps); err != nil {
+		logger.Info("invalid UI configuration", "err", err)
+		return rm.updateStatus(ctx, querier, err), nil
+	}
+
+	if querier.Spec.PersesDatasource != nil && !rm.caps.perses {
+		err := fmt.Errorf("persesDatasource requires the Perses operator CRDs to be installed")
+		logger.Info("invalid Perses datasource configuration", "err", err)
+		return rm.updateStatus(ctx, querier, err), nil
+	}
+
 	sidecarServices, err := rm.findSidecarServices(ctx, querier)
 	if client.IgnoreNotFound(err) != nil {
 		// we encountered an error other then NotFound, don't try to delete
 		// resources for this querier and reschedule reconcile
+		rm.updateStatus(ctx, querier, err)
 		return ctrl.Result{RequeueAfter: 10 * time.Second}, err
 	}
 
 	tlsHashes := map[string]string{}
-	if querier.Spec.WebTLSConfig != nil {
-		secretSelectors := []msoapi.SecretKeySelector{
-			querier.Spec.WebTLSConfig.CertificateAuthority,
-			querier.Spec.WebTLSConfig.Certificate,
-			querier.Spec.WebTLSConfig.PrivateKey,
-		}
-		for _, secretSelector := range secretSelectors {
-			hash, err := rm.hashOfTLSSecret(secretSelector, querier.Namespace)
-			if err != nil {
-				return ctrl.Result{}, err
-			}
-			tlsHashes[fmt.Sprintf("%s-%s", secretSelector.Name, secretSelector.Key)] = hash
+	for _, secretSelector := range tlsSecretSelectors(querier) {
+		hash, err := rm.hashOfTLSSecret(secretSelector, querier.Namespace)
+		if err != nil {
+			return ctrl.Result{}, err
 		}
+		tlsHashes[fmt.Sprintf("%s-%s", secretSelector.Name, secretSelector.Key)] = hash
 	}
 
-	reconcilers := thanosComponentReconcilers(querier, sidecarServices, rm.thanos, tlsHashes)
+	reconcilers := thanosComponentReconcilers(querier, sidecarServices, rm.thanos, tlsHashes, rm.caps)
 	for _, reconciler := range reconcilers {
 		err := reconciler.Reconcile(ctx, rm, rm.scheme)
 		// handle creation / updation errors that can happen due to a stale cache by
@@ -188,10 +354,32 @@ func (rm resourceManager) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 			return ctrl.Result{RequeueAfter: 2 * time.Second}, nil
 		}
 		if err != nil {
-			return ctrl.Result{}, err
+			return rm.updateStatus(ctx, querier, err), err
+		}
+	}
+
+	// The cluster-scoped resources have been removed by their reconcilers:
+	// the finalizer isn't needed anymore.
+	if !needsFinalizer(querier) && controllerutil.ContainsFinalizer(querier, finalizerName) {
+		patch := client.MergeFrom(querier.DeepCopy())
+		controllerutil.RemoveFinalizer(querier, finalizerName)
+		if err := rm.Patch(ctx, querier, patch); err != nil {
+			return ctrl.Result{}, client.IgnoreNotFound(err)
 		}
 	}
-	return ctrl.Result{}, nil
+
+	return rm.updateStatus(ctx, querier, nil), nil
+}
+
+func (rm resourceManager) updateStatus(ctx context.Context, querier *msoapi.ThanosQuerier, recError error) ctrl.Result {
+	logger := rm.logger.WithValues("querier", client.ObjectKeyFromObject(querier))
+
+	querier.Status.Conditions = updateConditions(querier, recError)
+	if err := rm.Status().Update(ctx, querier); err != nil {
+		logger.Info("Failed to update status", "err", err)
+		return ctrl.Result{RequeueAfter: 2 * time.Second}
+	}
+	return ctrl.Result{}
 }
 
 // Given a ThanosQuerier object, find the matching MonitoringStacks, extract the
@@ -214,9 +402,20 @@ func (rm resourceManager) findSidecarServices(ctx context.Context, tQuerier *mso
 
 	// Keep only the MonitoringStacks matching the ThanosQuerier's namespace selector.
 	for _, ms := range msList.Items {
-		if tQuerier.MatchesNamespace(ms.Namespace) {
+		var nsLabels map[string]string
+		if tQuerier.Spec.NamespaceSelector.LabelSelector != nil {
+			ns := &corev1.Namespace{}
+			if err := rm.Get(ctx, types.NamespacedName{Name: ms.Namespace}, ns); err != nil {
+				// Returning the endpoints found so far would silently remove
+				// the stacks of the namespace from the querier.
+				return nil, fmt.Errorf("Couldn't get namespace %s: %s", ms.Namespace, err)
+			}
+			nsLabels = ns.Labels
+		}
+
+		if tQuerier.MatchesNamespaceWithLabels(ms.Namespace, nsLabels) {
 			serviceName := ms.Name + "-thanos-sidecar"
-			sidecarUrls = append(sidecarUrls, getEndpointUrl(serviceName, ms.Namespace))
+			sidecarUrls = append(sidecarUrls, getEndpointUrl(serviceName, ms.Namespace, tQuerier.Spec.GRPCClientTLSConfig))
 		}
 	}
 	logger.Info("Found matching MonitoringStacks", "length", len(sidecarUrls))
@@ -238,9 +437,44 @@ func (rm resourceManager) hashOfTLSSecret(selector msoapi.SecretKeySelector, nam
 	return rand.SafeEncodeString(fmt.Sprint(hash)), nil
 }
 
+// tlsSecretSelectors returns the secret keys holding the TLS assets mounted
+// into the Thanos Querier pod.
+func tlsSecretSelectors(querier *msoapi.ThanosQuerier) []msoapi.SecretKeySelector {
+	var selectors []msoapi.SecretKeySelector
+	if querier.Spec.WebTLSConfig != nil {
+		selectors = append(selectors,
+			querier.Spec.WebTLSConfig.CertificateAuthority,
+			querier.Spec.WebTLSConfig.Certificate,
+			querier.Spec.WebTLSConfig.PrivateKey,
+		)
+	}
+
+	if grpcTLSConfig := querier.Spec.GRPCClientTLSConfig; grpcTLSConfig != nil {
+		for _, selector := range []*msoapi.SecretKeySelector{
+			grpcTLSConfig.CertificateAuthority,
+			grpcTLSConfig.Certificate,
+			grpcTLSConfig.PrivateKey,
+		} {
+			if selector != nil {
+				selectors = append(selectors, *selector)
+			}
+		}
+	}
+
+	return selectors
+}
+
 // Given a Service object, return a url to use as value for --store/--endpoint.
-func getEndpointUrl(serviceName string, namespace string) string {
-	return fmt.Sprintf("dnssrv+_grpc._tcp.%s.%s.svc.cluster.local", serviceName, namespace)
+//
+// When TLS is enabled without an explicit server name, the SRV records aren't
+// resolved to IP addresses so that the querier verifies the per-pod hostnames
+// of the headless sidecar service against the server certificates.
+func getEndpointUrl(serviceName string, namespace string, tlsConfig *msoapi.GRPCClientTLSConfig) string {
+	scheme := "dnssrv+"
+	if tlsConfig != nil && tlsConfig.ServerName == "" {
+		scheme = "dnssrvnoa+"
+	}
+	return fmt.Sprintf("%s_grpc._tcp.%s.%s.svc.cluster.local", scheme, serviceName, namespace)
 }
 
 // Find all ThanosQueriers, whose Selector fits the given MonitoringStack and
@@ -274,6 +508,44 @@ func (rm resourceManager) findQueriersForMonitoringStack(ctx context.Context, ms
 	return requests
 }
 
+// Find all ThanosQueriers selecting namespaces by labels and return a list of
+// reconcile requests, one for each ThanosQuerier. The queriers are always
+// re-enqueued since the namespace may have been selected or unselected by the
+// label change.
+func (rm resourceManager) findQueriersForNamespace(ctx context.Context, ns client.Object) []reconcile.Request {
+	logger := rm.logger.WithValues("Namespace", ns.GetName())
+	queriers := &msoapi.ThanosQuerierList{}
+	if err := rm.List(ctx, queriers); err != nil {
+		logger.Error(err, "Failed to list Thanosqueriers")
+		return []reconcile.Request{}
+	}
+
+	var requests []reconcile.Request
+	for _, item := range queriers.Items {
+		if item.Spec.NamespaceSelector.LabelSelector == nil {
+			continue
+		}
+		logger.V(6).Info("Namespace labels changed, scheduling querier sync", "querier", item.Namespace+"/"+item.Name)
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      item.GetName(),
+				Namespace: item.GetNamespace(),
+			},
+		})
+	}
+	return requests
+}
+
+// findQuerierForGlobalDatasource returns the ThanosQuerier which created the
+// given PersesGlobalDatasource.
+func (rm resourceManager) findQuerierForGlobalDatasource(_ context.Context, ds client.Object) []reconcile.Request {
+	owner, ok := querierReference(ds)
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: owner}}
+}
+
 // Find all ThanosQueriers, whose TLS secrets fit the given Secret and
 // return a list of reconcile requests, one for each ThanosQuerier.
 func (rm resourceManager) findQueriersForTLSSecrets(ctx context.Context, src client.Object) []reconcile.Request {
@@ -283,6 +555,9 @@ func (rm resourceManager) findQueriersForTLSSecrets(ctx context.Context, src cli
 		thanosTLSCertificateAuthoritySecretNameField,
 		thanosTLSCertificateSecretNameField,
 		thanosTLSPrivateKeySecretNameField,
+		thanosGRPCClientTLSCertificateAuthoritySecretNameField,
+		thanosGRPCClientTLSCertificateSecretNameField,
+		thanosGRPCClientTLSPrivateKeySecretNameField,
 	}
 
 	for _, field := range thanosWatchFields {
@@ -0,0 +1,53 @@
+package thanos_querier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	msoapi "github.com/rhobs/observability-operator/pkg/apis/monitoring/v1alpha1"
+)
+
+func TestFindSidecarServicesWithLabelSelector(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NilError(t, corev1.AddToScheme(scheme))
+	assert.NilError(t, msoapi.AddToScheme(scheme))
+
+	querier := &msoapi.ThanosQuerier{
+		ObjectMeta: metav1.ObjectMeta{Name: "querier", Namespace: "ns"},
+		Spec: msoapi.ThanosQuerierSpec{
+			NamespaceSelector: msoapi.NamespaceSelector{
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "true"}},
+			},
+		},
+	}
+	objects := []runtime.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"tenant": "true"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}},
+		&msoapi.MonitoringStack{ObjectMeta: metav1.ObjectMeta{Name: "ms", Namespace: "team-a"}},
+		&msoapi.MonitoringStack{ObjectMeta: metav1.ObjectMeta{Name: "ms", Namespace: "team-b"}},
+	}
+
+	rm := resourceManager{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objects...).Build(),
+		logger: logr.Discard(),
+	}
+	urls, err := rm.findSidecarServices(context.Background(), querier)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, urls, []string{"dnssrv+_grpc._tcp.ms-thanos-sidecar.team-a.svc.cluster.local"})
+
+	// The endpoints aren't returned when the labels of a namespace can't be
+	// read, even if the namespace doesn't exist.
+	rm.Client = fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(append(objects,
+		&msoapi.MonitoringStack{ObjectMeta: metav1.ObjectMeta{Name: "ms", Namespace: "team-c"}},
+	)...).Build()
+	urls, err = rm.findSidecarServices(context.Background(), querier)
+	assert.ErrorContains(t, err, "Couldn't get namespace team-c")
+	assert.Assert(t, urls == nil)
+}
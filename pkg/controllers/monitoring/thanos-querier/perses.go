@@ -0,0 +1,150 @@
+package thanos_querier
+
+import (
+	"fmt"
+
+	specCommon "github.com/perses/spec/go/common"
+	dsSpec "github.com/perses/spec/go/datasource"
+	persesv1alpha2 "github.com/rhobs/perses-operator/api/v1alpha2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	msoapi "github.com/rhobs/observability-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/rhobs/observability-operator/pkg/reconciler"
+)
+
+// persesComponentReconcilers returns the reconcilers of the Perses
+// datasources registering the Thanos Querier. Only the datasource matching
+// the configured scope is kept.
+func persesComponentReconcilers(name string, thanos *msoapi.ThanosQuerier, caps clusterCapabilities) []reconciler.Reconciler {
+	if !caps.perses {
+		return nil
+	}
+
+	scope := persesDatasourceScope(thanos)
+	return []reconciler.Reconciler{
+		reconciler.NewOptionalUpdater(newPersesDatasource(name, thanos), thanos, scope == msoapi.NamespacePersesDatasource),
+		// The global datasource is cluster-scoped and can't be garbage
+		// collected: it is removed by the finalizer of the ThanosQuerier.
+		reconciler.NewOptionalUpdater(newPersesGlobalDatasource(thanos), thanos, scope == msoapi.GlobalPersesDatasource),
+	}
+}
+
+// persesComponentCleanup returns the reconcilers deleting the resources which
+// aren't garbage collected when the ThanosQuerier is deleted.
+func persesComponentCleanup(thanos *msoapi.ThanosQuerier, caps clusterCapabilities) []reconciler.Reconciler {
+	if !caps.perses {
+		return nil
+	}
+
+	return []reconciler.Reconciler{
+		reconciler.NewDeleter(newPersesGlobalDatasource(thanos)),
+	}
+}
+
+// persesDatasourceScope returns the scope of the Perses datasource or an
+// empty string if no datasource should be created.
+func persesDatasourceScope(thanos *msoapi.ThanosQuerier) msoapi.PersesDatasourceScope {
+	ds := thanos.Spec.PersesDatasource
+	if ds == nil {
+		return ""
+	}
+	if ds.Scope == "" {
+		return msoapi.NamespacePersesDatasource
+	}
+	return ds.Scope
+}
+
+func persesGlobalDatasourceName(thanos *msoapi.ThanosQuerier) string {
+	return clusterScopedName("thanos-querier", thanos)
+}
+
+func newPersesDatasource(name string, thanos *msoapi.ThanosQuerier) *persesv1alpha2.PersesDatasource {
+	return &persesv1alpha2.PersesDatasource{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: persesv1alpha2.GroupVersion.String(),
+			Kind:       "PersesDatasource",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: thanos.Namespace,
+			Labels:    componentLabels(name),
+		},
+		Spec: newPersesDatasourceSpec(name, thanos),
+	}
+}
+
+func newPersesGlobalDatasource(thanos *msoapi.ThanosQuerier) *persesv1alpha2.PersesGlobalDatasource {
+	name := "thanos-querier-" + thanos.Name
+	return &persesv1alpha2.PersesGlobalDatasource{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: persesv1alpha2.GroupVersion.String(),
+			Kind:       "PersesGlobalDatasource",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        persesGlobalDatasourceName(thanos),
+			Labels:      componentLabels(name),
+			Annotations: querierAnnotations(thanos),
+		},
+		Spec: newPersesDatasourceSpec(name, thanos),
+	}
+}
+
+func newPersesDatasourceSpec(name string, thanos *msoapi.ThanosQuerier) persesv1alpha2.DatasourceSpec {
+	ds := thanos.Spec.PersesDatasource
+	if ds == nil {
+		ds = &msoapi.ThanosQuerierPersesDatasource{}
+	}
+
+	displayName := ds.DisplayName
+	if displayName == "" {
+		displayName = fmt.Sprintf("Thanos Querier %s/%s", thanos.Namespace, thanos.Name)
+	}
+
+	scheme := "http"
+	if thanos.Spec.WebTLSConfig != nil {
+		scheme = "https"
+	}
+
+	spec := persesv1alpha2.DatasourceSpec{
+		Config: persesv1alpha2.Datasource{
+			Spec: dsSpec.Spec{
+				Display: &specCommon.Display{
+					Name: displayName,
+				},
+				Default: ds.Default,
+				Plugin: specCommon.Plugin{
+					Kind: "PrometheusDatasource",
+					Spec: map[string]interface{}{
+						"proxy": map[string]interface{}{
+							"kind": "HTTPProxy",
+							"spec": map[string]interface{}{
+								"url": fmt.Sprintf("%s://%s.%s.svc.cluster.local:%d", scheme, name, thanos.Namespace, webPort),
+							},
+						},
+					},
+				},
+			},
+		},
+		InstanceSelector: ds.InstanceSelector,
+	}
+
+	if thanos.Spec.WebTLSConfig != nil {
+		ca := thanos.Spec.WebTLSConfig.CertificateAuthority
+		spec.Client = &persesv1alpha2.Client{
+			TLS: &persesv1alpha2.TLS{
+				Enable: ptr.To(true),
+				CaCert: &persesv1alpha2.Certificate{
+					SecretSource: persesv1alpha2.SecretSource{
+						Type:      persesv1alpha2.SecretSourceTypeSecret,
+						Name:      ptr.To(ca.Name),
+						Namespace: ptr.To(thanos.Namespace),
+					},
+					CertPath: ca.Key,
+				},
+			},
+		}
+	}
+
+	return spec
+}
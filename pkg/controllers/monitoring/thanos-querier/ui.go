@@ -0,0 +1,454 @@
+package thanos_querier
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	msoapi "github.com/rhobs/observability-operator/pkg/apis/monitoring/v1alpha1"
+	"github.com/rhobs/observability-operator/pkg/reconciler"
+)
+
+const (
+	webPort        = 10902
+	oauthProxyPort = 9091
+
+	oauthProxyPortName               = "oauth-proxy"
+	oauthProxyTLSMountPath           = "/etc/tls/private"
+	oauthProxyCookieMountPath        = "/etc/proxy/secrets"
+	oauthProxyUpstreamCAPath         = "/etc/thanos/tls-assets/web-ca-secret"
+	oauthProxyCookieSecretKey        = "session_secret"
+	oauthRedirectReferenceAnnotation = "serviceaccounts.openshift.io/oauth-redirectreference.thanos-querier"
+	oauthRedirectURIAnnotation       = "serviceaccounts.openshift.io/oauth-redirecturi.thanos-querier"
+
+	// nginxBackendProtocolAnnotation configures the ingress-nginx controller
+	// to connect to the backend over HTTPS.
+	nginxBackendProtocolAnnotation = "nginx.ingress.kubernetes.io/backend-protocol"
+	// routeTerminationAnnotation configures the TLS termination of the
+	// Route which OpenShift creates for an Ingress.
+	routeTerminationAnnotation = "route.openshift.io/termination"
+
+	// authDelegatorClusterRole allows the OAuth proxy to review the bearer
+	// tokens and the access of the clients.
+	authDelegatorClusterRole = "system:auth-delegator"
+)
+
+// uiComponentReconcilers returns the reconcilers of the resources exposing
+// the Thanos Querier UI. Resources whose API isn't available in the cluster
+// are skipped since they can't exist.
+func uiComponentReconcilers(name string, thanos *msoapi.ThanosQuerier, caps clusterCapabilities) []reconciler.Reconciler {
+	ui := thanos.Spec.UI
+	exposedAs := func(t msoapi.UIExposureType) bool {
+		return ui != nil && ui.Type == t
+	}
+	oauthProxy := ui != nil && ui.OAuthProxy
+
+	reconcilers := []reconciler.Reconciler{
+		reconciler.NewOptionalUpdater(newIngress(name, thanos), thanos, exposedAs(msoapi.IngressExposure)),
+	}
+
+	if oauthProxy {
+		reconcilers = append(reconcilers,
+			reconciler.NewCreateUpdateReconciler(newOAuthProxyCookieSecret(name, thanos.Namespace), thanos),
+			reconciler.NewUpdater(newOAuthProxyClusterRoleBinding(name, thanos), thanos),
+		)
+	} else {
+		reconcilers = append(reconcilers,
+			reconciler.NewDeleter(newOAuthProxyCookieSecret(name, thanos.Namespace)),
+			reconciler.NewDeleter(newOAuthProxyClusterRoleBinding(name, thanos)),
+		)
+	}
+
+	if caps.openShift {
+		reconcilers = append(reconcilers, reconciler.NewOptionalUpdater(newRoute(name, thanos), thanos, exposedAs(msoapi.RouteExposure)))
+	}
+
+	if caps.gatewayAPI {
+		reconcilers = append(reconcilers, reconciler.NewOptionalUpdater(newHTTPRoute(name, thanos), thanos, exposedAs(msoapi.HTTPRouteExposure)))
+	}
+
+	return reconcilers
+}
+
+// uiBackendPort returns the name and number of the service port to which the
+// external traffic is routed.
+func uiBackendPort(thanos *msoapi.ThanosQuerier) (string, int32) {
+	if thanos.Spec.UI != nil && thanos.Spec.UI.OAuthProxy {
+		return oauthProxyPortName, oauthProxyPort
+	}
+	return "http", webPort
+}
+
+// uiBackendHTTPS returns true when the port to which the external traffic is
+// routed only accepts HTTPS connections.
+func uiBackendHTTPS(thanos *msoapi.ThanosQuerier) bool {
+	return (thanos.Spec.UI != nil && thanos.Spec.UI.OAuthProxy) || thanos.Spec.WebTLSConfig != nil
+}
+
+func newRoute(name string, thanos *msoapi.ThanosQuerier) *routev1.Route {
+	portName, _ := uiBackendPort(thanos)
+
+	route := &routev1.Route{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: routev1.GroupVersion.String(),
+			Kind:       "Route",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: thanos.Namespace,
+			Labels:    componentLabels(name),
+		},
+		Spec: routev1.RouteSpec{
+			To: routev1.RouteTargetReference{
+				Kind: "Service",
+				Name: name,
+			},
+			Port: &routev1.RoutePort{
+				TargetPort: intstr.FromString(portName),
+			},
+			TLS: &routev1.TLSConfig{
+				Termination:                   routev1.TLSTerminationEdge,
+				InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+			},
+		},
+	}
+
+	if thanos.Spec.UI != nil {
+		route.Spec.Host = thanos.Spec.UI.Host
+	}
+
+	switch {
+	case thanos.Spec.UI != nil && thanos.Spec.UI.OAuthProxy:
+		// The OAuth proxy certificate is issued by the service CA which is
+		// trusted by the OpenShift router.
+		route.Spec.TLS.Termination = routev1.TLSTerminationReencrypt
+	case thanos.Spec.WebTLSConfig != nil:
+		route.Spec.TLS.Termination = routev1.TLSTerminationPassthrough
+	}
+
+	return route
+}
+
+func newIngress(name string, thanos *msoapi.ThanosQuerier) *networkingv1.Ingress {
+	portName, _ := uiBackendPort(thanos)
+
+	ingress := &networkingv1.Ingress{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: networkingv1.SchemeGroupVersion.String(),
+			Kind:       "Ingress",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: thanos.Namespace,
+			Labels:    componentLabels(name),
+		},
+	}
+
+	ui := thanos.Spec.UI
+	if ui == nil {
+		return ingress
+	}
+
+	ingress.Spec = networkingv1.IngressSpec{
+		Rules: []networkingv1.IngressRule{
+			{
+				Host: ui.Host,
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{
+							{
+								Path:     "/",
+								PathType: ptr.To(networkingv1.PathTypePrefix),
+								Backend: networkingv1.IngressBackend{
+									Service: &networkingv1.IngressServiceBackend{
+										Name: name,
+										Port: networkingv1.ServiceBackendPort{Name: portName},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// There is no standard way to configure the protocol of an Ingress
+	// backend: the annotations of ingress-nginx and of the OpenShift router,
+	// consistent with newRoute, are set. Other controllers are configured
+	// with ingress.annotations.
+	if uiBackendHTTPS(thanos) {
+		termination := string(routev1.TLSTerminationPassthrough)
+		if ui.OAuthProxy {
+			termination = string(routev1.TLSTerminationReencrypt)
+		}
+		ingress.Annotations = map[string]string{
+			nginxBackendProtocolAnnotation: "HTTPS",
+			routeTerminationAnnotation:     termination,
+		}
+	}
+
+	if ui.Ingress != nil {
+		if len(ui.Ingress.Annotations) > 0 && ingress.Annotations == nil {
+			ingress.Annotations = map[string]string{}
+		}
+		maps.Copy(ingress.Annotations, ui.Ingress.Annotations)
+		ingress.Spec.IngressClassName = ui.Ingress.IngressClassName
+		if ui.Ingress.TLSSecretName != "" {
+			ingress.Spec.TLS = []networkingv1.IngressTLS{
+				{
+					Hosts:      []string{ui.Host},
+					SecretName: ui.Ingress.TLSSecretName,
+				},
+			}
+		}
+	}
+
+	return ingress
+}
+
+func newHTTPRoute(name string, thanos *msoapi.ThanosQuerier) *gatewayv1.HTTPRoute {
+	_, port := uiBackendPort(thanos)
+
+	httpRoute := &gatewayv1.HTTPRoute{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: gatewayv1.GroupVersion.String(),
+			Kind:       "HTTPRoute",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: thanos.Namespace,
+			Labels:    componentLabels(name),
+		},
+	}
+
+	ui := thanos.Spec.UI
+	if ui == nil {
+		return httpRoute
+	}
+
+	if ui.HTTPRoute != nil {
+		for _, ref := range ui.HTTPRoute.ParentRefs {
+			parentRef := gatewayv1.ParentReference{
+				Name: gatewayv1.ObjectName(ref.Name),
+			}
+			if ref.Namespace != "" {
+				parentRef.Namespace = ptr.To(gatewayv1.Namespace(ref.Namespace))
+			}
+			if ref.SectionName != "" {
+				parentRef.SectionName = ptr.To(gatewayv1.SectionName(ref.SectionName))
+			}
+			httpRoute.Spec.ParentRefs = append(httpRoute.Spec.ParentRefs, parentRef)
+		}
+	}
+
+	httpRoute.Spec.Hostnames = []gatewayv1.Hostname{gatewayv1.Hostname(ui.Host)}
+	httpRoute.Spec.Rules = []gatewayv1.HTTPRouteRule{
+		{
+			BackendRefs: []gatewayv1.HTTPBackendRef{
+				{
+					BackendRef: gatewayv1.BackendRef{
+						BackendObjectReference: gatewayv1.BackendObjectReference{
+							Name: gatewayv1.ObjectName(name),
+							Port: ptr.To(gatewayv1.PortNumber(port)),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	return httpRoute
+}
+
+func newOAuthProxyCookieSecret(name string, namespace string) *corev1.Secret {
+	cookie := make([]byte, 32)
+	// crypto/rand.Read never returns an error.
+	_, _ = rand.Read(cookie)
+
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      oauthProxyCookieSecretName(name),
+			Namespace: namespace,
+		},
+		StringData: map[string]string{
+			oauthProxyCookieSecretKey: base64.StdEncoding.EncodeToString(cookie),
+		},
+	}
+}
+
+// newOAuthProxyClusterRoleBinding returns the binding which allows the OAuth
+// proxy to authorize the requests authenticated with a bearer token.
+func newOAuthProxyClusterRoleBinding(name string, thanos *msoapi.ThanosQuerier) *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: rbacv1.SchemeGroupVersion.String(),
+			Kind:       "ClusterRoleBinding",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        clusterScopedName("thanos-querier-auth-delegator", thanos),
+			Labels:      componentLabels(name),
+			Annotations: querierAnnotations(thanos),
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     authDelegatorClusterRole,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      name,
+				Namespace: thanos.Namespace,
+			},
+		},
+	}
+}
+
+func oauthProxyCookieSecretName(name string) string {
+	return name + "-oauth-proxy-cookie"
+}
+
+func oauthProxyTLSSecretName(name string) string {
+	return name + "-oauth-proxy-tls"
+}
+
+// oauthRedirectAnnotations returns the annotations which register the
+// ServiceAccount of the OAuth proxy as an OAuth client.
+func oauthRedirectAnnotations(name string, ui *msoapi.ThanosQuerierUIConfig) map[string]string {
+	if ui == nil || !ui.OAuthProxy {
+		return nil
+	}
+
+	if ui.Type != msoapi.RouteExposure {
+		return map[string]string{
+			oauthRedirectURIAnnotation: fmt.Sprintf("https://%s", ui.Host),
+		}
+	}
+
+	ref, _ := json.Marshal(map[string]any{
+		"kind":       "OAuthRedirectReference",
+		"apiVersion": "v1",
+		"reference": map[string]string{
+			"kind": "Route",
+			"name": name,
+		},
+	})
+	return map[string]string{
+		oauthRedirectReferenceAnnotation: string(ref),
+	}
+}
+
+// addOAuthProxy adds the OAuth proxy sidecar container and its volumes to the
+// Thanos Querier pod.
+//
+// Browser sessions (-openshift-sar) and bearer tokens (-openshift-delegate-urls)
+// are both authorized with the same check: the client must be allowed to get
+// the Thanos Querier service.
+func addOAuthProxy(podSpec *corev1.PodSpec, name string, thanos *msoapi.ThanosQuerier, image string) {
+	sar, _ := json.Marshal(map[string]string{
+		"namespace":    thanos.Namespace,
+		"resource":     "services",
+		"resourceName": name,
+		"verb":         "get",
+	})
+	delegateURLs, _ := json.Marshal(map[string]map[string]string{
+		"/": {
+			"namespace": thanos.Namespace,
+			"resource":  "services",
+			"name":      name,
+			"verb":      "get",
+		},
+	})
+
+	upstream := fmt.Sprintf("http://localhost:%d", webPort)
+	args := []string{
+		"-provider=openshift",
+		fmt.Sprintf("-https-address=:%d", oauthProxyPort),
+		"-http-address=",
+		"-email-domain=*",
+		fmt.Sprintf("-openshift-service-account=%s", name),
+		fmt.Sprintf("-openshift-sar=%s", sar),
+		fmt.Sprintf("-openshift-delegate-urls=%s", delegateURLs),
+		fmt.Sprintf("-tls-cert=%s/%s", oauthProxyTLSMountPath, corev1.TLSCertKey),
+		fmt.Sprintf("-tls-key=%s/%s", oauthProxyTLSMountPath, corev1.TLSPrivateKeyKey),
+		fmt.Sprintf("-cookie-secret-file=%s/%s", oauthProxyCookieMountPath, oauthProxyCookieSecretKey),
+	}
+
+	volumes := []corev1.Volume{
+		{
+			Name: "oauth-proxy-tls",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: oauthProxyTLSSecretName(name)},
+			},
+		},
+		{
+			Name: "oauth-proxy-cookie",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: oauthProxyCookieSecretName(name)},
+			},
+		},
+	}
+	volumeMounts := []corev1.VolumeMount{
+		{Name: "oauth-proxy-tls", MountPath: oauthProxyTLSMountPath, ReadOnly: true},
+		{Name: "oauth-proxy-cookie", MountPath: oauthProxyCookieMountPath, ReadOnly: true},
+	}
+
+	if thanos.Spec.WebTLSConfig != nil {
+		ca := thanos.Spec.WebTLSConfig.CertificateAuthority
+		upstream = fmt.Sprintf("https://localhost:%d", webPort)
+		args = append(args, fmt.Sprintf("-upstream-ca=%s/%s", oauthProxyUpstreamCAPath, ca.Key))
+		volumes = append(volumes, corev1.Volume{
+			Name: "thanos-web-tls-ca",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: ca.Name},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name: "thanos-web-tls-ca", MountPath: oauthProxyUpstreamCAPath, ReadOnly: true,
+		})
+	}
+	args = append(args, fmt.Sprintf("-upstream=%s", upstream))
+
+	podSpec.Volumes = append(podSpec.Volumes, volumes...)
+	podSpec.Containers = append(podSpec.Containers, corev1.Container{
+		Name:  "oauth-proxy",
+		Image: image,
+		Args:  args,
+		Ports: []corev1.ContainerPort{
+			{
+				ContainerPort: oauthProxyPort,
+				Name:          oauthProxyPortName,
+			},
+		},
+		VolumeMounts:             volumeMounts,
+		TerminationMessagePolicy: "FallbackToLogsOnError",
+		SecurityContext: &corev1.SecurityContext{
+			AllowPrivilegeEscalation: ptr.To(false),
+			Capabilities: &corev1.Capabilities{
+				Drop: []corev1.Capability{
+					"ALL",
+				},
+			},
+			RunAsNonRoot: ptr.To(true),
+			SeccompProfile: &corev1.SeccompProfile{
+				Type: corev1.SeccompProfileTypeRuntimeDefault,
+			},
+		},
+	})
+}
@@ -0,0 +1,92 @@
+package thanos_querier
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blang/semver/v4"
+
+	msoapi "github.com/rhobs/observability-operator/pkg/apis/monitoring/v1alpha1"
+)
+
+var (
+	promQLEngineMinVersion      = semver.MustParse("0.31.0")
+	deduplicationFuncMinVersion = semver.MustParse("0.35.0")
+)
+
+// imageVersion returns the semantic version of the image's tag. The tag of
+// an image pinned by digest (e.g. "thanos:v0.35.0@sha256:...") is used as
+// well. It returns nil if the image has no tag, e.g. when it is only
+// referenced by digest, or if the tag isn't a version: a digest doesn't carry
+// any version information.
+func imageVersion(image string) *semver.Version {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+
+	i := strings.LastIndex(image, ":")
+	if i < 0 || strings.Contains(image[i:], "/") {
+		return nil
+	}
+
+	v, err := semver.ParseTolerant(image[i+1:])
+	if err != nil {
+		return nil
+	}
+
+	return &v
+}
+
+// validateQueryConfig checks that the query options are supported by the
+// Thanos image. The validation is skipped when the version can't be inferred
+// from the image reference.
+func validateQueryConfig(queryConfig *msoapi.ThanosQueryConfig, image string) error {
+	if queryConfig == nil {
+		return nil
+	}
+
+	version := imageVersion(image)
+	if version == nil {
+		return nil
+	}
+
+	if queryConfig.PromQLEngine != "" && version.LT(promQLEngineMinVersion) {
+		return fmt.Errorf("promqlEngine requires Thanos v%s or later, got %s", promQLEngineMinVersion, image)
+	}
+
+	if queryConfig.DeduplicationFunc != "" && version.LT(deduplicationFuncMinVersion) {
+		return fmt.Errorf("deduplicationFunc requires Thanos v%s or later, got %s", deduplicationFuncMinVersion, image)
+	}
+
+	return nil
+}
+
+// validateUIConfig checks that the APIs required to expose the UI are
+// available in the cluster and that the exposure can reach the Thanos
+// Querier.
+func validateUIConfig(thanos *msoapi.ThanosQuerier, caps clusterCapabilities) error {
+	ui := thanos.Spec.UI
+	if ui == nil {
+		return nil
+	}
+
+	if ui.Type == msoapi.RouteExposure && !caps.openShift {
+		return fmt.Errorf("ui type %s is only supported on OpenShift", ui.Type)
+	}
+
+	if ui.Type == msoapi.HTTPRouteExposure && !caps.gatewayAPI {
+		return fmt.Errorf("ui type %s requires the Gateway API CRDs to be installed", ui.Type)
+	}
+
+	if ui.OAuthProxy && !caps.openShift {
+		return fmt.Errorf("oauthProxy is only supported on OpenShift")
+	}
+
+	// A BackendTLSPolicy can only trust a CA from a ConfigMap while the CAs
+	// of the OAuth proxy and of webTLSConfig aren't available as such.
+	if ui.Type == msoapi.HTTPRouteExposure && uiBackendHTTPS(thanos) {
+		return fmt.Errorf("ui type %s doesn't support the HTTPS backend enabled by oauthProxy or webTLSConfig", ui.Type)
+	}
+
+	return nil
+}
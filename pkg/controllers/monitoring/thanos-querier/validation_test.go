@@ -0,0 +1,146 @@
+package thanos_querier
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	msoapi "github.com/rhobs/observability-operator/pkg/apis/monitoring/v1alpha1"
+)
+
+func TestValidateQueryConfig(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		image       string
+		queryConfig *msoapi.ThanosQueryConfig
+		wantErr     bool
+	}{
+		{
+			name:  "no query config",
+			image: "quay.io/thanos/thanos:v0.30.0",
+		},
+		{
+			name:        "supported engine",
+			image:       "quay.io/thanos/thanos:v0.31.0",
+			queryConfig: &msoapi.ThanosQueryConfig{PromQLEngine: msoapi.ThanosPromQLEngine},
+		},
+		{
+			name:        "unsupported engine",
+			image:       "quay.io/thanos/thanos:v0.30.2",
+			queryConfig: &msoapi.ThanosQueryConfig{PromQLEngine: msoapi.ThanosPromQLEngine},
+			wantErr:     true,
+		},
+		{
+			name:        "unsupported deduplication func",
+			image:       "quay.io/thanos/thanos:v0.34.1",
+			queryConfig: &msoapi.ThanosQueryConfig{DeduplicationFunc: msoapi.ChainDeduplication},
+			wantErr:     true,
+		},
+		{
+			name:        "image with registry port",
+			image:       "registry.local:5000/thanos/thanos:v0.34.1",
+			queryConfig: &msoapi.ThanosQueryConfig{DeduplicationFunc: msoapi.ChainDeduplication},
+			wantErr:     true,
+		},
+		{
+			name:        "image without tag",
+			image:       "registry.local:5000/thanos/thanos",
+			queryConfig: &msoapi.ThanosQueryConfig{DeduplicationFunc: msoapi.ChainDeduplication},
+		},
+		{
+			name:        "image by digest",
+			image:       "quay.io/thanos/thanos@sha256:0123456789abcdef",
+			queryConfig: &msoapi.ThanosQueryConfig{DeduplicationFunc: msoapi.ChainDeduplication},
+		},
+		{
+			name:        "image by tag and digest",
+			image:       "quay.io/thanos/thanos:v0.34.1@sha256:0123456789abcdef",
+			queryConfig: &msoapi.ThanosQueryConfig{DeduplicationFunc: msoapi.ChainDeduplication},
+			wantErr:     true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateQueryConfig(tc.queryConfig, tc.image)
+			if tc.wantErr {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+		})
+	}
+}
+
+func TestValidateUIConfig(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		ui      *msoapi.ThanosQuerierUIConfig
+		webTLS  bool
+		caps    clusterCapabilities
+		wantErr bool
+	}{
+		{
+			name: "no ui",
+		},
+		{
+			name: "ingress",
+			ui:   &msoapi.ThanosQuerierUIConfig{Type: msoapi.IngressExposure, Host: "thanos.example.com"},
+		},
+		{
+			name:    "route without OpenShift",
+			ui:      &msoapi.ThanosQuerierUIConfig{Type: msoapi.RouteExposure},
+			wantErr: true,
+		},
+		{
+			name: "route on OpenShift",
+			ui:   &msoapi.ThanosQuerierUIConfig{Type: msoapi.RouteExposure, OAuthProxy: true},
+			caps: clusterCapabilities{openShift: true},
+		},
+		{
+			name:    "oauth proxy without OpenShift",
+			ui:      &msoapi.ThanosQuerierUIConfig{Type: msoapi.IngressExposure, Host: "thanos.example.com", OAuthProxy: true},
+			wantErr: true,
+		},
+		{
+			name:    "httproute without Gateway API",
+			ui:      &msoapi.ThanosQuerierUIConfig{Type: msoapi.HTTPRouteExposure, Host: "thanos.example.com"},
+			caps:    clusterCapabilities{openShift: true},
+			wantErr: true,
+		},
+		{
+			name: "httproute with Gateway API",
+			ui:   &msoapi.ThanosQuerierUIConfig{Type: msoapi.HTTPRouteExposure, Host: "thanos.example.com"},
+			caps: clusterCapabilities{gatewayAPI: true},
+		},
+		{
+			name:    "httproute with web TLS",
+			ui:      &msoapi.ThanosQuerierUIConfig{Type: msoapi.HTTPRouteExposure, Host: "thanos.example.com"},
+			webTLS:  true,
+			caps:    clusterCapabilities{gatewayAPI: true},
+			wantErr: true,
+		},
+		{
+			name:    "httproute with oauth proxy",
+			ui:      &msoapi.ThanosQuerierUIConfig{Type: msoapi.HTTPRouteExposure, Host: "thanos.example.com", OAuthProxy: true},
+			caps:    clusterCapabilities{openShift: true, gatewayAPI: true},
+			wantErr: true,
+		},
+		{
+			name:   "ingress with web TLS",
+			ui:     &msoapi.ThanosQuerierUIConfig{Type: msoapi.IngressExposure, Host: "thanos.example.com"},
+			webTLS: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			thanos := &msoapi.ThanosQuerier{Spec: msoapi.ThanosQuerierSpec{UI: tc.ui}}
+			if tc.webTLS {
+				thanos.Spec.WebTLSConfig = &msoapi.WebTLSConfig{}
+			}
+			err := validateUIConfig(thanos, tc.caps)
+			if tc.wantErr {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+		})
+	}
+}
@@ -18,6 +18,8 @@ import (
 	persesv1 "github.com/rhobs/perses/pkg/model/api/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
+
+	uiv1alpha1 "github.com/rhobs/observability-operator/pkg/apis/uiplugin/v1alpha1"
 )
 
 func newAcceleratorsDatasource(namespace string) *persesv1alpha2.PersesDatasource {
@@ -127,7 +129,7 @@ func buildAcceleratorsDashboard() (dashboard.Builder, error) {
 	)
 }
 
-func newAcceleratorsDashboard(namespace string) (*persesv1alpha2.PersesDashboard, error) {
+func newAcceleratorsDashboard(namespace string, _ *uiv1alpha1.PersesReference) (*persesv1alpha2.PersesDashboard, error) {
 	builder, err := buildAcceleratorsDashboard()
 	if err != nil {
 		return nil, err
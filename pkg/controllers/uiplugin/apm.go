@@ -3,6 +3,7 @@ package uiplugin
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/perses/perses/go-sdk/common"
 	"github.com/perses/perses/go-sdk/dashboard"
@@ -17,63 +18,138 @@ import (
 	persesv1 "github.com/rhobs/perses/pkg/model/api/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
+
+	uiv1alpha1 "github.com/rhobs/observability-operator/pkg/apis/uiplugin/v1alpha1"
 )
 
-func withServiceMetrics(variableMatchers string) dashboard.Option {
+// apmSpanMetrics describes the span metrics queried by the APM dashboard.
+type apmSpanMetrics struct {
+	// calls and duration match the names of the calls counter and of the
+	// duration histogram buckets.
+	calls    string
+	duration string
+	// durationUnit is the unit of the duration histogram.
+	durationUnit common.TimeUnit
+	// serviceLabel is the label holding the name of the instrumented service.
+	serviceLabel string
+	// byCollector is true when the metrics are scraped from the
+	// OpenTelemetry collectors and can be filtered by collector.
+	byCollector bool
+	// datasource is the name of the datasource. The default datasource is
+	// used when it is empty.
+	datasource string
+}
+
+// newAPMSpanMetrics returns the span metrics matching the APM dashboard
+// configuration.
+func newAPMSpanMetrics(cfg *uiv1alpha1.APMDashboardConfig) apmSpanMetrics {
+	convention := uiv1alpha1.OpenTelemetrySpanMetrics
+	namespace, datasource := "", ""
+	if cfg != nil {
+		if cfg.Convention != "" {
+			convention = cfg.Convention
+		}
+		namespace = cfg.SpanMetricsNamespace
+		datasource = cfg.Datasource
+	}
+
+	if convention == uiv1alpha1.TempoSpanMetrics {
+		if namespace == "" {
+			namespace = "traces_spanmetrics"
+		}
+		return apmSpanMetrics{
+			calls:        namespace + "_calls_total",
+			duration:     namespace + "_latency_bucket",
+			durationUnit: common.SecondsUnit,
+			serviceLabel: "service",
+			datasource:   datasource,
+		}
+	}
+
+	if namespace == "" {
+		namespace = "traces_span_metrics"
+	}
+	// The OpenTelemetry connector replaces dots by underscores.
+	namespace = strings.ReplaceAll(namespace, ".", "_")
+	return apmSpanMetrics{
+		calls:        namespace + "_calls(_total)?",
+		duration:     namespace + "_duration(_milliseconds)?_bucket",
+		durationUnit: common.MilliSecondsUnit,
+		serviceLabel: "service_name",
+		byCollector:  true,
+		datasource:   datasource,
+	}
+}
+
+// selector returns the series selector of the metric filtered by the given
+// matchers.
+func (m apmSpanMetrics) selector(metric string, matchers ...string) string {
+	return fmt.Sprintf("{%s}", strings.Join(append([]string{fmt.Sprintf("__name__=~%q", metric)}, matchers...), ", "))
+}
+
+// variableMatchers returns the matchers of the dashboard variables.
+func (m apmSpanMetrics) variableMatchers() []string {
+	var matchers []string
+	if m.byCollector {
+		matchers = append(matchers, `namespace="$namespace"`, `service="$collector"`)
+	}
+	return append(matchers, m.serviceLabel+`="$service"`, `span_kind=~"${span_kind}"`)
+}
+
+func (m apmSpanMetrics) query(expr, seriesNameFormat string) panel.Option {
+	options := []query.Option{query.SeriesNameFormat(seriesNameFormat)}
+	if m.datasource != "" {
+		options = append(options, query.Datasource(m.datasource))
+	}
+	return panel.AddQuery(query.PromQL(expr, options...))
+}
+
+func (m apmSpanMetrics) labelValues(label string, matchers ...string) listvariable.Option {
+	options := []labelvalues.Option{labelvalues.Matchers(m.selector(m.calls, matchers...))}
+	if m.datasource != "" {
+		options = append(options, labelvalues.Datasource(m.datasource))
+	}
+	return labelvalues.PrometheusLabelValues(label, options...)
+}
+
+func withServiceMetrics(m apmSpanMetrics) dashboard.Option {
+	calls := m.selector(m.calls, m.variableMatchers()...)
+	errors := m.selector(m.calls, append(m.variableMatchers(), `status_code="STATUS_CODE_ERROR"`)...)
+	duration := m.selector(m.duration, m.variableMatchers()...)
+
 	return dashboard.AddPanelGroup("Service Metrics",
 		panelgroup.PanelsPerLine(3),
 		panelgroup.AddPanel("Request rate",
 			timeseries.Chart(),
-			panel.AddQuery(
-				query.PromQL(
-					fmt.Sprintf(`sum(rate({__name__=~"traces_span_metrics_calls(_total)?", %s}[$__rate_interval]))`, variableMatchers),
-					query.SeriesNameFormat("req/s"),
-				),
-			),
+			m.query(fmt.Sprintf(`sum(rate(%s[$__rate_interval]))`, calls), "req/s"),
 		),
 		panelgroup.AddPanel("Error rate",
 			timeseries.Chart(),
-			panel.AddQuery(
-				query.PromQL(
-					fmt.Sprintf(`sum(rate({__name__=~"traces_span_metrics_calls(_total)?", %s, status_code="STATUS_CODE_ERROR"}[$__rate_interval])) or vector(0)`, variableMatchers),
-					query.SeriesNameFormat("error/s"),
-				),
-			),
+			m.query(fmt.Sprintf(`sum(rate(%s[$__rate_interval])) or vector(0)`, errors), "error/s"),
 		),
 		panelgroup.AddPanel("Latency",
 			timeseries.Chart(
 				timeseries.WithYAxis(timeseries.YAxis{
 					Format: &common.Format{
-						Unit: ptr.To(string(common.MilliSecondsUnit)),
+						Unit: ptr.To(string(m.durationUnit)),
 					},
 				}),
 				timeseries.WithLegend(timeseries.Legend{
 					Position: timeseries.BottomPosition,
 				}),
 			),
-			panel.AddQuery(
-				query.PromQL(
-					fmt.Sprintf(`histogram_quantile(.95, sum(rate({__name__=~"traces_span_metrics_duration(_milliseconds)?_bucket", %s}[$__rate_interval])) by (le))`, variableMatchers),
-					query.SeriesNameFormat("95th"),
-				),
-			),
-			panel.AddQuery(
-				query.PromQL(
-					fmt.Sprintf(`histogram_quantile(.75, sum(rate({__name__=~"traces_span_metrics_duration(_milliseconds)?_bucket", %s}[$__rate_interval])) by (le))`, variableMatchers),
-					query.SeriesNameFormat("75th"),
-				),
-			),
-			panel.AddQuery(
-				query.PromQL(
-					fmt.Sprintf(`histogram_quantile(.50, sum(rate({__name__=~"traces_span_metrics_duration(_milliseconds)?_bucket", %s}[$__rate_interval])) by (le))`, variableMatchers),
-					query.SeriesNameFormat("50th"),
-				),
-			),
+			m.query(fmt.Sprintf(`histogram_quantile(.95, sum(rate(%s[$__rate_interval])) by (le))`, duration), "95th"),
+			m.query(fmt.Sprintf(`histogram_quantile(.75, sum(rate(%s[$__rate_interval])) by (le))`, duration), "75th"),
+			m.query(fmt.Sprintf(`histogram_quantile(.50, sum(rate(%s[$__rate_interval])) by (le))`, duration), "50th"),
 		),
 	)
 }
 
-func withOperationMetrics(variableMatchers string) dashboard.Option {
+func withOperationMetrics(m apmSpanMetrics) dashboard.Option {
+	calls := m.selector(m.calls, m.variableMatchers()...)
+	errors := m.selector(m.calls, append(m.variableMatchers(), `status_code="STATUS_CODE_ERROR"`)...)
+	duration := m.selector(m.duration, m.variableMatchers()...)
+
 	return dashboard.AddPanelGroup("Operations",
 		panelgroup.PanelsPerLine(1),
 		panelgroup.AddPanel("Operation metrics",
@@ -110,7 +186,7 @@ func withOperationMetrics(variableMatchers string) dashboard.Option {
 						Name:   "value #3",
 						Header: "P95 Latency",
 						Format: &common.Format{
-							Unit:          ptr.To(string(common.MilliSecondsUnit)),
+							Unit:          ptr.To(string(m.durationUnit)),
 							DecimalPlaces: 3,
 						},
 					},
@@ -120,55 +196,43 @@ func withOperationMetrics(variableMatchers string) dashboard.Option {
 					},
 				}),
 			),
-			panel.AddQuery(
-				query.PromQL(
-					fmt.Sprintf(`sum(rate({__name__=~"traces_span_metrics_calls(_total)?", %s}[$__rate_interval])) by (span_name) > 0`, variableMatchers),
-					query.SeriesNameFormat("Request rate"),
-				),
-			),
-			panel.AddQuery(
-				query.PromQL(
-					fmt.Sprintf(`sum(rate({__name__=~"traces_span_metrics_calls(_total)?", %s, status_code="STATUS_CODE_ERROR"}[$__rate_interval])) by (span_name) > 0`, variableMatchers),
-					query.SeriesNameFormat("Error rate"),
-				),
-			),
-			panel.AddQuery(
-				query.PromQL(
-					fmt.Sprintf(`histogram_quantile(.95, sum(rate({__name__=~"traces_span_metrics_duration(_milliseconds)?_bucket", %s}[$__rate_interval])) by (span_name, le)) > 0`, variableMatchers),
-					query.SeriesNameFormat("P95 Latency"),
-				),
-			),
+			m.query(fmt.Sprintf(`sum(rate(%s[$__rate_interval])) by (span_name) > 0`, calls), "Request rate"),
+			m.query(fmt.Sprintf(`sum(rate(%s[$__rate_interval])) by (span_name) > 0`, errors), "Error rate"),
+			m.query(fmt.Sprintf(`histogram_quantile(.95, sum(rate(%s[$__rate_interval])) by (span_name, le)) > 0`, duration), "P95 Latency"),
 		),
 	)
 }
 
-func buildAPMDashboard() (dashboard.Builder, error) {
-	variableMatchers := `namespace="$namespace", service="$collector", service_name="$service", span_kind=~"${span_kind}"`
-
-	return dashboard.New("apm",
+func buildAPMDashboard(m apmSpanMetrics) (dashboard.Builder, error) {
+	options := []dashboard.Option{
 		dashboard.Name("Application Performance Monitoring (APM)"),
-		dashboard.AddVariable("namespace",
-			listvariable.List(
-				listvariable.DisplayName("OTEL Collector Namespace"),
-				labelvalues.PrometheusLabelValues("namespace",
-					labelvalues.Matchers(`{__name__=~"traces_span_metrics_calls(_total)?"}`),
+	}
+
+	// The metrics generated by Tempo aren't labelled with the collector.
+	serviceMatchers := []string{}
+	if m.byCollector {
+		serviceMatchers = []string{`namespace="$namespace"`, `service="$collector"`}
+		options = append(options,
+			dashboard.AddVariable("namespace",
+				listvariable.List(
+					listvariable.DisplayName("OTEL Collector Namespace"),
+					m.labelValues("namespace"),
 				),
 			),
-		),
-		dashboard.AddVariable("collector",
-			listvariable.List(
-				listvariable.DisplayName("OTEL Collector"),
-				labelvalues.PrometheusLabelValues("service",
-					labelvalues.Matchers(`{__name__=~"traces_span_metrics_calls(_total)?", namespace="$namespace"}`),
+			dashboard.AddVariable("collector",
+				listvariable.List(
+					listvariable.DisplayName("OTEL Collector"),
+					m.labelValues("service", `namespace="$namespace"`),
 				),
 			),
-		),
+		)
+	}
+
+	options = append(options,
 		dashboard.AddVariable("service",
 			listvariable.List(
 				listvariable.DisplayName("Service"),
-				labelvalues.PrometheusLabelValues("service_name",
-					labelvalues.Matchers(`{__name__=~"traces_span_metrics_calls(_total)?", namespace="$namespace", service="$collector"}`),
-				),
+				m.labelValues(m.serviceLabel, serviceMatchers...),
 			),
 		),
 		dashboard.AddVariable("span_kind",
@@ -180,18 +244,23 @@ func buildAPMDashboard() (dashboard.Builder, error) {
 				listvariable.AllowMultiple(true),
 				listvariable.AllowAllValue(true),
 				listvariable.CustomAllValue(".*"),
-				labelvalues.PrometheusLabelValues("span_kind",
-					labelvalues.Matchers(`{__name__=~"traces_span_metrics_calls(_total)?"}`),
-				),
+				m.labelValues("span_kind"),
 			),
 		),
-		withServiceMetrics(variableMatchers),
-		withOperationMetrics(variableMatchers),
+		withServiceMetrics(m),
+		withOperationMetrics(m),
 	)
+
+	return dashboard.New("apm", options...)
 }
 
-func newAPMDashboard(namespace string) (*persesv1alpha2.PersesDashboard, error) {
-	builder, err := buildAPMDashboard()
+func newAPMDashboard(namespace string, cfg *uiv1alpha1.PersesReference) (*persesv1alpha2.PersesDashboard, error) {
+	var apm *uiv1alpha1.APMDashboardConfig
+	if cfg != nil {
+		apm = cfg.APM
+	}
+
+	builder, err := buildAPMDashboard(newAPMSpanMetrics(apm))
 	if err != nil {
 		return nil, err
 	}
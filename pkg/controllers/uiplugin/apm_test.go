@@ -0,0 +1,76 @@
+package uiplugin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/prometheus/promql/parser"
+	"gotest.tools/v3/assert"
+
+	uiv1alpha1 "github.com/rhobs/observability-operator/pkg/apis/uiplugin/v1alpha1"
+)
+
+func TestAPMDashboard(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		cfg        *uiv1alpha1.APMDashboardConfig
+		calls      string
+		duration   string
+		variables  []string
+		datasource any
+	}{
+		{
+			name:      "default",
+			calls:     `traces_span_metrics_calls(_total)?`,
+			duration:  `traces_span_metrics_duration(_milliseconds)?_bucket`,
+			variables: []string{"namespace", "collector", "service", "span_kind"},
+		},
+		{
+			name: "opentelemetry",
+			cfg: &uiv1alpha1.APMDashboardConfig{
+				SpanMetricsNamespace: "span.metrics",
+				Datasource:           "ms-datasource",
+			},
+			calls:      `span_metrics_calls(_total)?`,
+			duration:   `span_metrics_duration(_milliseconds)?_bucket`,
+			variables:  []string{"namespace", "collector", "service", "span_kind"},
+			datasource: map[string]any{"kind": "PrometheusDatasource", "name": "ms-datasource"},
+		},
+		{
+			name:      "tempo",
+			cfg:       &uiv1alpha1.APMDashboardConfig{Convention: uiv1alpha1.TempoSpanMetrics},
+			calls:     `traces_spanmetrics_calls_total`,
+			duration:  `traces_spanmetrics_latency_bucket`,
+			variables: []string{"service", "span_kind"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dashboard, err := newAPMDashboard("ns", &uiv1alpha1.PersesReference{Enabled: true, APM: tc.cfg})
+			assert.NilError(t, err)
+
+			spec := dashboard.Spec.Config.Spec
+			var variables []string
+			for _, v := range spec.Variables {
+				variables = append(variables, v.Spec.GetName())
+			}
+			assert.DeepEqual(t, variables, tc.variables)
+
+			var queries []string
+			for key, panel := range spec.Panels {
+				for _, q := range panel.Spec.Queries {
+					querySpec := q.Spec.Plugin.Spec.(map[string]any)
+					assert.DeepEqual(t, querySpec["datasource"], tc.datasource)
+
+					query := querySpec["query"].(string)
+					_, err := parser.ParseExpr(strings.ReplaceAll(query, "$__rate_interval", "5m"))
+					assert.NilError(t, err, "panel %q", key)
+					queries = append(queries, query)
+				}
+			}
+
+			all := strings.Join(queries, "\n")
+			assert.Assert(t, strings.Contains(all, `{__name__=~"`+tc.calls+`"`), all)
+			assert.Assert(t, strings.Contains(all, `{__name__=~"`+tc.duration+`"`), all)
+		})
+	}
+}
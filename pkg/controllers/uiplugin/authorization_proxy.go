@@ -0,0 +1,381 @@
+package uiplugin
+
+import (
+	"fmt"
+	"slices"
+
+	osv1 "github.com/openshift/api/console/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/yaml"
+
+	uiv1alpha1 "github.com/rhobs/observability-operator/pkg/apis/uiplugin/v1alpha1"
+)
+
+const (
+	authorizationProxyConfigDir = "/etc/authorization-proxy"
+	authorizationProxyTLSDir    = "/etc/tls/private"
+	// authorizationProxyBasePort is the port of the first authorized proxy.
+	// The next ones listen on the following ports.
+	authorizationProxyBasePort = 9443
+	// serviceCAFile is the service CA bundle injected in the pods by
+	// OpenShift.
+	serviceCAFile = "/var/run/secrets/kubernetes.io/serviceaccount/service-ca.crt"
+)
+
+// proxyAuthorization is the permission which a user must have to send
+// requests through a kube-rbac-proxy container. Alias references the
+// console proxy of the plugin which is protected.
+type proxyAuthorization struct {
+	Alias       string
+	Namespace   string
+	Group       string
+	Resource    string
+	Subresource string
+	Name        string
+	// NamespaceQueryParameter is the query parameter of the requests which
+	// contains the namespace of the resource.
+	NamespaceQueryParameter string
+}
+
+// authorizationProxyConfig is the configuration of the kube-rbac-proxy
+// instances checking the permissions of the users before forwarding the
+// requests of the console to the plugin backends.
+type authorizationProxyConfig struct {
+	Image     string
+	Upstreams []authorizedUpstream
+	// Config contains the kube-rbac-proxy configuration and the CA
+	// certificate of every upstream.
+	Config map[string]string
+}
+
+// authorizedUpstream is a backend service protected by a kube-rbac-proxy
+// container.
+type authorizedUpstream struct {
+	Alias  string
+	Port   int32
+	URL    string
+	CAFile string
+}
+
+// kubeRBACProxyConfig is the configuration file of kube-rbac-proxy.
+type kubeRBACProxyConfig struct {
+	Authorization kubeRBACProxyAuthorization `json:"authorization"`
+}
+
+type kubeRBACProxyAuthorization struct {
+	Rewrites           *kubeRBACProxyRewrites          `json:"rewrites,omitempty"`
+	ResourceAttributes kubeRBACProxyResourceAttributes `json:"resourceAttributes"`
+}
+
+type kubeRBACProxyRewrites struct {
+	ByQueryParameter *kubeRBACProxyQueryParameter `json:"byQueryParameter,omitempty"`
+}
+
+type kubeRBACProxyQueryParameter struct {
+	Name string `json:"name"`
+}
+
+type kubeRBACProxyResourceAttributes struct {
+	Namespace   string `json:"namespace,omitempty"`
+	APIGroup    string `json:"apiGroup,omitempty"`
+	Resource    string `json:"resource,omitempty"`
+	Subresource string `json:"subresource,omitempty"`
+	Name        string `json:"name,omitempty"`
+}
+
+func authorizationProxyName(pluginName string) string {
+	return pluginName + "-authz"
+}
+
+// addProxyAuthorization routes the proxies of the plugin with an
+// authorization through the authorization proxy of the plugin.
+func addProxyAuthorization(pluginInfo *UIPluginInfo, authorizations []proxyAuthorization, namespace, image string) error {
+	if len(authorizations) == 0 {
+		return nil
+	}
+	if image == "" {
+		return fmt.Errorf("no image provided for the authorization proxy")
+	}
+
+	name := authorizationProxyName(pluginInfo.Name)
+	cfg := &authorizationProxyConfig{
+		Image:  image,
+		Config: map[string]string{},
+	}
+
+	for i, authz := range authorizations {
+		proxy := findConsolePluginProxy(pluginInfo.Proxies, authz.Alias)
+		if proxy == nil || proxy.Endpoint.Service == nil {
+			return fmt.Errorf("proxy %q not found in plugin %s", authz.Alias, pluginInfo.Name)
+		}
+
+		service := proxy.Endpoint.Service
+		upstream := authorizedUpstream{
+			Alias:  authz.Alias,
+			Port:   authorizationProxyBasePort + int32(i),
+			URL:    fmt.Sprintf("https://%s.%s.svc:%d/", service.Name, service.Namespace, service.Port),
+			CAFile: serviceCAFile,
+		}
+		if proxy.CACertificate != "" {
+			cfg.Config[authz.Alias+"-ca.crt"] = proxy.CACertificate
+			upstream.CAFile = fmt.Sprintf("%s/%s-ca.crt", authorizationProxyConfigDir, authz.Alias)
+		}
+
+		config, err := yaml.Marshal(newKubeRBACProxyConfig(authz))
+		if err != nil {
+			return err
+		}
+		cfg.Config[authz.Alias+".yaml"] = string(config)
+		cfg.Upstreams = append(cfg.Upstreams, upstream)
+
+		// The user token is required to check the permissions.
+		proxy.Authorization = osv1.UserToken
+		proxy.CACertificate = ""
+		proxy.Endpoint.Service = &osv1.ConsolePluginProxyServiceConfig{
+			Name:      name,
+			Namespace: namespace,
+			Port:      upstream.Port,
+		}
+
+		for j := range pluginInfo.LegacyProxies {
+			legacy := &pluginInfo.LegacyProxies[j]
+			if legacy.Alias != authz.Alias {
+				continue
+			}
+			legacy.Authorize = true
+			legacy.CACertificate = ""
+			legacy.Service.Name = name
+			legacy.Service.Namespace = namespace
+			legacy.Service.Port = upstream.Port
+		}
+	}
+
+	pluginInfo.AuthorizationProxy = cfg
+	return nil
+}
+
+func findConsolePluginProxy(proxies []osv1.ConsolePluginProxy, alias string) *osv1.ConsolePluginProxy {
+	for i := range proxies {
+		if proxies[i].Alias == alias {
+			return &proxies[i]
+		}
+	}
+	return nil
+}
+
+func newKubeRBACProxyConfig(authz proxyAuthorization) kubeRBACProxyConfig {
+	config := kubeRBACProxyConfig{
+		Authorization: kubeRBACProxyAuthorization{
+			ResourceAttributes: kubeRBACProxyResourceAttributes{
+				Namespace:   authz.Namespace,
+				APIGroup:    authz.Group,
+				Resource:    authz.Resource,
+				Subresource: authz.Subresource,
+				Name:        authz.Name,
+			},
+		},
+	}
+
+	if authz.NamespaceQueryParameter != "" {
+		config.Authorization.Rewrites = &kubeRBACProxyRewrites{
+			ByQueryParameter: &kubeRBACProxyQueryParameter{Name: authz.NamespaceQueryParameter},
+		}
+		config.Authorization.ResourceAttributes.Namespace = "{{ .Value }}"
+	}
+
+	return config
+}
+
+// mergeProxyAuthorization returns the authorizations of the plugin with the
+// ones defined by the user. A user authorization replaces the built-in
+// authorization of the same proxy.
+func mergeProxyAuthorization(builtin []proxyAuthorization, user []uiv1alpha1.ProxyAuthorization) []proxyAuthorization {
+	var merged []proxyAuthorization
+	for _, authz := range builtin {
+		if !slices.ContainsFunc(user, func(u uiv1alpha1.ProxyAuthorization) bool { return u.Alias == authz.Alias }) {
+			merged = append(merged, authz)
+		}
+	}
+
+	for _, authz := range user {
+		merged = append(merged, proxyAuthorization{
+			Alias:                   authz.Alias,
+			Namespace:               authz.Namespace,
+			Group:                   authz.Group,
+			Resource:                authz.Resource,
+			Subresource:             authz.Subresource,
+			Name:                    authz.Name,
+			NamespaceQueryParameter: authz.NamespaceQueryParameter,
+		})
+	}
+
+	return merged
+}
+
+func newAuthorizationProxyConfigMap(info UIPluginInfo, namespace string) *corev1.ConfigMap {
+	name := authorizationProxyName(info.Name)
+	cm := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    componentLabels(name),
+		},
+	}
+	if info.AuthorizationProxy != nil {
+		cm.Data = info.AuthorizationProxy.Config
+	}
+
+	return cm
+}
+
+func newAuthorizationProxyService(info UIPluginInfo, namespace string) *corev1.Service {
+	name := authorizationProxyName(info.Name)
+
+	var ports []corev1.ServicePort
+	if info.AuthorizationProxy != nil {
+		for _, upstream := range info.AuthorizationProxy.Upstreams {
+			ports = append(ports, corev1.ServicePort{
+				Port:       upstream.Port,
+				Name:       fmt.Sprintf("authz-%d", upstream.Port),
+				Protocol:   corev1.ProtocolTCP,
+				TargetPort: intstr.FromInt32(upstream.Port),
+			})
+		}
+	}
+
+	return &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Service",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    componentLabels(name),
+			Annotations: map[string]string{
+				"service.alpha.openshift.io/serving-cert-secret-name": name + "-tls",
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Ports:    ports,
+			Selector: componentLabels(name),
+			Type:     corev1.ServiceTypeClusterIP,
+		},
+	}
+}
+
+func newAuthorizationProxyDeployment(info UIPluginInfo, namespace string) *appsv1.Deployment {
+	name := authorizationProxyName(info.Name)
+	settings := newDeploymentSettings(info.DeploymentConfig)
+	cfg := info.AuthorizationProxy
+	if cfg == nil {
+		cfg = &authorizationProxyConfig{}
+	}
+
+	var containers []corev1.Container
+	for _, upstream := range cfg.Upstreams {
+		args := []string{
+			fmt.Sprintf("--secure-listen-address=0.0.0.0:%d", upstream.Port),
+			fmt.Sprintf("--upstream=%s", upstream.URL),
+			fmt.Sprintf("--upstream-ca-file=%s", upstream.CAFile),
+			fmt.Sprintf("--config-file=%s/%s.yaml", authorizationProxyConfigDir, upstream.Alias),
+			fmt.Sprintf("--tls-cert-file=%s/tls.crt", authorizationProxyTLSDir),
+			fmt.Sprintf("--tls-private-key-file=%s/tls.key", authorizationProxyTLSDir),
+		}
+		if info.TLSMinVersion != "" {
+			args = append(args, fmt.Sprintf("--tls-min-version=%s", info.TLSMinVersion))
+		}
+
+		containers = append(containers, corev1.Container{
+			Name:  fmt.Sprintf("authz-%d", upstream.Port),
+			Image: cfg.Image,
+			Args:  args,
+			Ports: []corev1.ContainerPort{
+				{
+					ContainerPort: upstream.Port,
+					Name:          fmt.Sprintf("authz-%d", upstream.Port),
+				},
+			},
+			Resources:                settings.resources,
+			TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+			SecurityContext: &corev1.SecurityContext{
+				RunAsNonRoot:             ptr.To(true),
+				AllowPrivilegeEscalation: ptr.To(false),
+				ReadOnlyRootFilesystem:   ptr.To(true),
+				Capabilities: &corev1.Capabilities{
+					Drop: []corev1.Capability{"ALL"},
+				},
+			},
+			VolumeMounts: []corev1.VolumeMount{
+				{Name: "config", ReadOnly: true, MountPath: authorizationProxyConfigDir},
+				{Name: servingCertVolumeName, ReadOnly: true, MountPath: authorizationProxyTLSDir},
+			},
+		})
+	}
+
+	return &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: appsv1.SchemeGroupVersion.String(),
+			Kind:       "Deployment",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    componentLabels(name),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To(settings.replicas),
+			Selector: &metav1.LabelSelector{
+				MatchLabels: componentLabels(name),
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: componentLabels(name),
+					Annotations: map[string]string{
+						annotationPrefix + "config-hash": computeConfigMapHash(newAuthorizationProxyConfigMap(info, namespace)),
+					},
+				},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: name + serviceAccountSuffix,
+					Containers:         containers,
+					Volumes: []corev1.Volume{
+						{
+							Name: "config",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{Name: name},
+								},
+							},
+						},
+						{
+							Name: servingCertVolumeName,
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{
+									SecretName: name + "-tls",
+								},
+							},
+						},
+					},
+					NodeSelector:              settings.nodeSelector,
+					Tolerations:               settings.tolerations,
+					TopologySpreadConstraints: settings.topologySpreadConstraints,
+					PriorityClassName:         settings.priorityClassName,
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsNonRoot: ptr.To(true),
+						SeccompProfile: &corev1.SeccompProfile{
+							Type: corev1.SeccompProfileTypeRuntimeDefault,
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,126 @@
+package uiplugin
+
+import (
+	"testing"
+
+	osv1 "github.com/openshift/api/console/v1"
+	"gotest.tools/v3/assert"
+
+	uiv1alpha1 "github.com/rhobs/observability-operator/pkg/apis/uiplugin/v1alpha1"
+)
+
+func TestAddProxyAuthorization(t *testing.T) {
+	info := getBasePluginInfo("ns", "monitoring", "image")
+	addMonitoringProxies(info, "monitoring", "ns", "http://alertmanager:9093", "http://thanos:9090")
+
+	err := addProxyAuthorization(info, []proxyAuthorization{
+		{Alias: "alertmanager-proxy", Group: "monitoring.rhobs", Resource: "monitoringstacks", Subresource: "api", Name: "ms", Namespace: "team-a"},
+		{Alias: "thanos-proxy", Group: "monitoring.rhobs", Resource: "thanosqueriers", Subresource: "api", Name: "tq", Namespace: "team-a"},
+	}, "ns", "kube-rbac-proxy")
+	assert.NilError(t, err)
+
+	// The proxies are routed through the authorization proxy.
+	for i, alias := range []string{"alertmanager-proxy", "thanos-proxy"} {
+		port := int32(authorizationProxyBasePort + i)
+
+		proxy := findConsolePluginProxy(info.Proxies, alias)
+		assert.Equal(t, proxy.Authorization, osv1.UserToken)
+		assert.DeepEqual(t, *proxy.Endpoint.Service, osv1.ConsolePluginProxyServiceConfig{Name: "monitoring-authz", Namespace: "ns", Port: port})
+
+		for _, legacy := range info.LegacyProxies {
+			if legacy.Alias == alias {
+				assert.Equal(t, legacy.Service.Name, "monitoring-authz")
+				assert.Equal(t, legacy.Service.Port, port)
+			}
+		}
+	}
+
+	cfg := info.AuthorizationProxy
+	assert.Equal(t, cfg.Upstreams[0].URL, "https://monitoring.ns.svc:9444/")
+	assert.Equal(t, cfg.Upstreams[1].URL, "https://monitoring.ns.svc:9445/")
+	assert.Equal(t, cfg.Config["thanos-proxy.yaml"], `authorization:
+  resourceAttributes:
+    apiGroup: monitoring.rhobs
+    name: tq
+    namespace: team-a
+    resource: thanosqueriers
+    subresource: api
+`)
+
+	deployment := newAuthorizationProxyDeployment(*info, "ns")
+	containers := deployment.Spec.Template.Spec.Containers
+	assert.Equal(t, len(containers), 2)
+	assert.Equal(t, containers[0].Image, "kube-rbac-proxy")
+	assert.Equal(t, containers[0].Args[1], "--upstream=https://monitoring.ns.svc:9444/")
+	assert.Equal(t, len(newAuthorizationProxyService(*info, "ns").Spec.Ports), 2)
+
+	// The custom CA of a proxy is used to verify the upstream.
+	info = &UIPluginInfo{
+		Name: "custom",
+		Proxies: []osv1.ConsolePluginProxy{
+			{
+				Alias:         "api",
+				CACertificate: "ca",
+				Endpoint: osv1.ConsolePluginProxyEndpoint{
+					Type:    osv1.ProxyTypeService,
+					Service: &osv1.ConsolePluginProxyServiceConfig{Name: "api", Namespace: "app", Port: 8443},
+				},
+			},
+		},
+	}
+	assert.NilError(t, addProxyAuthorization(info, []proxyAuthorization{{Alias: "api", Resource: "services", Namespace: "app"}}, "ns", "kube-rbac-proxy"))
+	assert.Equal(t, info.Proxies[0].CACertificate, "")
+	assert.Equal(t, info.Proxies[0].Authorization, osv1.UserToken)
+	assert.Equal(t, info.AuthorizationProxy.Config["api-ca.crt"], "ca")
+	assert.Equal(t, info.AuthorizationProxy.Upstreams[0].CAFile, authorizationProxyConfigDir+"/api-ca.crt")
+
+	err = addProxyAuthorization(info, []proxyAuthorization{{Alias: "unknown", Resource: "pods"}}, "ns", "kube-rbac-proxy")
+	assert.ErrorContains(t, err, `proxy "unknown" not found in plugin custom`)
+
+	err = addProxyAuthorization(info, []proxyAuthorization{{Alias: "api", Resource: "pods"}}, "ns", "")
+	assert.ErrorContains(t, err, "no image provided")
+}
+
+func TestNamespaceQueryParameterAuthorization(t *testing.T) {
+	info := getBasePluginInfo("ns", "monitoring", "image")
+	addPersesProxy(info, "ns")
+
+	err := addProxyAuthorization(info, []proxyAuthorization{
+		{Alias: "perses", Group: "perses.dev", Resource: "persesdashboards", NamespaceQueryParameter: "project"},
+	}, "ns", "kube-rbac-proxy")
+	assert.NilError(t, err)
+
+	// The namespace of the request is checked.
+	assert.Equal(t, info.AuthorizationProxy.Upstreams[0].URL, "https://perses.ns.svc:8080/")
+	assert.Equal(t, info.AuthorizationProxy.Config["perses.yaml"], `authorization:
+  resourceAttributes:
+    apiGroup: perses.dev
+    namespace: '{{ .Value }}'
+    resource: persesdashboards
+  rewrites:
+    byQueryParameter:
+      name: project
+`)
+}
+
+func TestMergeProxyAuthorization(t *testing.T) {
+	builtin := []proxyAuthorization{
+		newMonitoringBackendAuthorization("alertmanager-proxy", "monitoringstacks", &uiv1alpha1.NamespacedReference{Name: "ms", Namespace: "team-a"}),
+		newMonitoringBackendAuthorization("thanos-proxy", "thanosqueriers", &uiv1alpha1.NamespacedReference{Name: "tq", Namespace: "team-a"}),
+	}
+
+	// A user authorization replaces the built-in authorization of the same
+	// proxy.
+	merged := mergeProxyAuthorization(builtin, []uiv1alpha1.ProxyAuthorization{
+		{Alias: "thanos-proxy", Resource: "namespaces"},
+		{Alias: "backend", Resource: "namespaces", NamespaceQueryParameter: "namespace"},
+	})
+	assert.DeepEqual(t, merged, []proxyAuthorization{
+		builtin[0],
+		{Alias: "thanos-proxy", Resource: "namespaces"},
+		{Alias: "backend", Resource: "namespaces", NamespaceQueryParameter: "namespace"},
+	})
+
+	assert.DeepEqual(t, mergeProxyAuthorization(builtin, nil), builtin)
+	assert.Assert(t, mergeProxyAuthorization(nil, nil) == nil)
+}
@@ -2,10 +2,15 @@ package uiplugin
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"slices"
 	"strings"
 
 	"golang.org/x/mod/semver"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	uiv1alpha1 "github.com/rhobs/observability-operator/pkg/apis/uiplugin/v1alpha1"
@@ -32,6 +37,37 @@ type CompatibilityEntry struct {
 	// SupportsTLSProfile indicates whether this plugin image supports
 	// -tls-min-version and -tls-cipher-suites command flags.
 	SupportsTLSProfile bool
+	// SupportsLokiStacks indicates whether this plugin image reads the list
+	// of LokiStacks from its configuration file. It only applies to the
+	// Logging plugin.
+	SupportsLokiStacks bool
+	// Image overrides the image referenced by ImageKey. It can only be set
+	// by the compatibility matrix ConfigMap.
+	Image string
+	// Source is the origin of the entry.
+	Source uiv1alpha1.CompatibilitySource
+}
+
+const (
+	// CompatibilityMatrixConfigMapName is the name of the ConfigMap, in the
+	// operator namespace, which extends or overrides the built-in
+	// compatibility matrix.
+	CompatibilityMatrixConfigMapName = "observability-operator-compatibility-matrix"
+	compatibilityMatrixKey           = "compatibility-matrix.yaml"
+)
+
+// compatibilityMatrixConfigEntry is the representation of a compatibility
+// matrix entry in the ConfigMap.
+type compatibilityMatrixConfigEntry struct {
+	PluginType         uiv1alpha1.UIPluginType `yaml:"pluginType"`
+	MinClusterVersion  string                  `yaml:"minClusterVersion"`
+	MaxClusterVersion  string                  `yaml:"maxClusterVersion"`
+	ImageKey           string                  `yaml:"imageKey"`
+	Image              string                  `yaml:"image"`
+	SupportLevel       SupportLevel            `yaml:"supportLevel"`
+	Features           []string                `yaml:"features"`
+	SupportsTLSProfile bool                    `yaml:"supportsTLSProfile"`
+	SupportsLokiStacks bool                    `yaml:"supportsLokiStacks"`
 }
 
 type ListFunction func(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error
@@ -131,11 +167,12 @@ var compatibilityMatrix = []CompatibilityEntry{
 		},
 	},
 	{
-		PluginType:        uiv1alpha1.TypeLogging,
-		MinClusterVersion: "v4.22",
-		MaxClusterVersion: "",
-		ImageKey:          "ui-logging",
-		SupportLevel:      GeneralAvailability,
+		PluginType:         uiv1alpha1.TypeLogging,
+		MinClusterVersion:  "v4.22",
+		MaxClusterVersion:  "",
+		ImageKey:           "ui-logging",
+		SupportLevel:       GeneralAvailability,
+		SupportsLokiStacks: true,
 		Features: []string{
 			"dev-console",
 			"alerts",
@@ -163,9 +200,97 @@ var compatibilityMatrix = []CompatibilityEntry{
 		Features:           []string{},
 		SupportsTLSProfile: true,
 	},
+	{
+		PluginType:        uiv1alpha1.TypeCustom,
+		MinClusterVersion: "v4.12",
+		MaxClusterVersion: "",
+		// the image of custom plugins is defined by the UIPlugin resource
+		ImageKey:     "",
+		SupportLevel: DevPreview,
+		Features:     []string{},
+	},
+}
+
+// parseCompatibilityMatrix parses and validates the compatibility matrix
+// entries of the ConfigMap. images is the set of known image keys.
+func parseCompatibilityMatrix(cm *corev1.ConfigMap, images map[string]string) ([]CompatibilityEntry, error) {
+	data, found := cm.Data[compatibilityMatrixKey]
+	if !found {
+		return nil, fmt.Errorf("compatibility matrix ConfigMap %s/%s: missing %q key", cm.Namespace, cm.Name, compatibilityMatrixKey)
+	}
+
+	var configEntries []compatibilityMatrixConfigEntry
+	decoder := yaml.NewDecoder(strings.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&configEntries); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("compatibility matrix ConfigMap %s/%s: %w", cm.Namespace, cm.Name, err)
+	}
+
+	entries := make([]CompatibilityEntry, 0, len(configEntries))
+	for i, e := range configEntries {
+		if err := validateCompatibilityMatrixConfigEntry(e, images); err != nil {
+			return nil, fmt.Errorf("compatibility matrix ConfigMap %s/%s: entry %d: %w", cm.Namespace, cm.Name, i, err)
+		}
+
+		entries = append(entries, CompatibilityEntry{
+			PluginType:         e.PluginType,
+			MinClusterVersion:  e.MinClusterVersion,
+			MaxClusterVersion:  e.MaxClusterVersion,
+			ImageKey:           e.ImageKey,
+			Image:              e.Image,
+			SupportLevel:       e.SupportLevel,
+			Features:           e.Features,
+			SupportsTLSProfile: e.SupportsTLSProfile,
+			SupportsLokiStacks: e.SupportsLokiStacks,
+			Source:             uiv1alpha1.ConfigMapCompatibilitySource,
+		})
+	}
+
+	return entries, nil
+}
+
+func validateCompatibilityMatrixConfigEntry(e compatibilityMatrixConfigEntry, images map[string]string) error {
+	if _, found := pluginTypeToConsoleName[e.PluginType]; !found && e.PluginType != uiv1alpha1.TypeCustom {
+		return fmt.Errorf("unknown plugin type %q", e.PluginType)
+	}
+
+	if !semver.IsValid(e.MinClusterVersion) {
+		return fmt.Errorf("invalid minClusterVersion %q", e.MinClusterVersion)
+	}
+
+	if e.MaxClusterVersion != "" {
+		if !semver.IsValid(e.MaxClusterVersion) {
+			return fmt.Errorf("invalid maxClusterVersion %q", e.MaxClusterVersion)
+		}
+		if semver.Compare(e.MinClusterVersion, e.MaxClusterVersion) >= 0 {
+			return fmt.Errorf("minClusterVersion %q must be lower than maxClusterVersion %q", e.MinClusterVersion, e.MaxClusterVersion)
+		}
+	}
+
+	// The image of custom plugins is defined by the UIPlugin resource.
+	if e.Image == "" && e.PluginType != uiv1alpha1.TypeCustom {
+		if _, found := images[e.ImageKey]; !found {
+			return fmt.Errorf("unknown imageKey %q", e.ImageKey)
+		}
+	}
+
+	switch e.SupportLevel {
+	case DevPreview, TechPreview, GeneralAvailability, Experimental_SSA:
+	default:
+		return fmt.Errorf("unknown supportLevel %q", e.SupportLevel)
+	}
+
+	return nil
 }
 
 func lookupImageAndFeatures(pluginType uiv1alpha1.UIPluginType, clusterVersion string) (CompatibilityEntry, error) {
+	return lookupCompatibilityEntry(pluginType, clusterVersion, nil)
+}
+
+// lookupCompatibilityEntry returns the compatibility entry matching the
+// plugin type and cluster version. The entries loaded from the ConfigMap
+// take precedence over the built-in ones.
+func lookupCompatibilityEntry(pluginType uiv1alpha1.UIPluginType, clusterVersion string, configMapEntries []CompatibilityEntry) (CompatibilityEntry, error) {
 	if !strings.HasPrefix(clusterVersion, "v") {
 		clusterVersion = "v" + clusterVersion
 	}
@@ -175,7 +300,7 @@ func lookupImageAndFeatures(pluginType uiv1alpha1.UIPluginType, clusterVersion s
 		return CompatibilityEntry{}, fmt.Errorf("dynamic plugins not supported before 4.11")
 	}
 
-	for _, entry := range compatibilityMatrix {
+	for _, entry := range slices.Concat(configMapEntries, compatibilityMatrix) {
 		if entry.PluginType != pluginType {
 			continue
 		}
@@ -183,12 +308,28 @@ func lookupImageAndFeatures(pluginType uiv1alpha1.UIPluginType, clusterVersion s
 		matchedVersion, err := compareClusterVersion(entry, clusterVersion, pluginType)
 
 		if err == nil {
+			if matchedVersion.Source == "" {
+				matchedVersion.Source = uiv1alpha1.BuiltInCompatibilitySource
+			}
 			return matchedVersion, nil
 		}
 	}
 	return CompatibilityEntry{}, fmt.Errorf("plugin %q: no compatible image found for cluster version %q", pluginType, clusterVersion)
 }
 
+// latestClusterVersion returns the most recent cluster version of the
+// built-in compatibility matrix. It is used when the cluster has no version,
+// i.e. when it isn't an OpenShift cluster.
+func latestClusterVersion() string {
+	latest := ""
+	for _, entry := range compatibilityMatrix {
+		if latest == "" || semver.Compare(entry.MinClusterVersion, latest) > 0 {
+			latest = entry.MinClusterVersion
+		}
+	}
+	return latest
+}
+
 func compareClusterVersion(entry CompatibilityEntry, clusterVersion string, pluginType uiv1alpha1.UIPluginType) (CompatibilityEntry, error) {
 	canonicalMinClusterVersion := fmt.Sprintf("%s-0", semver.Canonical(entry.MinClusterVersion))
 	canonicalMaxClusterVersion := fmt.Sprintf("%s-0", semver.Canonical(entry.MaxClusterVersion))
@@ -1,11 +1,20 @@
 package uiplugin
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/go-logr/logr"
 	"golang.org/x/mod/semver"
 	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	uiv1alpha1 "github.com/rhobs/observability-operator/pkg/apis/uiplugin/v1alpha1"
 )
@@ -409,3 +418,119 @@ func TestLookupImageAndFeatures(t *testing.T) {
 		})
 	}
 }
+
+func TestParseCompatibilityMatrix(t *testing.T) {
+	images := map[string]string{"ui-logging": "quay.io/logging:latest"}
+	newConfigMap := func(data string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: CompatibilityMatrixConfigMapName, Namespace: "operator"},
+			Data:       map[string]string{compatibilityMatrixKey: data},
+		}
+	}
+
+	entries, err := parseCompatibilityMatrix(newConfigMap(`
+- pluginType: Logging
+  minClusterVersion: v4.20
+  image: quay.io/logging:custom
+  supportLevel: TechPreview
+  features: [dev-console]
+  supportsTLSProfile: true
+  supportsLokiStacks: true
+- pluginType: Logging
+  minClusterVersion: v4.15
+  maxClusterVersion: v4.20
+  imageKey: ui-logging
+  supportLevel: GeneralAvailability
+`), images)
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 2)
+
+	// ConfigMap entries take precedence over the built-in ones.
+	entry, err := lookupCompatibilityEntry(uiv1alpha1.TypeLogging, "v4.21", entries)
+	assert.NilError(t, err)
+	assert.Equal(t, entry.Image, "quay.io/logging:custom")
+	assert.Equal(t, entry.SupportLevel, TechPreview)
+	assert.Assert(t, entry.SupportsLokiStacks)
+	assert.Equal(t, entry.Source, uiv1alpha1.ConfigMapCompatibilitySource)
+
+	entry, err = lookupCompatibilityEntry(uiv1alpha1.TypeLogging, "v4.16", entries)
+	assert.NilError(t, err)
+	assert.Assert(t, !entry.SupportsLokiStacks)
+
+	// Built-in entries apply when no ConfigMap entry matches.
+	entry, err = lookupCompatibilityEntry(uiv1alpha1.TypeDashboards, "v4.21", entries)
+	assert.NilError(t, err)
+	assert.Equal(t, entry.Source, uiv1alpha1.BuiltInCompatibilitySource)
+
+	entries, err = parseCompatibilityMatrix(newConfigMap(""), images)
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 0)
+
+	for _, tc := range []struct {
+		name string
+		data string
+		err  string
+	}{
+		{
+			name: "unknown field",
+			data: "- pluginType: Logging\n  minVersion: v4.15\n",
+			err:  "field minVersion not found",
+		},
+		{
+			name: "unknown plugin type",
+			data: "- pluginType: Unknown\n  minClusterVersion: v4.15\n  imageKey: ui-logging\n  supportLevel: TechPreview\n",
+			err:  `unknown plugin type "Unknown"`,
+		},
+		{
+			name: "invalid version",
+			data: "- pluginType: Logging\n  minClusterVersion: 4.15\n  imageKey: ui-logging\n  supportLevel: TechPreview\n",
+			err:  `invalid minClusterVersion "4.15"`,
+		},
+		{
+			name: "inverted versions",
+			data: "- pluginType: Logging\n  minClusterVersion: v4.16\n  maxClusterVersion: v4.15\n  imageKey: ui-logging\n  supportLevel: TechPreview\n",
+			err:  "must be lower than maxClusterVersion",
+		},
+		{
+			name: "unknown image key",
+			data: "- pluginType: Logging\n  minClusterVersion: v4.15\n  imageKey: ui-unknown\n  supportLevel: TechPreview\n",
+			err:  `unknown imageKey "ui-unknown"`,
+		},
+		{
+			name: "unknown support level",
+			data: "- pluginType: Logging\n  minClusterVersion: v4.15\n  imageKey: ui-logging\n  supportLevel: Beta\n",
+			err:  `unknown supportLevel "Beta"`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseCompatibilityMatrix(newConfigMap(tc.data), images)
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+
+	_, err = parseCompatibilityMatrix(&corev1.ConfigMap{}, images)
+	assert.ErrorContains(t, err, "missing")
+}
+
+func TestGetCompatibilityMatrixEntries(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NilError(t, clientgoscheme.AddToScheme(scheme))
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: CompatibilityMatrixConfigMapName, Namespace: "ns"},
+		Data:       map[string]string{compatibilityMatrixKey: "- unknownField: true"},
+	}
+	recorder := record.NewFakeRecorder(1)
+	rm := resourceManager{
+		k8sClient:  fake.NewClientBuilder().WithScheme(scheme).WithObjects(cm).Build(),
+		pluginConf: UIPluginsConfiguration{ResourcesNamespace: "ns"},
+		recorder:   recorder,
+		logger:     logr.Discard(),
+	}
+
+	// The invalid ConfigMap is ignored in favor of the built-in matrix.
+	entries, err := rm.getCompatibilityMatrixEntries(context.Background(), &uiv1alpha1.UIPlugin{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 0)
+	assert.Assert(t, strings.HasPrefix(<-recorder.Events, "Warning InvalidCompatibilityMatrix using the built-in compatibility matrix"))
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"hash/fnv"
 	"io"
+	"slices"
 	"sort"
 	"strings"
 	"text/template"
@@ -16,6 +17,7 @@ import (
 	"golang.org/x/mod/semver"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -64,18 +66,38 @@ func isVersionAheadOrEqual(currentVersion, version string) bool {
 	return semver.Compare(currentVersion, canonicalMinVersion) >= 0
 }
 
-func pluginComponentReconcilers(plugin *uiv1alpha1.UIPlugin, pluginInfo UIPluginInfo, clusterVersion string, logger logr.Logger) []reconciler.Reconciler {
+func pluginComponentReconcilers(plugin *uiv1alpha1.UIPlugin, pluginInfo UIPluginInfo, clusterVersion string, caps clusterCapabilities, logger logr.Logger) []reconciler.Reconciler {
 	namespace := pluginInfo.ResourceNamespace
 
+	// Without the console, only the backend services of the plugin are
+	// deployed. The service account is kept since the health analyzer runs
+	// with it.
 	components := []reconciler.Reconciler{
 		reconciler.NewUpdater(newServiceAccount(pluginInfo.Name, namespace), plugin),
-		reconciler.NewUpdater(newDeployment(pluginInfo, namespace, plugin.Spec.Deployment), plugin),
-		reconciler.NewUpdater(newService(pluginInfo, namespace), plugin),
-	}
-
-	if isVersionAheadOrEqual(clusterVersion, "v4.17") {
+		reconciler.NewOptionalUpdater(newDeployment(pluginInfo, namespace, plugin.Spec.Deployment), plugin, caps.console),
+		reconciler.NewOptionalUpdater(newService(pluginInfo, namespace), plugin, caps.console),
+		reconciler.NewOptionalUpdater(newPodDisruptionBudget(pluginInfo.Name, namespace, componentLabels(pluginInfo.Name), pluginInfo.DeploymentConfig), plugin, caps.console && newDeploymentSettings(pluginInfo.DeploymentConfig).replicas > 1),
+	}
+
+	// The authorization proxy checks the permissions of the users before
+	// forwarding the requests to the backends of the plugin. In standalone
+	// mode, it protects the exposed backend services.
+	authorizationProxyEnabled := pluginInfo.AuthorizationProxy != nil
+	authorizationProxy := authorizationProxyName(pluginInfo.Name)
+	components = append(components,
+		reconciler.NewOptionalUpdater(newServiceAccount(authorizationProxy, namespace), plugin, authorizationProxyEnabled),
+		reconciler.NewOptionalUpdater(newClusterRoleBinding(namespace, authorizationProxy+serviceAccountSuffix, "system:auth-delegator", authorizationProxy+"-system-auth-delegator"), plugin, authorizationProxyEnabled),
+		reconciler.NewOptionalUpdater(newAuthorizationProxyConfigMap(pluginInfo, namespace), plugin, authorizationProxyEnabled),
+		reconciler.NewOptionalUpdater(newAuthorizationProxyService(pluginInfo, namespace), plugin, authorizationProxyEnabled),
+		reconciler.NewOptionalUpdater(newAuthorizationProxyDeployment(pluginInfo, namespace), plugin, authorizationProxyEnabled),
+		reconciler.NewOptionalUpdater(newPodDisruptionBudget(authorizationProxy, namespace, componentLabels(authorizationProxy), pluginInfo.DeploymentConfig), plugin, authorizationProxyEnabled && newDeploymentSettings(pluginInfo.DeploymentConfig).replicas > 1),
+	)
+
+	switch {
+	case !caps.console:
+	case isVersionAheadOrEqual(clusterVersion, "v4.17"):
 		components = append(components, reconciler.NewUpdater(newConsolePlugin(pluginInfo, namespace), plugin))
-	} else {
+	default:
 		components = append(components, reconciler.NewUpdater(newLegacyConsolePlugin(pluginInfo, namespace), plugin))
 	}
 
@@ -104,12 +126,44 @@ func pluginComponentReconcilers(plugin *uiv1alpha1.UIPlugin, pluginInfo UIPlugin
 	}
 
 	if plugin.Spec.Type == uiv1alpha1.TypeTroubleshootingPanel && pluginInfo.Korrel8rImage != "" {
+		deployKorrel8r := backendComponentActive(plugin, pluginInfo, caps, korrel8rName)
 		components = append(components, reconciler.NewUpdater(newKorrel8rService(korrel8rName, namespace), plugin))
 		korrel8rCm, err := newKorrel8rConfigMap(korrel8rName, namespace, pluginInfo)
 		if err == nil && korrel8rCm != nil {
 			components = append(components, reconciler.NewUpdater(korrel8rCm, plugin))
-			components = append(components, reconciler.NewUpdater(newKorrel8rDeployment(korrel8rName, namespace, pluginInfo), plugin))
+			components = append(components, reconciler.NewOptionalUpdater(newKorrel8rDeployment(korrel8rName, namespace, pluginInfo, korrel8rCm), plugin, deployKorrel8r))
+			components = append(components, reconciler.NewOptionalUpdater(newPodDisruptionBudget(korrel8rName, namespace, componentLabels(korrel8rName), pluginInfo.DeploymentConfig), plugin, deployKorrel8r && korrel8rDeploymentSettings(pluginInfo).replicas > 1))
+		}
+	}
+
+	if plugin.Spec.Type == uiv1alpha1.TypeCustom {
+		customRulesEnabled := len(pluginInfo.CustomRules) > 0
+		components = append(components,
+			reconciler.NewOptionalUpdater(newCustomPluginRole(pluginInfo, namespace), plugin, customRulesEnabled),
+			reconciler.NewOptionalUpdater(newCustomPluginRoleBinding(pluginInfo, namespace), plugin, customRulesEnabled),
+		)
+	}
+
+	if plugin.Spec.Type == uiv1alpha1.TypeLogging {
+		// The cluster roles of the tenants which are no longer queried are
+		// removed.
+		for _, tenant := range allLoggingTenants {
+			components = append(components, reconciler.NewOptionalUpdater(loggingClusterRole(string(tenant)), plugin, slices.Contains(pluginInfo.LoggingTenants, tenant)))
+		}
+
+		lokiProxyEnabled := pluginInfo.LokiProxy != nil
+		lokiProxy := pluginInfo.LokiProxy
+		if lokiProxy == nil {
+			lokiProxy = &lokiProxyConfig{}
 		}
+		components = append(components,
+			reconciler.NewOptionalUpdater(newServiceAccount(lokiProxyName, namespace), plugin, lokiProxyEnabled),
+			reconciler.NewOptionalUpdater(newClusterRoleBinding(namespace, lokiProxyName+serviceAccountSuffix, "system:auth-delegator", lokiProxyName+"-system-auth-delegator"), plugin, lokiProxyEnabled),
+			reconciler.NewOptionalUpdater(newLokiProxyNetworkPolicy(namespace), plugin, lokiProxyEnabled),
+			reconciler.NewOptionalUpdater(newLokiProxySecret(namespace, lokiProxy), plugin, lokiProxyEnabled),
+			reconciler.NewOptionalUpdater(newLokiProxyService(namespace), plugin, lokiProxyEnabled),
+			reconciler.NewOptionalUpdater(newLokiProxyDeployment(namespace, lokiProxy, plugin.Spec.Deployment), plugin, lokiProxyEnabled),
+		)
 	}
 
 	// Only add monitoring-specific components for monitoring plugins to prevent conflicts
@@ -117,63 +171,60 @@ func pluginComponentReconcilers(plugin *uiv1alpha1.UIPlugin, pluginInfo UIPlugin
 	if plugin.Spec.Type == uiv1alpha1.TypeMonitoring {
 		monitoringConfig := plugin.Spec.Monitoring
 		serviceAccountName := plugin.Name + serviceAccountSuffix
-		incidentsEnabled := monitoringConfig != nil &&
-			monitoringConfig.Incidents != nil &&
-			monitoringConfig.Incidents.Enabled &&
-			pluginInfo.HealthAnalyzerImage != ""
-
-		healthAnalyzerEnabled := monitoringConfig != nil &&
-			monitoringConfig.ClusterHealthAnalyzer != nil &&
-			monitoringConfig.ClusterHealthAnalyzer.Enabled &&
-			pluginInfo.HealthAnalyzerImage != ""
-
-		deployHealthAnalyzer := incidentsEnabled || healthAnalyzerEnabled
+		deployHealthAnalyzer := healthAnalyzerActive(plugin, pluginInfo, caps)
+		// The platform monitoring stack is queried unless the health analyzer
+		// is pointed at a MonitoringStack or ThanosQuerier. Their services
+		// don't authenticate the requests: no permission is needed.
+		usePlatformMonitoring := deployHealthAnalyzer && pluginInfo.HealthAnalyzerBackends == nil
 
 		components = append(components,
 			reconciler.NewOptionalUpdater(componentsHealthClusterRole("components-health-view"), plugin, deployHealthAnalyzer),
 			reconciler.NewOptionalUpdater(newClusterRoleBinding(namespace, serviceAccountName, "components-health-view", plugin.Name+"-"+"components-health-view"), plugin, deployHealthAnalyzer),
-			reconciler.NewOptionalUpdater(newComponentHealthConfig(namespace), plugin, deployHealthAnalyzer),
+			reconciler.NewOptionalUpdater(newComponentHealthConfig(namespace, pluginInfo.HealthAnalyzerComponents), plugin, deployHealthAnalyzer),
 		)
 
 		components = append(components,
-			reconciler.NewOptionalUpdater(newClusterRoleBinding(namespace, serviceAccountName, "cluster-monitoring-view", plugin.Name+"cluster-monitoring-view"), plugin, deployHealthAnalyzer),
+			reconciler.NewOptionalUpdater(newClusterRoleBinding(namespace, serviceAccountName, "cluster-monitoring-view", plugin.Name+"cluster-monitoring-view"), plugin, usePlatformMonitoring),
 			reconciler.NewOptionalUpdater(newClusterRoleBinding(namespace, serviceAccountName, "system:auth-delegator", serviceAccountName+"-system-auth-delegator"), plugin, deployHealthAnalyzer),
-			reconciler.NewOptionalUpdater(newAlertManagerViewRoleBinding(serviceAccountName, namespace), plugin, deployHealthAnalyzer),
+			reconciler.NewOptionalUpdater(newAlertManagerViewRoleBinding(serviceAccountName, namespace), plugin, usePlatformMonitoring),
 			reconciler.NewOptionalUpdater(newHealthAnalyzerPrometheusRole(namespace), plugin, deployHealthAnalyzer),
 			reconciler.NewOptionalUpdater(newHealthAnalyzerPrometheusRoleBinding(namespace), plugin, deployHealthAnalyzer),
 			reconciler.NewOptionalUpdater(newHealthAnalyzerService(namespace), plugin, deployHealthAnalyzer),
 			reconciler.NewOptionalUpdater(newHealthAnalyzerDeployment(namespace, serviceAccountName, pluginInfo),
 				plugin, deployHealthAnalyzer),
-			reconciler.NewOptionalUpdater(newHealthAnalyzerServiceMonitor(namespace), plugin, deployHealthAnalyzer),
 		)
 
-		persesServiceAccountName := "perses" + serviceAccountSuffix
-		persesEnabled := monitoringConfig != nil && monitoringConfig.Perses != nil && monitoringConfig.Perses.Enabled
-		components = append(components,
-			reconciler.NewOptionalUpdater(newServiceAccount("perses", namespace), plugin, persesEnabled),
-			reconciler.NewOptionalUpdater(newClusterRoleBinding(namespace, persesServiceAccountName, "system:auth-delegator", persesServiceAccountName+"-system-auth-delegator"), plugin, persesEnabled),
-			reconciler.NewOptionalUpdater(newPersesClusterRole(), plugin, persesEnabled),
-			reconciler.NewOptionalUpdater(newClusterRoleBinding(namespace, persesServiceAccountName, "perses-cr", persesServiceAccountName+"-perses-cr"), plugin, persesEnabled),
-			reconciler.NewOptionalUpdater(newPerses(namespace, pluginInfo.PersesImage), plugin, persesEnabled),
-			reconciler.NewOptionalUpdater(newAcceleratorsDatasource(namespace), plugin, persesEnabled),
-		)
-
-		acceleratorsDashboard, err := newAcceleratorsDashboard(namespace)
-		if err != nil {
-			logger.Error(err, "Cannot build Accelerators dashboard")
-		} else {
-			components = append(components, reconciler.NewOptionalUpdater(acceleratorsDashboard, plugin, persesEnabled))
+		// ServiceMonitors and the Perses resources can't be reconciled (nor
+		// deleted) when their API isn't installed.
+		if caps.serviceMonitors {
+			components = append(components, reconciler.NewOptionalUpdater(newHealthAnalyzerServiceMonitor(namespace), plugin, deployHealthAnalyzer))
 		}
 
-		apmDashboard, err := newAPMDashboard(namespace)
-		if err != nil {
-			logger.Error(err, "Cannot build APM dashboard")
-		} else {
-			components = append(components, reconciler.NewOptionalUpdater(apmDashboard, plugin, persesEnabled))
+		if caps.perses {
+			persesServiceAccountName := "perses" + serviceAccountSuffix
+			deployPerses := backendComponentActive(plugin, pluginInfo, caps, persesServiceName)
+			components = append(components,
+				reconciler.NewOptionalUpdater(newServiceAccount("perses", namespace), plugin, deployPerses),
+				reconciler.NewOptionalUpdater(newClusterRoleBinding(namespace, persesServiceAccountName, "system:auth-delegator", persesServiceAccountName+"-system-auth-delegator"), plugin, deployPerses),
+				reconciler.NewOptionalUpdater(newPersesClusterRole(), plugin, deployPerses),
+				reconciler.NewOptionalUpdater(newClusterRoleBinding(namespace, persesServiceAccountName, "perses-cr", persesServiceAccountName+"-perses-cr"), plugin, deployPerses),
+				reconciler.NewOptionalUpdater(newPerses(namespace, pluginInfo, caps), plugin, deployPerses),
+				reconciler.NewOptionalUpdater(newPodDisruptionBudget("perses", namespace, persesPodLabels, pluginInfo.DeploymentConfig), plugin, deployPerses && persesReplicas(pluginInfo) > 1),
+				reconciler.NewOptionalUpdater(newAcceleratorsDatasource(namespace), plugin, deployPerses),
+			)
+
+			for _, d := range dashboardCatalog {
+				dashboard, err := d.build(namespace, pluginInfo.PersesConfig)
+				if err != nil {
+					logger.Error(err, "Cannot build dashboard", "dashboard", d.name)
+					continue
+				}
+				components = append(components, reconciler.NewOptionalUpdater(dashboard, plugin, deployPerses && d.isEnabled(monitoringConfig.Perses)))
+			}
 		}
 	}
 
-	return components
+	return append(components, exposureReconcilers(plugin, pluginInfo, caps)...)
 
 }
 
@@ -237,7 +288,7 @@ func newLegacyConsolePlugin(info UIPluginInfo, namespace string) *osv1alpha1.Con
 			Service: osv1alpha1.ConsolePluginService{
 				Name:      info.Name,
 				Namespace: namespace,
-				Port:      port,
+				Port:      info.pluginPort(),
 				BasePath:  "/",
 			},
 			Proxy: info.LegacyProxies,
@@ -261,7 +312,7 @@ func newConsolePlugin(info UIPluginInfo, namespace string) *osv1.ConsolePlugin {
 				Service: &osv1.ConsolePluginService{
 					Name:      info.Name,
 					Namespace: namespace,
-					Port:      port,
+					Port:      info.pluginPort(),
 					BasePath:  "/",
 				},
 			},
@@ -273,7 +324,7 @@ func newConsolePlugin(info UIPluginInfo, namespace string) *osv1.ConsolePlugin {
 
 func newDeployment(info UIPluginInfo, namespace string, config *uiv1alpha1.DeploymentConfig) *appsv1.Deployment {
 	pluginArgs := []string{
-		fmt.Sprintf("-port=%d", port),
+		fmt.Sprintf("-port=%d", info.pluginPort()),
 		"-cert=/var/serving-cert/tls.crt",
 		"-key=/var/serving-cert/tls.key",
 	}
@@ -289,6 +340,10 @@ func newDeployment(info UIPluginInfo, namespace string, config *uiv1alpha1.Deplo
 		pluginArgs = append(pluginArgs, fmt.Sprintf("-tls-cipher-suites=%s", strings.Join(info.TLSCiphers, ",")))
 	}
 
+	if info.Args != nil {
+		pluginArgs = info.Args
+	}
+
 	volumes := []corev1.Volume{
 		{
 			Name: servingCertVolumeName,
@@ -328,7 +383,7 @@ func newDeployment(info UIPluginInfo, namespace string, config *uiv1alpha1.Deplo
 		})
 	}
 
-	nodeSelector, tolerations := createNodeSelectorAndTolerations(config)
+	settings := newDeploymentSettings(config)
 
 	plugin := &appsv1.Deployment{
 		TypeMeta: metav1.TypeMeta{
@@ -341,7 +396,7 @@ func newDeployment(info UIPluginInfo, namespace string, config *uiv1alpha1.Deplo
 			Labels:    componentLabels(info.Name),
 		},
 		Spec: appsv1.DeploymentSpec{
-			Replicas: ptr.To(int32(1)),
+			Replicas: ptr.To(settings.replicas),
 			Selector: &metav1.LabelSelector{
 				MatchLabels: componentLabels(info.Name),
 			},
@@ -360,7 +415,7 @@ func newDeployment(info UIPluginInfo, namespace string, config *uiv1alpha1.Deplo
 							Image: info.Image,
 							Ports: []corev1.ContainerPort{
 								{
-									ContainerPort: port,
+									ContainerPort: info.pluginPort(),
 									Name:          "web",
 								},
 							},
@@ -376,13 +431,16 @@ func newDeployment(info UIPluginInfo, namespace string, config *uiv1alpha1.Deplo
 							},
 							VolumeMounts: volumeMounts,
 							Args:         pluginArgs,
+							Resources:    settings.resources,
 						},
 					},
-					Volumes:       volumes,
-					NodeSelector:  nodeSelector,
-					Tolerations:   tolerations,
-					RestartPolicy: "Always",
-					DNSPolicy:     "ClusterFirst",
+					Volumes:                   volumes,
+					NodeSelector:              settings.nodeSelector,
+					Tolerations:               settings.tolerations,
+					TopologySpreadConstraints: settings.topologySpreadConstraints,
+					PriorityClassName:         settings.priorityClassName,
+					RestartPolicy:             "Always",
+					DNSPolicy:                 "ClusterFirst",
 					SecurityContext: &corev1.PodSecurityContext{
 						RunAsNonRoot: ptr.To(true),
 						SeccompProfile: &corev1.SeccompProfile{
@@ -416,6 +474,43 @@ func computeConfigMapHash(cm *corev1.ConfigMap) string {
 	return fmt.Sprintf("%x", h.Sum(nil))
 }
 
+// deploymentSettings are the replicas, resources and scheduling settings of
+// a deployment managed for a plugin.
+type deploymentSettings struct {
+	replicas                  int32
+	resources                 corev1.ResourceRequirements
+	nodeSelector              map[string]string
+	tolerations               []corev1.Toleration
+	topologySpreadConstraints []corev1.TopologySpreadConstraint
+	priorityClassName         string
+}
+
+// newDeploymentSettings returns the settings defined by the deployment
+// configuration of the plugin. The components override them with their own
+// configuration.
+func newDeploymentSettings(config *uiv1alpha1.DeploymentConfig) deploymentSettings {
+	nodeSelector, tolerations := createNodeSelectorAndTolerations(config)
+	settings := deploymentSettings{
+		replicas:     1,
+		nodeSelector: nodeSelector,
+		tolerations:  tolerations,
+	}
+	if config == nil {
+		return settings
+	}
+
+	if config.Replicas != nil {
+		settings.replicas = *config.Replicas
+	}
+	if config.Resources != nil {
+		settings.resources = *config.Resources
+	}
+	settings.topologySpreadConstraints = config.TopologySpreadConstraints
+	settings.priorityClassName = config.PriorityClassName
+
+	return settings
+}
+
 func createNodeSelectorAndTolerations(config *uiv1alpha1.DeploymentConfig) (map[string]string, []corev1.Toleration) {
 	if config == nil {
 		return defaultNodeSelector, nil
@@ -452,10 +547,10 @@ func newService(info UIPluginInfo, namespace string) *corev1.Service {
 		Spec: corev1.ServiceSpec{
 			Ports: []corev1.ServicePort{
 				{
-					Port:       port,
+					Port:       info.pluginPort(),
 					Name:       "http",
 					Protocol:   corev1.ProtocolTCP,
-					TargetPort: intstr.FromInt32(port),
+					TargetPort: intstr.FromInt32(info.pluginPort()),
 				},
 			},
 			Selector: componentLabels(info.Name),
@@ -501,7 +596,7 @@ func componentsHealthClusterRole(name string) *rbacv1.ClusterRole {
 	}
 }
 
-func newKorrel8rDeployment(name string, namespace string, info UIPluginInfo) *appsv1.Deployment {
+func newKorrel8rDeployment(name string, namespace string, info UIPluginInfo, configMap *corev1.ConfigMap) *appsv1.Deployment {
 	volumes := []corev1.Volume{
 		{
 			Name: servingCertVolumeName,
@@ -537,6 +632,47 @@ func newKorrel8rDeployment(name string, namespace string, info UIPluginInfo) *ap
 		MountPath: Korrel8rConfigMountDir,
 	})
 
+	// The deployment is rolled out whenever the configuration or the
+	// custom rules change.
+	configHashes := []string{computeConfigMapHash(configMap)}
+	for i, cm := range info.Korrel8rRules {
+		configHashes = append(configHashes, computeConfigMapHash(cm))
+		volumeName := fmt.Sprintf("korrel8r-rules-%d", i)
+		volumes = append(volumes, corev1.Volume{
+			Name: volumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: cm.Name,
+					},
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      volumeName,
+			ReadOnly:  true,
+			MountPath: fmt.Sprintf("%s/%s", korrel8rRulesMountDir, cm.Name),
+		})
+	}
+
+	for _, store := range info.Korrel8rStores {
+		if store.CAVolume == nil {
+			continue
+		}
+		volumes = append(volumes, *store.CAVolume)
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      store.CAVolume.Name,
+			ReadOnly:  true,
+			MountPath: fmt.Sprintf("%s/%s", korrel8rCAMountDir, store.Domain),
+		})
+	}
+
+	command := []string{"korrel8r", "web", fmt.Sprintf("--https=:%d", port), "--cert=/secrets/tls.crt", "--key=/secrets/tls.key", "--config=/config/korrel8r.yaml"}
+	if cfg := info.Korrel8rConfig; cfg != nil && cfg.Verbosity != nil {
+		command = append(command, fmt.Sprintf("--verbose=%d", *cfg.Verbosity))
+	}
+	settings := korrel8rDeploymentSettings(info)
+
 	deploy := &appsv1.Deployment{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: appsv1.SchemeGroupVersion.String(),
@@ -548,6 +684,7 @@ func newKorrel8rDeployment(name string, namespace string, info UIPluginInfo) *ap
 			Labels:    componentLabels(name),
 		},
 		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To(settings.replicas),
 			Selector: &metav1.LabelSelector{
 				MatchLabels: componentLabels(name),
 			},
@@ -556,14 +693,18 @@ func newKorrel8rDeployment(name string, namespace string, info UIPluginInfo) *ap
 					Name:      name,
 					Namespace: namespace,
 					Labels:    componentLabels(name),
+					Annotations: map[string]string{
+						annotationPrefix + "config-hash": strings.Join(configHashes, "-"),
+					},
 				},
 				Spec: corev1.PodSpec{
 					ServiceAccountName: info.Name + serviceAccountSuffix,
 					Containers: []corev1.Container{
 						{
-							Name:    name,
-							Image:   info.Korrel8rImage,
-							Command: []string{"korrel8r", "web", fmt.Sprintf("--https=:%d", port), "--cert=/secrets/tls.crt", "--key=/secrets/tls.key", "--config=/config/korrel8r.yaml"},
+							Name:      name,
+							Image:     info.Korrel8rImage,
+							Command:   command,
+							Resources: settings.resources,
 							Ports: []corev1.ContainerPort{
 								{
 									ContainerPort: port,
@@ -585,7 +726,11 @@ func newKorrel8rDeployment(name string, namespace string, info UIPluginInfo) *ap
 							VolumeMounts: volumeMounts,
 						},
 					},
-					Volumes: volumes,
+					Volumes:                   volumes,
+					NodeSelector:              settings.nodeSelector,
+					Tolerations:               settings.tolerations,
+					TopologySpreadConstraints: settings.topologySpreadConstraints,
+					PriorityClassName:         settings.priorityClassName,
 				},
 			},
 		},
@@ -593,6 +738,65 @@ func newKorrel8rDeployment(name string, namespace string, info UIPluginInfo) *ap
 	return deploy
 }
 
+// korrel8rDeploymentSettings returns the settings of the plugin deployment
+// overridden by the korrel8r configuration.
+func korrel8rDeploymentSettings(info UIPluginInfo) deploymentSettings {
+	settings := newDeploymentSettings(info.DeploymentConfig)
+
+	cfg := info.Korrel8rConfig
+	if cfg == nil {
+		return settings
+	}
+
+	if cfg.Replicas != nil {
+		settings.replicas = *cfg.Replicas
+	}
+	if cfg.Resources != nil {
+		settings.resources = *cfg.Resources
+	}
+	if cfg.TopologySpreadConstraints != nil {
+		settings.topologySpreadConstraints = cfg.TopologySpreadConstraints
+	}
+
+	return settings
+}
+
+// newPodDisruptionBudget returns a PodDisruptionBudget for the pods matching
+// the selector. At least one pod is kept available unless configured
+// otherwise.
+func newPodDisruptionBudget(name string, namespace string, selector map[string]string, config *uiv1alpha1.DeploymentConfig) *policyv1.PodDisruptionBudget {
+	pdb := &policyv1.PodDisruptionBudget{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: policyv1.SchemeGroupVersion.String(),
+			Kind:       "PodDisruptionBudget",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    componentLabels(name),
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable: ptr.To(intstr.FromInt32(1)),
+			Selector: &metav1.LabelSelector{
+				MatchLabels: selector,
+			},
+		},
+	}
+
+	if config == nil || config.PodDisruptionBudget == nil {
+		return pdb
+	}
+
+	if cfg := config.PodDisruptionBudget; cfg.MaxUnavailable != nil {
+		pdb.Spec.MinAvailable = nil
+		pdb.Spec.MaxUnavailable = cfg.MaxUnavailable
+	} else if cfg.MinAvailable != nil {
+		pdb.Spec.MinAvailable = cfg.MinAvailable
+	}
+
+	return pdb
+}
+
 func newKorrel8rService(name string, namespace string) *corev1.Service {
 	annotations := map[string]string{
 		"service.beta.openshift.io/serving-cert-secret-name": name,
@@ -625,26 +829,18 @@ func newKorrel8rService(name string, namespace string) *corev1.Service {
 }
 
 func newKorrel8rConfigMap(name string, namespace string, info UIPluginInfo) (*corev1.ConfigMap, error) {
-
-	korrel8rData := map[string]string{
-		"Metric":      "thanos-querier",
-		"MetricAlert": "alertmanager-main",
-		"Log":         "logging-loki-gateway-http",
-		"Netflow":     "loki-gateway-http", "Trace": "tempo-platform-gateway",
-		"MonitoringNs": reconciler.OpenshiftMonitoringNamespace,
-		"LoggingNs":    OpenshiftLoggingNs,
-		"NetobservNs":  OpenshiftNetobservNs,
-		"TracingNs":    OpenshiftTracingNs,
+	stores := info.Korrel8rStores
+	if stores == nil {
+		stores = defaultKorrel8rStores(info)
 	}
 
-	if info.LokiServiceNames[OpenshiftLoggingNs] != "" {
-		korrel8rData["Log"] = info.LokiServiceNames[OpenshiftLoggingNs]
-	}
-	if info.LokiServiceNames[OpenshiftNetobservNs] != "" {
-		korrel8rData["Netflow"] = info.LokiServiceNames[OpenshiftNetobservNs]
+	korrel8rData := map[string]any{
+		"Stores":   stores,
+		"Includes": korrel8rIncludes(info.Korrel8rRules),
+		"Timeout":  "",
 	}
-	if info.TempoServiceNames[OpenshiftTracingNs] != "" {
-		korrel8rData["Trace"] = info.TempoServiceNames[OpenshiftTracingNs]
+	if info.Korrel8rConfig != nil {
+		korrel8rData["Timeout"] = info.Korrel8rConfig.Timeout
 	}
 
 	var korrel8rConfigYAMLTmpl = template.Must(template.ParseFS(korrel8rConfigYAMLTmplFile, "config/korrel8r.yaml"))
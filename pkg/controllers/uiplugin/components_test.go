@@ -4,6 +4,12 @@ import (
 	"testing"
 
 	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	uiv1alpha1 "github.com/rhobs/observability-operator/pkg/apis/uiplugin/v1alpha1"
 )
 
 func TestIsVersionAheadOrEqual(t *testing.T) {
@@ -231,3 +237,70 @@ func TestPluginComponentReconcilersTLSProfile(t *testing.T) {
 		})
 	}
 }
+
+func TestDeploymentConfig(t *testing.T) {
+	config := &uiv1alpha1.DeploymentConfig{
+		Replicas: ptr.To(int32(2)),
+		Resources: &corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+		},
+		Tolerations: []corev1.Toleration{{Key: "infra", Effect: corev1.TaintEffectNoSchedule}},
+		TopologySpreadConstraints: []corev1.TopologySpreadConstraint{
+			{MaxSkew: 1, TopologyKey: "kubernetes.io/hostname", WhenUnsatisfiable: corev1.ScheduleAnyway},
+		},
+		PriorityClassName: "system-cluster-critical",
+		PodDisruptionBudget: &uiv1alpha1.PodDisruptionBudgetConfig{
+			MaxUnavailable: ptr.To(intstr.FromString("50%")),
+		},
+	}
+	info := UIPluginInfo{Name: "plugin", DeploymentConfig: config}
+
+	deploy := newDeployment(info, "ns", config)
+	assert.Equal(t, *deploy.Spec.Replicas, int32(2))
+	pod := deploy.Spec.Template.Spec
+	assert.Equal(t, pod.Containers[0].Resources.Requests.Cpu().String(), "100m")
+	assert.DeepEqual(t, pod.NodeSelector, defaultNodeSelector)
+	assert.DeepEqual(t, pod.Tolerations, config.Tolerations)
+	assert.DeepEqual(t, pod.TopologySpreadConstraints, config.TopologySpreadConstraints)
+	assert.Equal(t, pod.PriorityClassName, "system-cluster-critical")
+
+	// The components inherit the settings unless overridden.
+	info.Korrel8rConfig = &uiv1alpha1.Korrel8rConfig{Replicas: ptr.To(int32(3))}
+	settings := korrel8rDeploymentSettings(info)
+	assert.Equal(t, settings.replicas, int32(3))
+	assert.Equal(t, settings.priorityClassName, "system-cluster-critical")
+	assert.DeepEqual(t, settings.topologySpreadConstraints, config.TopologySpreadConstraints)
+
+	info.HealthAnalyzerConfig = &uiv1alpha1.ClusterHealthAnalyzerReference{
+		NodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""},
+	}
+	settings = healthAnalyzerDeploymentSettings(info)
+	// The health analyzer has no leader election: the replicas aren't
+	// inherited.
+	assert.Equal(t, settings.replicas, int32(1))
+	assert.DeepEqual(t, settings.nodeSelector, info.HealthAnalyzerConfig.NodeSelector)
+	assert.Equal(t, settings.resources.Requests.Cpu().String(), "100m")
+
+	// Perses doesn't inherit the replicas.
+	perses := newPerses("ns", info, clusterCapabilities{openShift: true})
+	assert.Assert(t, perses.Spec.Replicas == nil)
+	assert.DeepEqual(t, perses.Spec.Resources, config.Resources)
+	assert.DeepEqual(t, perses.Spec.NodeSelector, defaultNodeSelector)
+	assert.DeepEqual(t, perses.Spec.Tolerations, config.Tolerations)
+
+	// The authorization proxy runs as many replicas as the plugin.
+	assert.Equal(t, *newAuthorizationProxyDeployment(info, "ns").Spec.Replicas, int32(2))
+
+	pdb := newPodDisruptionBudget("plugin", "ns", componentLabels("plugin"), config)
+	assert.Assert(t, pdb.Spec.MinAvailable == nil)
+	assert.Equal(t, pdb.Spec.MaxUnavailable.String(), "50%")
+
+	pdb = newPodDisruptionBudget("perses", "ns", persesPodLabels, nil)
+	assert.Equal(t, pdb.Spec.MinAvailable.IntValue(), 1)
+	assert.DeepEqual(t, pdb.Spec.Selector.MatchLabels, persesPodLabels)
+
+	// Without configuration, a single replica is deployed.
+	deploy = newDeployment(UIPluginInfo{Name: "plugin"}, "ns", nil)
+	assert.Equal(t, *deploy.Spec.Replicas, int32(1))
+	assert.Equal(t, deploy.Spec.Template.Spec.PriorityClassName, "")
+}
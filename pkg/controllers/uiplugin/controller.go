@@ -2,6 +2,7 @@ package uiplugin
 
 import (
 	"context"
+	"fmt"
 	"slices"
 	"time"
 
@@ -10,24 +11,34 @@ import (
 	osv1 "github.com/openshift/api/console/v1"
 	osv1alpha1 "github.com/openshift/api/console/v1alpha1"
 	operatorv1 "github.com/openshift/api/operator/v1"
+	monv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	persesv1alpha2 "github.com/rhobs/perses-operator/api/v1alpha2"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metaerrors "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
+	monv1alpha1 "github.com/rhobs/observability-operator/pkg/apis/monitoring/v1alpha1"
 	uiv1alpha1 "github.com/rhobs/observability-operator/pkg/apis/uiplugin/v1alpha1"
 	"github.com/rhobs/observability-operator/pkg/reconciler"
 )
@@ -41,6 +52,8 @@ type resourceManager struct {
 	pluginConf       UIPluginsConfiguration
 	clusterVersion   string
 	apiReader        client.Reader
+	caps             clusterCapabilities
+	recorder         record.EventRecorder
 }
 
 type UIPluginsConfiguration struct {
@@ -50,7 +63,8 @@ type UIPluginsConfiguration struct {
 }
 
 type Options struct {
-	PluginsConf UIPluginsConfiguration
+	PluginsConf      UIPluginsConfiguration
+	OpenShiftEnabled bool
 }
 
 const (
@@ -59,6 +73,9 @@ const (
 	FailedToReconcileReason = "UIPluginFailedToReconcile"
 	ReconciledMessage       = "Plugin reconciled successfully"
 	NoReason                = "None"
+	// InvalidCompatibilityMatrixReason is the reason of the events reporting
+	// that the compatibility matrix ConfigMap is ignored.
+	InvalidCompatibilityMatrixReason = "InvalidCompatibilityMatrix"
 )
 
 // RBAC for managing UIPlugins
@@ -69,6 +86,13 @@ const (
 //+kubebuilder:rbac:groups=apps,resources=deployments,verbs=list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=list;watch;create;update;delete;patch
 //+kubebuilder:rbac:groups="",resources=serviceaccounts;services;configmaps,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=list;watch;create;update;patch;delete
+
+// RBAC for exposing the backend services in standalone mode
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=backendtlspolicies,verbs=list;watch;create;update;patch;delete
 
 // RBAC for managing Console CRs
 // +kubebuilder:rbac:groups=operator.openshift.io,resources=consoles,verbs=get;patch;list;watch
@@ -92,6 +116,9 @@ const (
 // +kubebuilder:rbac:groups=perses.dev,resources=perses/status;persesdatasources/status;persesglobaldatasources/status;persesdashboards/status,verbs=get;patch;update
 // +kubebuilder:rbac:groups=perses.dev,resources=perses/finalizers;persesglobaldatasources/finalizers;persesdashboards/finalizers;persesdatasources/finalizers;persesdashboards/finalizers,verbs=update
 
+// RBAC for the Loki proxy of the logging plugin
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+
 // RBAC for delegating the use of SCC nonroot-v2 (for OpenShift >= 4.11) and nonroot (for OpenShift < 4.11) for Perses
 //+kubebuilder:rbac:groups="security.openshift.io",resources=securitycontextconstraints,resourceNames=nonroot;nonroot-v2,verbs=use
 
@@ -117,16 +144,49 @@ const (
 //+kubebuilder:rbac:groups=machineconfiguration.openshift.io,resources=machineconfigpools,verbs=get;list
 //+kubebuilder:rbac:groups=kubevirt.io,resources=kubevirts,verbs=get;list
 
-const finalizerName = "uiplugin.observability.openshift.io/finalizer"
+const (
+	finalizerName = "uiplugin.observability.openshift.io/finalizer"
+	// apiSupportAnnotation was used to report the support level of the
+	// plugin before it moved to the status.
+	apiSupportAnnotation = "observability.openshift.io/api-support"
+)
 
 // RegisterWithManager registers the controller with Manager
 func RegisterWithManager(mgr ctrl.Manager, opts Options) error {
 	logger := ctrl.Log.WithName("observability-ui")
 
-	clusterVersion, err := getClusterVersion(mgr.GetAPIReader())
+	// Without OpenShift, there is no cluster version: the most recent
+	// entries of the compatibility matrix apply.
+	version := latestClusterVersion()
+	if opts.OpenShiftEnabled {
+		clusterVersion, err := getClusterVersion(mgr.GetAPIReader())
+		if err != nil {
+			logger.Error(err, "failed to get cluster version")
+			return err
+		}
+		version = clusterVersion.Status.Desired.Version
+	}
+
+	perses, err := hasKind(mgr, persesv1alpha2.GroupVersion.WithKind("PersesDatasource"))
+	if err != nil {
+		return err
+	}
 
+	// The backend services are exposed over HTTPS: HTTPRoutes are only
+	// created along with BackendTLSPolicies.
+	httpRoutes, err := hasKind(mgr, gatewayv1.SchemeGroupVersion.WithKind("HTTPRoute"))
+	if err != nil {
+		return err
+	}
+
+	backendTLSPolicies, err := hasKind(mgr, gatewayv1.SchemeGroupVersion.WithKind("BackendTLSPolicy"))
+	if err != nil {
+		return err
+	}
+	gatewayAPI := httpRoutes && backendTLSPolicies
+
+	serviceMonitors, err := hasKind(mgr, monv1.SchemeGroupVersion.WithKind(monv1.ServiceMonitorsKind))
 	if err != nil {
-		logger.Error(err, "failed to get cluster version")
 		return err
 	}
 
@@ -142,8 +202,15 @@ func RegisterWithManager(mgr ctrl.Manager, opts Options) error {
 		scheme:           mgr.GetScheme(),
 		logger:           logger,
 		pluginConf:       opts.PluginsConf,
-		clusterVersion:   clusterVersion.Status.Desired.Version,
+		clusterVersion:   version,
 		apiReader:        mgr.GetAPIReader(),
+		caps: clusterCapabilities{
+			openShift:       opts.OpenShiftEnabled,
+			perses:          perses,
+			gatewayAPI:      gatewayAPI,
+			serviceMonitors: serviceMonitors,
+		},
+		recorder: mgr.GetEventRecorderFor("observability-operator"),
 	}
 
 	generationChanged := builder.WithPredicates(predicate.GenerationChangedPredicate{})
@@ -155,14 +222,33 @@ func RegisterWithManager(mgr ctrl.Manager, opts Options) error {
 		Owns(&v1.ServiceAccount{}, generationChanged).
 		Owns(&rbacv1.Role{}, generationChanged).
 		Owns(&rbacv1.RoleBinding{}, generationChanged).
-		Owns(&persesv1alpha2.Perses{}, generationChanged).
-		Owns(&persesv1alpha2.PersesDashboard{}, generationChanged).
-		Owns(&persesv1alpha2.PersesDatasource{}, generationChanged).
-		Owns(&persesv1alpha2.PersesGlobalDatasource{}, generationChanged)
+		Owns(&policyv1.PodDisruptionBudget{}, generationChanged).
+		Owns(&networkingv1.Ingress{}, generationChanged).
+		Owns(&networkingv1.NetworkPolicy{}, generationChanged).
+		Watches(&monv1alpha1.MonitoringStack{}, handler.EnqueueRequestsFromMapFunc(rm.findPluginsForMonitoringResource), generationChanged).
+		Watches(&monv1alpha1.ThanosQuerier{}, handler.EnqueueRequestsFromMapFunc(rm.findPluginsForMonitoringResource), generationChanged).
+		Watches(&v1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(rm.findPluginsForConfigMap)).
+		Watches(&v1.Secret{}, handler.EnqueueRequestsFromMapFunc(rm.findPluginsForSecret))
+
+	if perses {
+		ctrlBuilder.
+			Owns(&persesv1alpha2.Perses{}, generationChanged).
+			Owns(&persesv1alpha2.PersesDashboard{}, generationChanged).
+			Owns(&persesv1alpha2.PersesDatasource{}, generationChanged).
+			Owns(&persesv1alpha2.PersesGlobalDatasource{}, generationChanged)
+	}
 
-	if isVersionAheadOrEqual(rm.clusterVersion, "v4.17") {
+	if gatewayAPI {
+		ctrlBuilder.
+			Owns(&gatewayv1.HTTPRoute{}, generationChanged).
+			Owns(&gatewayv1.BackendTLSPolicy{}, generationChanged)
+	}
+
+	switch {
+	case !opts.OpenShiftEnabled:
+	case isVersionAheadOrEqual(rm.clusterVersion, "v4.17"):
 		ctrlBuilder.Owns(&osv1.ConsolePlugin{}, generationChanged)
-	} else {
+	default:
 		ctrlBuilder.Owns(&osv1alpha1.ConsolePlugin{}, generationChanged)
 	}
 
@@ -173,7 +259,21 @@ func RegisterWithManager(mgr ctrl.Manager, opts Options) error {
 	}
 	rm.controller = ctrl
 
-	return nil
+	// The Grafana dashboards are converted to Perses dashboards.
+	if !perses {
+		return nil
+	}
+
+	return registerGrafanaDashboardsWithManager(mgr, opts.PluginsConf.ResourcesNamespace)
+}
+
+// hasKind returns whether the API server serves the given kind.
+func hasKind(mgr ctrl.Manager, gvk schema.GroupVersionKind) (bool, error) {
+	_, err := mgr.GetRESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		return false, nil
+	}
+	return err == nil, err
 }
 
 func getClusterVersion(k8client client.Reader) (*configv1.ClusterVersion, error) {
@@ -186,6 +286,10 @@ func getClusterVersion(k8client client.Reader) (*configv1.ClusterVersion, error)
 }
 
 func (rm resourceManager) consolePluginCapabilityEnabled(ctx context.Context, name types.NamespacedName, clusterVersion string) bool {
+	if !rm.caps.openShift {
+		return false
+	}
+
 	var err error
 
 	if isVersionAheadOrEqual(clusterVersion, "v4.17") {
@@ -202,12 +306,12 @@ func (rm resourceManager) consolePluginCapabilityEnabled(ctx context.Context, na
 func (rm resourceManager) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := rm.logger.WithValues("plugin", req.NamespacedName)
 
-	if !rm.consolePluginCapabilityEnabled(ctx, req.NamespacedName, rm.clusterVersion) {
-		logger.Info("Cluster console plugin not supported or not accessible. Skipping observability UI plugin reconciliation")
-		return ctrl.Result{}, nil
-	}
+	// Without the console, the plugins run in standalone mode: only their
+	// backend services are deployed.
+	caps := rm.caps
+	caps.console = rm.consolePluginCapabilityEnabled(ctx, req.NamespacedName, rm.clusterVersion)
 
-	logger.Info("Reconciling observability UI plugin")
+	logger.Info("Reconciling observability UI plugin", "standalone", !caps.console)
 
 	plugin, err := rm.getUIPlugin(ctx, req)
 	if err != nil {
@@ -222,9 +326,19 @@ func (rm resourceManager) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 
 	// Check if the plugin is being deleted
 	if !plugin.ObjectMeta.DeletionTimestamp.IsZero() {
-		logger.V(6).Info("deregistering plugin from the console")
-		if err := rm.deregisterPluginFromConsole(ctx, pluginTypeToConsoleName[plugin.Spec.Type]); err != nil {
-			return ctrl.Result{}, err
+		if caps.console {
+			// Leave the registration of a console plugin managed by
+			// someone else untouched.
+			uncontrolled, err := rm.findUncontrolledObject(ctx, plugin, rm.newConsolePluginObject(pluginConsoleName(plugin)))
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if uncontrolled == nil {
+				logger.V(6).Info("deregistering plugin from the console")
+				if err := rm.deregisterPluginFromConsole(ctx, pluginConsoleName(plugin)); err != nil {
+					return ctrl.Result{}, err
+				}
+			}
 		}
 
 		// Remove finalizer if present
@@ -255,34 +369,82 @@ func (rm resourceManager) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		}
 	}
 
-	compatibilityInfo, err := lookupImageAndFeatures(plugin.Spec.Type, rm.clusterVersion)
+	if !caps.console && !supportsStandalone(plugin.Spec.Type) {
+		// Nothing can be deployed until the console is available: report it
+		// without retrying.
+		plugin.Status.ConsoleRegistration = uiv1alpha1.ConsoleUnavailable
+		plugin.Status.Components = componentStatuses(plugin, UIPluginInfo{}, caps)
+		return rm.updateStatus(ctx, req, plugin, errStandaloneNotSupported(plugin.Spec.Type)), nil
+	}
+
+	configMapEntries, err := rm.getCompatibilityMatrixEntries(ctx, plugin)
 	if err != nil {
 		return rm.updateStatus(ctx, req, plugin, err), err
 	}
 
-	if plugin.Annotations == nil {
-		plugin.Annotations = map[string]string{}
-		plugin.Annotations["observability.openshift.io/api-support"] = string(compatibilityInfo.SupportLevel)
+	compatibilityInfo, err := lookupCompatibilityEntry(plugin.Spec.Type, rm.clusterVersion, configMapEntries)
+	if err != nil {
+		return rm.updateStatus(ctx, req, plugin, err), err
+	}
+	plugin.Status.CompatibilitySource = compatibilityInfo.Source
+	plugin.Status.SupportLevel = string(compatibilityInfo.SupportLevel)
 
-		if err := rm.k8sClient.Update(ctx, plugin); err != nil {
+	// The support level used to be reported by an annotation: remove it now
+	// that it is part of the status.
+	if _, found := plugin.Annotations[apiSupportAnnotation]; found {
+		patch := client.MergeFrom(plugin.DeepCopy())
+		delete(plugin.Annotations, apiSupportAnnotation)
+		if err := rm.k8sClient.Patch(ctx, plugin, patch); err != nil {
+			if apierrors.IsNotFound(err) {
+				return ctrl.Result{}, nil
+			}
 			return ctrl.Result{}, err
 		}
-		// Upon changes to the uiplugin, reconciliation will happen anyways, so just return early
-		// and let the reconciliation handle the further changes
-		return ctrl.Result{}, nil
-	} else if plugin.Annotations["observability.openshift.io/api-support"] != string(compatibilityInfo.SupportLevel) {
-		plugin.Annotations["observability.openshift.io/api-support"] = string(compatibilityInfo.SupportLevel)
+	}
 
-		if err := rm.k8sClient.Update(ctx, plugin); err != nil {
-			return ctrl.Result{}, err
-		}
-		return ctrl.Result{}, nil
+	// The operator mustn't take over resources which it doesn't manage for
+	// this plugin, in particular those of a custom plugin whose names are
+	// chosen by the user.
+	var objects []client.Object
+	if caps.console {
+		objects = append(objects, rm.newConsolePluginObject(pluginConsoleName(plugin)))
+	}
+	if plugin.Spec.Type == uiv1alpha1.TypeCustom {
+		objects = append(objects, customPluginObjects(plugin, rm.pluginConf.ResourcesNamespace)...)
+	}
+	uncontrolled, err := rm.findUncontrolledObject(ctx, plugin, objects...)
+	if err != nil {
+		return rm.updateStatus(ctx, req, plugin, err), err
+	}
+	if uncontrolled != nil {
+		// The deletion of the conflicting object isn't watched: retry
+		// periodically.
+		rm.updateStatus(ctx, req, plugin, errNotControlled(uncontrolled))
+		return ctrl.Result{RequeueAfter: time.Minute}, nil
 	}
 
 	pluginInfo, pluginInfoErr := PluginInfoBuilder(ctx, rm.k8sClient, rm.k8sDynamicClient, plugin, rm.pluginConf, compatibilityInfo, rm.clusterVersion, rm.logger)
 
 	if pluginInfo != nil {
-		reconcilers := pluginComponentReconcilers(plugin, *pluginInfo, rm.clusterVersion, rm.logger)
+		pluginInfo.ServingCertificateCAs, err = getServingCertificateCAs(ctx, rm.k8sClient, plugin, pluginInfo.ResourceNamespace, caps)
+		if err != nil {
+			return rm.updateStatus(ctx, req, plugin, err), err
+		}
+	}
+
+	if pluginInfo != nil && !caps.console {
+		if err := addExposureAuthorization(pluginInfo, plugin, caps, rm.pluginConf.Images["kube-rbac-proxy"]); err != nil {
+			return rm.updateStatus(ctx, req, plugin, err), err
+		}
+	}
+
+	if pluginInfo != nil {
+		plugin.Status.Image = pluginInfo.Image
+		plugin.Status.Features = pluginInfo.Features
+
+		plugin.Status.Components = componentStatuses(plugin, *pluginInfo, caps)
+
+		reconcilers := pluginComponentReconcilers(plugin, *pluginInfo, rm.clusterVersion, caps, rm.logger)
 		for _, reconciler := range reconcilers {
 			err := reconciler.Reconcile(ctx, rm.k8sClient, rm.scheme)
 			// handle creation / updation errors that can happen due to a stale cache by
@@ -295,11 +457,12 @@ func (rm resourceManager) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 				return rm.updateStatus(ctx, req, plugin, err), err
 			}
 		}
-		if pluginInfo.AreMonitoringFeatsDisabled {
+		if pluginInfo.AreMonitoringFeatsDisabled && caps.console {
 			// prevents double rendering of monitoring console-tabs
-			if err := rm.deregisterPluginFromConsole(ctx, pluginTypeToConsoleName[plugin.Spec.Type]); err != nil {
+			if err := rm.deregisterPluginFromConsole(ctx, pluginConsoleName(plugin)); err != nil {
 				return rm.updateStatus(ctx, req, plugin, err), err
 			}
+			plugin.Status.ConsoleRegistration = uiv1alpha1.ConsoleNotRegistered
 		}
 	}
 
@@ -308,9 +471,15 @@ func (rm resourceManager) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		return rm.updateStatus(ctx, req, plugin, pluginInfoErr), pluginInfoErr
 	}
 
+	if !caps.console {
+		plugin.Status.ConsoleRegistration = uiv1alpha1.ConsoleUnavailable
+		return rm.updateStatus(ctx, req, plugin, nil), nil
+	}
+
 	if err := rm.registerPluginWithConsole(ctx, pluginInfo); err != nil {
 		return rm.updateStatus(ctx, req, plugin, err), err
 	}
+	plugin.Status.ConsoleRegistration = uiv1alpha1.ConsoleRegistered
 
 	return rm.updateStatus(ctx, req, plugin, nil), nil
 }
@@ -343,11 +512,15 @@ func (rm resourceManager) updateStatus(ctx context.Context, req ctrl.Request, pl
 			ObservedGeneration: pl.Generation,
 		}) || changed
 	} else {
+		message := ReconciledMessage
+		if pl.Status.ConsoleRegistration == uiv1alpha1.ConsoleUnavailable {
+			message = standaloneReconciledMessage
+		}
 		changed = meta.SetStatusCondition(&pl.Status.Conditions, metav1.Condition{
 			Type:               string(uiv1alpha1.ReconciledCondition),
 			Status:             metav1.ConditionTrue,
 			Reason:             ReconciledReason,
-			Message:            ReconciledMessage,
+			Message:            message,
 			ObservedGeneration: pl.Generation,
 		}) || changed
 		changed = meta.SetStatusCondition(&pl.Status.Conditions, metav1.Condition{
@@ -365,7 +538,10 @@ func (rm resourceManager) updateStatus(ctx context.Context, req ctrl.Request, pl
 		}) || changed
 	}
 
-	if !changed {
+	// Fields other than the conditions (e.g. the compatibility source) are
+	// set by the caller: compare with the cached object to detect changes.
+	current := &uiv1alpha1.UIPlugin{}
+	if !changed && rm.k8sClient.Get(ctx, req.NamespacedName, current) == nil && equality.Semantic.DeepEqual(current.Status, pl.Status) {
 		return ctrl.Result{}
 	}
 
@@ -378,6 +554,49 @@ func (rm resourceManager) updateStatus(ctx context.Context, req ctrl.Request, pl
 	return ctrl.Result{}
 }
 
+// newConsolePluginObject returns an empty ConsolePlugin of the API version
+// served by the cluster.
+func (rm resourceManager) newConsolePluginObject(name string) client.Object {
+	if isVersionAheadOrEqual(rm.clusterVersion, "v4.17") {
+		return &osv1.ConsolePlugin{
+			TypeMeta:   metav1.TypeMeta{APIVersion: osv1.GroupVersion.String(), Kind: "ConsolePlugin"},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+		}
+	}
+	return &osv1alpha1.ConsolePlugin{
+		TypeMeta:   metav1.TypeMeta{APIVersion: osv1alpha1.GroupVersion.String(), Kind: "ConsolePlugin"},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+	}
+}
+
+// findUncontrolledObject returns the first object which exists but isn't
+// controlled by the UIPlugin, or nil if there is none.
+func (rm resourceManager) findUncontrolledObject(ctx context.Context, plugin *uiv1alpha1.UIPlugin, objects ...client.Object) (client.Object, error) {
+	for _, obj := range objects {
+		current := obj.DeepCopyObject().(client.Object)
+		if err := rm.k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), current); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		if !metav1.IsControlledBy(current, plugin) {
+			return obj, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func errNotControlled(obj client.Object) error {
+	name := obj.GetName()
+	if obj.GetNamespace() != "" {
+		name = obj.GetNamespace() + "/" + name
+	}
+	return fmt.Errorf("%s %s already exists and isn't managed by this UIPlugin", obj.GetObjectKind().GroupVersionKind().Kind, name)
+}
+
 func (rm resourceManager) registerPluginWithConsole(ctx context.Context, pluginInfo *UIPluginInfo) error {
 	cluster := &operatorv1.Console{}
 	if err := rm.apiReader.Get(ctx, client.ObjectKey{Name: "cluster"}, cluster); err != nil {
@@ -424,6 +643,163 @@ func (rm resourceManager) deregisterPluginFromConsole(ctx context.Context, plugi
 	return nil
 }
 
+// findPluginsForMonitoringResource returns the troubleshooting panels whose
+// korrel8r stores and the monitoring plugins whose backends reference the
+// given MonitoringStack or ThanosQuerier.
+func (rm resourceManager) findPluginsForMonitoringResource(ctx context.Context, obj client.Object) []reconcile.Request {
+	plugins := &uiv1alpha1.UIPluginList{}
+	if err := rm.k8sClient.List(ctx, plugins); err != nil {
+		rm.logger.Error(err, "failed to list UIPlugins")
+		return nil
+	}
+
+	matches := func(ref *uiv1alpha1.NamespacedReference) bool {
+		return ref != nil && ref.Name == obj.GetName() && ref.Namespace == obj.GetNamespace()
+	}
+
+	var requests []reconcile.Request
+	for _, plugin := range plugins.Items {
+		var monitoringStacks, thanosQueriers []*uiv1alpha1.NamespacedReference
+		if plugin.Spec.TroubleshootingPanel != nil {
+			for _, store := range plugin.Spec.TroubleshootingPanel.Stores {
+				monitoringStacks = append(monitoringStacks, store.MonitoringStack)
+				thanosQueriers = append(thanosQueriers, store.ThanosQuerier)
+			}
+		}
+		if plugin.Spec.Monitoring != nil {
+			backends := []*uiv1alpha1.MonitoringBackendsReference{plugin.Spec.Monitoring.Backends}
+			if plugin.Spec.Monitoring.ClusterHealthAnalyzer != nil {
+				backends = append(backends, plugin.Spec.Monitoring.ClusterHealthAnalyzer.Backends)
+			}
+			for _, b := range backends {
+				if b != nil {
+					monitoringStacks = append(monitoringStacks, b.MonitoringStack)
+					thanosQueriers = append(thanosQueriers, b.ThanosQuerier)
+				}
+			}
+		}
+
+		var refs []*uiv1alpha1.NamespacedReference
+		switch obj.(type) {
+		case *monv1alpha1.MonitoringStack:
+			refs = monitoringStacks
+		case *monv1alpha1.ThanosQuerier:
+			refs = thanosQueriers
+		}
+
+		if slices.ContainsFunc(refs, matches) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: plugin.Name}})
+		}
+	}
+
+	return requests
+}
+
+// getCompatibilityMatrixEntries returns the compatibility matrix entries
+// defined in the compatibility matrix ConfigMap, if it exists. An invalid
+// ConfigMap mustn't break all the plugins: it is ignored, in favor of the
+// built-in matrix, and reported with an event on the plugin.
+func (rm resourceManager) getCompatibilityMatrixEntries(ctx context.Context, plugin *uiv1alpha1.UIPlugin) ([]CompatibilityEntry, error) {
+	cm := &v1.ConfigMap{}
+	err := rm.k8sClient.Get(ctx, types.NamespacedName{Name: CompatibilityMatrixConfigMapName, Namespace: rm.pluginConf.ResourcesNamespace}, cm)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := parseCompatibilityMatrix(cm, rm.pluginConf.Images)
+	if err != nil {
+		rm.logger.Info("Ignoring the invalid compatibility matrix", "err", err)
+		rm.recorder.Eventf(plugin, v1.EventTypeWarning, InvalidCompatibilityMatrixReason, "using the built-in compatibility matrix: %s", err)
+		return nil, nil
+	}
+
+	return entries, nil
+}
+
+// findPluginsForConfigMap returns the plugins which depend on the given
+// ConfigMap: all of them for the compatibility matrix and the
+// troubleshooting panels and health analyzers referencing it otherwise.
+func (rm resourceManager) findPluginsForConfigMap(ctx context.Context, obj client.Object) []reconcile.Request {
+	if obj.GetNamespace() != rm.pluginConf.ResourcesNamespace {
+		return nil
+	}
+
+	plugins := &uiv1alpha1.UIPluginList{}
+	if err := rm.k8sClient.List(ctx, plugins); err != nil {
+		rm.logger.Error(err, "failed to list UIPlugins")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, plugin := range plugins.Items {
+		if obj.GetName() == CompatibilityMatrixConfigMapName {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: plugin.Name}})
+			continue
+		}
+
+		if plugin.Spec.TroubleshootingPanel != nil && slices.ContainsFunc(plugin.Spec.TroubleshootingPanel.Rules, func(ref uiv1alpha1.Korrel8rRulesReference) bool {
+			return ref.Name == obj.GetName()
+		}) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: plugin.Name}})
+			continue
+		}
+
+		// The CA certificate of the service CA is copied for the
+		// BackendTLSPolicies.
+		if obj.GetName() == serviceCAConfigMapName && plugin.Spec.Exposure != nil {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: plugin.Name}})
+			continue
+		}
+
+		if plugin.Spec.Monitoring != nil && plugin.Spec.Monitoring.ClusterHealthAnalyzer != nil && slices.ContainsFunc(plugin.Spec.Monitoring.ClusterHealthAnalyzer.Components, func(ref uiv1alpha1.HealthAnalyzerComponentsReference) bool {
+			return ref.Name == obj.GetName()
+		}) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: plugin.Name}})
+		}
+	}
+
+	return requests
+}
+
+// findPluginsForSecret returns the plugins whose Loki or Perses database
+// configuration references the given secret.
+func (rm resourceManager) findPluginsForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	if obj.GetNamespace() != rm.pluginConf.ResourcesNamespace {
+		return nil
+	}
+
+	plugins := &uiv1alpha1.UIPluginList{}
+	if err := rm.k8sClient.List(ctx, plugins); err != nil {
+		rm.logger.Error(err, "failed to list UIPlugins")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, plugin := range plugins.Items {
+		var names []string
+		if plugin.Spec.Logging != nil && plugin.Spec.Logging.Loki != nil {
+			names = lokiSecretNames(plugin.Spec.Logging.Loki)
+		}
+		if m := plugin.Spec.Monitoring; m != nil && m.Perses != nil && m.Perses.Storage != nil && m.Perses.Storage.SQL != nil {
+			names = append(names, m.Perses.Storage.SQL.Secret)
+		}
+		// Outside of OpenShift, the serving certificates are provided by
+		// the user.
+		if !rm.caps.openShift {
+			names = append(names, servingCertificateSecrets(&plugin)...)
+		}
+
+		if slices.Contains(names, obj.GetName()) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: plugin.Name}})
+		}
+	}
+
+	return requests
+}
+
 func (rm resourceManager) getUIPlugin(ctx context.Context, req ctrl.Request) (*uiv1alpha1.UIPlugin, error) {
 	logger := rm.logger.WithValues("plugin", req.NamespacedName)
 
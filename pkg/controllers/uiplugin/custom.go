@@ -0,0 +1,201 @@
+package uiplugin
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	osv1 "github.com/openshift/api/console/v1"
+	osv1alpha1 "github.com/openshift/api/console/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	uiv1alpha1 "github.com/rhobs/observability-operator/pkg/apis/uiplugin/v1alpha1"
+)
+
+// customPluginPrefix is prepended to the name of the resources created for a
+// custom plugin so that they can't collide with the resources of the operator
+// and of the built-in plugins which live in the same namespace.
+const customPluginPrefix = "custom-"
+
+func customPluginName(pluginName string) string {
+	return customPluginPrefix + pluginName
+}
+
+func createCustomPluginInfo(plugin *uiv1alpha1.UIPlugin, namespace, name string) (*UIPluginInfo, error) {
+	config := plugin.Spec.Custom
+	if config == nil {
+		return nil, fmt.Errorf("custom configuration can not be empty for plugin type %s", plugin.Spec.Type)
+	}
+
+	if config.Image == "" {
+		return nil, fmt.Errorf("custom plugin image can not be empty")
+	}
+
+	if err := validateCustomPluginRules(config.Rules); err != nil {
+		return nil, err
+	}
+
+	displayName := config.DisplayName
+	if displayName == "" {
+		displayName = plugin.Name
+	}
+
+	// The arguments of the plugin image are unknown: they replace the
+	// default arguments of the built-in plugins.
+	args := []string{}
+	args = append(args, config.Args...)
+
+	pluginInfo := &UIPluginInfo{
+		Image:             config.Image,
+		Name:              name,
+		ConsoleName:       name,
+		DisplayName:       displayName,
+		Port:              config.Port,
+		Args:              args,
+		ResourceNamespace: namespace,
+	}
+
+	for _, proxy := range config.Proxies {
+		authorization := osv1.None
+		if proxy.Authorize {
+			authorization = osv1.UserToken
+		}
+
+		pluginInfo.LegacyProxies = append(pluginInfo.LegacyProxies, osv1alpha1.ConsolePluginProxy{
+			Type:          osv1alpha1.ProxyTypeService,
+			Alias:         proxy.Alias,
+			Authorize:     proxy.Authorize,
+			CACertificate: proxy.CACertificate,
+			Service: osv1alpha1.ConsolePluginProxyServiceConfig{
+				Name:      proxy.Service.Name,
+				Namespace: proxy.Service.Namespace,
+				Port:      proxy.Service.Port,
+			},
+		})
+		pluginInfo.Proxies = append(pluginInfo.Proxies, osv1.ConsolePluginProxy{
+			Alias:         proxy.Alias,
+			Authorization: authorization,
+			CACertificate: proxy.CACertificate,
+			Endpoint: osv1.ConsolePluginProxyEndpoint{
+				Type: osv1.ProxyTypeService,
+				Service: &osv1.ConsolePluginProxyServiceConfig{
+					Name:      proxy.Service.Name,
+					Namespace: proxy.Service.Namespace,
+					Port:      proxy.Service.Port,
+				},
+			},
+		})
+	}
+
+	// The rules are only granted in the namespace of the plugin: anyone
+	// allowed to create a UIPlugin could otherwise obtain any cluster-wide
+	// permission held by the operator.
+	pluginInfo.CustomRules = config.Rules
+
+	if len(config.Config) > 0 {
+		pluginInfo.ConfigMap = newCustomPluginConfigMap(name, namespace, config.Config)
+	}
+
+	return pluginInfo, nil
+}
+
+// validateCustomPluginRules rejects the rules which would give the plugin
+// access to the secrets of the operator namespace, including the token of
+// the operator service account, or allow it to escalate its permissions.
+func validateCustomPluginRules(rules []rbacv1.PolicyRule) error {
+	for _, rule := range rules {
+		for _, verb := range rule.Verbs {
+			if verb == rbacv1.VerbAll || slices.Contains([]string{"bind", "escalate", "impersonate"}, verb) {
+				return fmt.Errorf("custom plugin rules can not grant the %q verb", verb)
+			}
+		}
+
+		if slices.Contains(rule.APIGroups, rbacv1.APIGroupAll) {
+			return fmt.Errorf("custom plugin rules can not grant access to all API groups")
+		}
+
+		for _, resource := range rule.Resources {
+			// Subresources are granted as "<resource>/<subresource>".
+			r, _, _ := strings.Cut(resource, "/")
+			if r == rbacv1.ResourceAll || r == "secrets" {
+				return fmt.Errorf("custom plugin rules can not grant access to the %q resource", resource)
+			}
+		}
+	}
+
+	return nil
+}
+
+func newCustomPluginConfigMap(name, namespace string, data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Data: data,
+	}
+}
+
+// customPluginObjects returns the objects which the operator creates for a
+// custom plugin, regardless of its configuration. Their names are derived
+// from the name of the UIPlugin which is chosen by the user: the operator
+// must not modify or delete them unless they are controlled by the UIPlugin.
+func customPluginObjects(plugin *uiv1alpha1.UIPlugin, namespace string) []client.Object {
+	info := UIPluginInfo{Name: customPluginName(plugin.Name), ResourceNamespace: namespace}
+	return []client.Object{
+		newServiceAccount(info.Name, namespace),
+		newDeployment(info, namespace, nil),
+		newService(info, namespace),
+		newCustomPluginConfigMap(info.Name, namespace, nil),
+		newCustomPluginRole(info, namespace),
+		newCustomPluginRoleBinding(info, namespace),
+	}
+}
+
+func newCustomPluginRole(info UIPluginInfo, namespace string) *rbacv1.Role {
+	return &rbacv1.Role{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: rbacv1.SchemeGroupVersion.String(),
+			Kind:       "Role",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      info.Name,
+			Namespace: namespace,
+		},
+		Rules: info.CustomRules,
+	}
+}
+
+func newCustomPluginRoleBinding(info UIPluginInfo, namespace string) *rbacv1.RoleBinding {
+	name := info.Name
+	return &rbacv1.RoleBinding{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: rbacv1.SchemeGroupVersion.String(),
+			Kind:       "RoleBinding",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				APIGroup:  corev1.SchemeGroupVersion.Group,
+				Kind:      "ServiceAccount",
+				Name:      info.Name + serviceAccountSuffix,
+				Namespace: namespace,
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.SchemeGroupVersion.Group,
+			Kind:     "Role",
+			Name:     name,
+		},
+	}
+}
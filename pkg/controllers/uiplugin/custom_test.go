@@ -0,0 +1,186 @@
+package uiplugin
+
+import (
+	"context"
+	"testing"
+
+	osv1 "github.com/openshift/api/console/v1"
+	"gotest.tools/v3/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	uiv1alpha1 "github.com/rhobs/observability-operator/pkg/apis/uiplugin/v1alpha1"
+)
+
+func TestCreateCustomPluginInfo(t *testing.T) {
+	plugin := &uiv1alpha1.UIPlugin{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-plugin"},
+		Spec: uiv1alpha1.UIPluginSpec{
+			Type: uiv1alpha1.TypeCustom,
+			Custom: &uiv1alpha1.CustomPluginConfig{
+				Image: "quay.io/example/my-plugin:v1",
+				Port:  8443,
+				Args:  []string{"--ssl"},
+				Proxies: []uiv1alpha1.CustomPluginProxy{
+					{
+						Alias:     "backend",
+						Service:   uiv1alpha1.CustomPluginProxyService{Name: "backend", Namespace: "team-a", Port: 8080},
+						Authorize: true,
+					},
+				},
+				Rules: []rbacv1.PolicyRule{
+					{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}},
+				},
+				Config: map[string]string{"config.yaml": "key: value"},
+			},
+		},
+	}
+
+	info, err := createCustomPluginInfo(plugin, "ns", customPluginName(plugin.Name))
+	assert.NilError(t, err)
+	assert.Equal(t, info.ConsoleName, "custom-my-plugin")
+	assert.Equal(t, info.DisplayName, "my-plugin")
+	assert.Equal(t, info.Proxies[0].Authorization, osv1.UserToken)
+	assert.Equal(t, info.Proxies[0].Endpoint.Service.Port, int32(8080))
+	assert.Equal(t, info.LegacyProxies[0].Authorize, true)
+	assert.Equal(t, len(info.ClusterRoles), 0)
+	role, roleBinding := newCustomPluginRole(*info, "ns"), newCustomPluginRoleBinding(*info, "ns")
+	assert.Equal(t, role.Name, "custom-my-plugin")
+	assert.Equal(t, role.Namespace, "ns")
+	assert.DeepEqual(t, role.Rules, plugin.Spec.Custom.Rules)
+	assert.Equal(t, roleBinding.Subjects[0].Name, "custom-my-plugin-sa")
+	assert.Equal(t, roleBinding.RoleRef.Kind, "Role")
+	assert.Equal(t, roleBinding.RoleRef.Name, role.Name)
+	assert.Equal(t, info.ConfigMap.Data["config.yaml"], "key: value")
+
+	deployment := newDeployment(*info, "ns", nil)
+	container := deployment.Spec.Template.Spec.Containers[0]
+	assert.DeepEqual(t, container.Args, []string{"--ssl"})
+	assert.Equal(t, container.Ports[0].ContainerPort, int32(8443))
+	assert.Equal(t, newService(*info, "ns").Spec.Ports[0].TargetPort.IntVal, int32(8443))
+	assert.Equal(t, newConsolePlugin(*info, "ns").Spec.Backend.Service.Port, int32(8443))
+
+	// Without arguments, the default arguments of the built-in plugins aren't used.
+	plugin.Spec.Custom.Args = nil
+	info, err = createCustomPluginInfo(plugin, "ns", customPluginName(plugin.Name))
+	assert.NilError(t, err)
+	deployment = newDeployment(*info, "ns", nil)
+	assert.Equal(t, len(deployment.Spec.Template.Spec.Containers[0].Args), 0)
+
+	plugin.Spec.Custom = nil
+	_, err = createCustomPluginInfo(plugin, "ns", customPluginName(plugin.Name))
+	assert.ErrorContains(t, err, "custom configuration can not be empty")
+}
+
+func TestValidateCustomPluginRules(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		rule rbacv1.PolicyRule
+		err  string
+	}{
+		{
+			name: "valid",
+			rule: rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods", "pods/log"}, Verbs: []string{"get"}},
+		},
+		{
+			name: "secrets",
+			rule: rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}},
+			err:  `the "secrets" resource`,
+		},
+		{
+			name: "all resources",
+			rule: rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"*"}, Verbs: []string{"get"}},
+			err:  `the "*" resource`,
+		},
+		{
+			name: "all subresources",
+			rule: rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"*/status"}, Verbs: []string{"get"}},
+			err:  `the "*/status" resource`,
+		},
+		{
+			name: "all API groups",
+			rule: rbacv1.PolicyRule{APIGroups: []string{"*"}, Resources: []string{"pods"}, Verbs: []string{"get"}},
+			err:  "all API groups",
+		},
+		{
+			name: "all verbs",
+			rule: rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"*"}},
+			err:  `the "*" verb`,
+		},
+		{
+			name: "escalate",
+			rule: rbacv1.PolicyRule{APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: []string{"roles"}, Verbs: []string{"escalate"}},
+			err:  `the "escalate" verb`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateCustomPluginRules([]rbacv1.PolicyRule{tc.rule})
+			if tc.err == "" {
+				assert.NilError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestFindUncontrolledObject(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NilError(t, osv1.AddToScheme(scheme))
+	assert.NilError(t, appsv1.AddToScheme(scheme))
+	assert.NilError(t, corev1.AddToScheme(scheme))
+	assert.NilError(t, rbacv1.AddToScheme(scheme))
+
+	plugin := &uiv1alpha1.UIPlugin{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-plugin", UID: "uid"},
+		Spec:       uiv1alpha1.UIPluginSpec{Type: uiv1alpha1.TypeCustom},
+	}
+	controlled := []metav1.OwnerReference{{Name: "my-plugin", UID: "uid", Controller: ptr.To(true)}}
+
+	rm := resourceManager{
+		k8sClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&osv1.ConsolePlugin{ObjectMeta: metav1.ObjectMeta{Name: "custom-my-plugin", OwnerReferences: controlled}},
+			&osv1.ConsolePlugin{ObjectMeta: metav1.ObjectMeta{Name: "monitoring-plugin"}},
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "custom-my-plugin", Namespace: "ns", OwnerReferences: controlled}},
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "custom-other-plugin", Namespace: "ns"}},
+		).Build(),
+		clusterVersion: "v4.18",
+	}
+
+	for _, tc := range []struct {
+		name     string
+		objects  []client.Object
+		expected string
+	}{
+		{
+			name:    "controlled or missing",
+			objects: append([]client.Object{rm.newConsolePluginObject("custom-my-plugin")}, customPluginObjects(plugin, "ns")...),
+		},
+		{
+			name:     "foreign console plugin",
+			objects:  []client.Object{rm.newConsolePluginObject("monitoring-plugin")},
+			expected: "ConsolePlugin monitoring-plugin already exists and isn't managed by this UIPlugin",
+		},
+		{
+			name:     "foreign deployment",
+			objects:  customPluginObjects(&uiv1alpha1.UIPlugin{ObjectMeta: metav1.ObjectMeta{Name: "other-plugin"}}, "ns"),
+			expected: "Deployment ns/custom-other-plugin already exists and isn't managed by this UIPlugin",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			obj, err := rm.findUncontrolledObject(context.Background(), plugin, tc.objects...)
+			assert.NilError(t, err)
+			if tc.expected == "" {
+				assert.Assert(t, obj == nil)
+				return
+			}
+			assert.Error(t, errNotControlled(obj), tc.expected)
+		})
+	}
+}
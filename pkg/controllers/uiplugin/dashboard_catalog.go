@@ -0,0 +1,94 @@
+package uiplugin
+
+import (
+	"embed"
+	"fmt"
+
+	persesv1alpha2 "github.com/rhobs/perses-operator/api/v1alpha2"
+	persesv1 "github.com/rhobs/perses/pkg/model/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	uiv1alpha1 "github.com/rhobs/observability-operator/pkg/apis/uiplugin/v1alpha1"
+)
+
+var (
+	//go:embed config/dashboards/*.yaml
+	catalogDashboardsFS embed.FS
+)
+
+// catalogDashboard is a dashboard shipped by the operator when Perses is
+// enabled on the monitoring plugin.
+type catalogDashboard struct {
+	name             uiv1alpha1.PersesCatalogDashboardName
+	enabledByDefault bool
+	// build returns the dashboard deployed in the namespace. The Perses
+	// configuration may be nil.
+	build func(namespace string, cfg *uiv1alpha1.PersesReference) (*persesv1alpha2.PersesDashboard, error)
+}
+
+// dashboardCatalog lists the dashboards shipped by the operator. Dashboards
+// which aren't built in Go are read from config/dashboards/<name>.yaml.
+var dashboardCatalog = []catalogDashboard{
+	{name: uiv1alpha1.AcceleratorsDashboard, enabledByDefault: true, build: newAcceleratorsDashboard},
+	{name: uiv1alpha1.APMDashboard, enabledByDefault: true, build: newAPMDashboard},
+	{name: uiv1alpha1.KubernetesWorkloadsDashboard, build: declarativeDashboard(uiv1alpha1.KubernetesWorkloadsDashboard)},
+	{name: uiv1alpha1.MonitoringStackDashboard, build: declarativeDashboard(uiv1alpha1.MonitoringStackDashboard)},
+	{name: uiv1alpha1.ThanosDashboard, build: declarativeDashboard(uiv1alpha1.ThanosDashboard)},
+	{name: uiv1alpha1.OpenTelemetryCollectorDashboard, build: declarativeDashboard(uiv1alpha1.OpenTelemetryCollectorDashboard)},
+	{name: uiv1alpha1.TempoDashboard, build: declarativeDashboard(uiv1alpha1.TempoDashboard)},
+}
+
+// isEnabled returns whether the dashboard is enabled by the Perses
+// configuration, falling back to its default state.
+func (d catalogDashboard) isEnabled(cfg *uiv1alpha1.PersesReference) bool {
+	if cfg == nil {
+		return d.enabledByDefault
+	}
+
+	for _, dashboard := range cfg.Dashboards {
+		if dashboard.Name == d.name {
+			return dashboard.Enabled
+		}
+	}
+
+	return d.enabledByDefault
+}
+
+func declarativeDashboard(name uiv1alpha1.PersesCatalogDashboardName) func(string, *uiv1alpha1.PersesReference) (*persesv1alpha2.PersesDashboard, error) {
+	return func(namespace string, _ *uiv1alpha1.PersesReference) (*persesv1alpha2.PersesDashboard, error) {
+		data, err := catalogDashboardsFS.ReadFile(fmt.Sprintf("config/dashboards/%s.yaml", name))
+		if err != nil {
+			return nil, err
+		}
+
+		bytes, err := yaml.YAMLToJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s dashboard: %w", name, err)
+		}
+
+		rhobsDashboard := persesv1.Dashboard{}
+		if err := rhobsDashboard.UnmarshalJSON(bytes); err != nil {
+			return nil, fmt.Errorf("invalid %s dashboard: %w", name, err)
+		}
+
+		return &persesv1alpha2.PersesDashboard{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: persesv1alpha2.GroupVersion.String(),
+				Kind:       "PersesDashboard",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      string(name) + "-dashboard",
+				Namespace: namespace,
+				Labels: map[string]string{
+					"app.kubernetes.io/managed-by": "observability-operator",
+				},
+			},
+			Spec: persesv1alpha2.PersesDashboardSpec{
+				Config: persesv1alpha2.Dashboard{
+					Spec: rhobsDashboard.Spec,
+				},
+			},
+		}, nil
+	}
+}
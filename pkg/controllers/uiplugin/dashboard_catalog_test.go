@@ -0,0 +1,61 @@
+package uiplugin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/prometheus/promql/parser"
+	"gotest.tools/v3/assert"
+
+	uiv1alpha1 "github.com/rhobs/observability-operator/pkg/apis/uiplugin/v1alpha1"
+)
+
+func TestDashboardCatalog(t *testing.T) {
+	names := map[string]struct{}{}
+	for _, d := range dashboardCatalog {
+		t.Run(string(d.name), func(t *testing.T) {
+			dashboard, err := d.build("ns", nil)
+			assert.NilError(t, err)
+			assert.Equal(t, dashboard.Namespace, "ns")
+			assert.Assert(t, len(dashboard.Spec.Config.Spec.Panels) > 0)
+			assert.Assert(t, len(dashboard.Spec.Config.Spec.Layouts) > 0)
+
+			for key, panel := range dashboard.Spec.Config.Spec.Panels {
+				for _, q := range panel.Spec.Queries {
+					spec, ok := q.Spec.Plugin.Spec.(map[string]any)
+					assert.Assert(t, ok, "panel %q", key)
+					query, _ := spec["query"].(string)
+					_, err := parser.ParseExpr(strings.ReplaceAll(query, "$__rate_interval", "5m"))
+					assert.NilError(t, err, "panel %q", key)
+				}
+			}
+
+			_, found := names[dashboard.Name]
+			assert.Assert(t, !found, "duplicate dashboard name %q", dashboard.Name)
+			names[dashboard.Name] = struct{}{}
+		})
+	}
+}
+
+func TestCatalogDashboardIsEnabled(t *testing.T) {
+	apm := catalogDashboard{name: uiv1alpha1.APMDashboard, enabledByDefault: true}
+	tempo := catalogDashboard{name: uiv1alpha1.TempoDashboard}
+
+	assert.Assert(t, apm.isEnabled(nil))
+	assert.Assert(t, !tempo.isEnabled(nil))
+
+	cfg := &uiv1alpha1.PersesReference{
+		Enabled: true,
+		Dashboards: []uiv1alpha1.PersesCatalogDashboard{
+			{Name: uiv1alpha1.APMDashboard, Enabled: false},
+			{Name: uiv1alpha1.TempoDashboard, Enabled: true},
+		},
+	}
+	assert.Assert(t, !apm.isEnabled(cfg))
+	assert.Assert(t, tempo.isEnabled(cfg))
+
+	// Dashboards which aren't listed keep their default state.
+	cfg.Dashboards = nil
+	assert.Assert(t, apm.isEnabled(cfg))
+	assert.Assert(t, !tempo.isEnabled(cfg))
+}
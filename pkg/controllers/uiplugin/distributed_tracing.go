@@ -37,6 +37,7 @@ func createDistributedTracingPluginInfo(plugin *uiv1alpha1.UIPlugin, namespace,
 		ConsoleName:       pluginTypeToConsoleName[plugin.Spec.Type],
 		DisplayName:       "Distributed Tracing Console Plugin",
 		ResourceNamespace: namespace,
+		Features:          features,
 		ExtraArgs:         extraArgs,
 		LegacyProxies: []osv1alpha1.ConsolePluginProxy{
 			{
@@ -124,19 +125,44 @@ func createDistributedTracingPluginInfo(plugin *uiv1alpha1.UIPlugin, namespace,
 	return pluginInfo, nil
 }
 
+type distributedTracingConfig struct {
+	Timeout          string                   `yaml:"timeout,omitempty"`
+	QueryLimit       int32                    `yaml:"queryLimit,omitempty"`
+	DefaultTimeRange string                   `yaml:"defaultTimeRange,omitempty"`
+	Namespaces       []string                 `yaml:"namespaces,omitempty"`
+	Instances        []tempoInstanceReference `yaml:"instances,omitempty"`
+}
+
+type tempoInstanceReference struct {
+	Kind      string   `yaml:"kind"`
+	Namespace string   `yaml:"namespace"`
+	Name      string   `yaml:"name"`
+	Tenants   []string `yaml:"tenants,omitempty"`
+}
+
 func marshalDistributedTracingPluginConfig(cfg *uiv1alpha1.DistributedTracingConfig) (string, error) {
 	if cfg == nil {
 		return "", nil
 	}
 
-	if cfg.Timeout == "" {
+	if cfg.Timeout == "" && cfg.QueryLimit == 0 && cfg.DefaultTimeRange == "" && len(cfg.Namespaces) == 0 && len(cfg.Instances) == 0 {
 		return "", nil
 	}
 
-	pluginCfg := struct {
-		Timeout string `yaml:"timeout"`
-	}{
-		Timeout: cfg.Timeout,
+	pluginCfg := distributedTracingConfig{
+		Timeout:          cfg.Timeout,
+		QueryLimit:       cfg.QueryLimit,
+		DefaultTimeRange: cfg.DefaultTimeRange,
+		Namespaces:       cfg.Namespaces,
+	}
+
+	for _, instance := range cfg.Instances {
+		pluginCfg.Instances = append(pluginCfg.Instances, tempoInstanceReference{
+			Kind:      instance.Kind,
+			Namespace: instance.Namespace,
+			Name:      instance.Name,
+			Tenants:   instance.Tenants,
+		})
 	}
 
 	buf := &bytes.Buffer{}
@@ -0,0 +1,70 @@
+package uiplugin
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	uiv1alpha1 "github.com/rhobs/observability-operator/pkg/apis/uiplugin/v1alpha1"
+)
+
+func TestMarshalDistributedTracingPluginConfig(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		cfg      *uiv1alpha1.DistributedTracingConfig
+		expected string
+	}{
+		{
+			name:     "no configuration",
+			cfg:      nil,
+			expected: "",
+		},
+		{
+			name:     "timeout only",
+			cfg:      &uiv1alpha1.DistributedTracingConfig{Timeout: "30s"},
+			expected: "timeout: 30s\n",
+		},
+		{
+			name: "instances and defaults",
+			cfg: &uiv1alpha1.DistributedTracingConfig{
+				QueryLimit:       50,
+				DefaultTimeRange: "1h",
+				Namespaces:       []string{"tracing"},
+				Instances: []uiv1alpha1.TempoInstanceReference{
+					{Kind: "TempoStack", Name: "prod", Namespace: "tracing", Tenants: []string{"dev", "prod"}},
+					{Kind: "TempoMonolithic", Name: "simple", Namespace: "tracing"},
+				},
+			},
+			expected: `queryLimit: 50
+defaultTimeRange: 1h
+namespaces:
+    - tracing
+instances:
+    - kind: TempoStack
+      namespace: tracing
+      name: prod
+      tenants:
+        - dev
+        - prod
+    - kind: TempoMonolithic
+      namespace: tracing
+      name: simple
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			config, err := marshalDistributedTracingPluginConfig(tc.cfg)
+			assert.NilError(t, err)
+			assert.Equal(t, config, tc.expected)
+		})
+	}
+}
+
+func TestDistributedTracingPluginInfoFeatures(t *testing.T) {
+	plugin := &uiv1alpha1.UIPlugin{Spec: uiv1alpha1.UIPluginSpec{Type: uiv1alpha1.TypeDistributedTracing}}
+
+	info, err := createDistributedTracingPluginInfo(plugin, "ns", "distributed-tracing", "image", []string{"dev-console"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, info.Features, []string{"dev-console"})
+	assert.Assert(t, containsArg(info.ExtraArgs, "-features=dev-console"))
+}
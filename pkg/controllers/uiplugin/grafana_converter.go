@@ -0,0 +1,580 @@
+package uiplugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	persesv1 "github.com/rhobs/perses/pkg/model/api/v1"
+)
+
+// grafanaDashboard is the subset of the Grafana dashboard model which is
+// converted to Perses.
+type grafanaDashboard struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Panels      []grafanaPanel `json:"panels"`
+	Rows        []any          `json:"rows"`
+	Templating  struct {
+		List []grafanaVariable `json:"list"`
+	} `json:"templating"`
+	Time struct {
+		From string `json:"from"`
+	} `json:"time"`
+	Refresh any `json:"refresh"`
+}
+
+type grafanaPanel struct {
+	ID          int                `json:"id"`
+	Type        string             `json:"type"`
+	Title       string             `json:"title"`
+	Description string             `json:"description"`
+	Collapsed   bool               `json:"collapsed"`
+	GridPos     grafanaGridPos     `json:"gridPos"`
+	Datasource  *grafanaDatasource `json:"datasource"`
+	Targets     []grafanaTarget    `json:"targets"`
+	Panels      []grafanaPanel     `json:"panels"`
+	FieldConfig struct {
+		Defaults struct {
+			Unit     string   `json:"unit"`
+			Decimals *int     `json:"decimals"`
+			Min      *float64 `json:"min"`
+			Max      *float64 `json:"max"`
+		} `json:"defaults"`
+	} `json:"fieldConfig"`
+	Options struct {
+		Content string `json:"content"`
+	} `json:"options"`
+}
+
+type grafanaGridPos struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type grafanaTarget struct {
+	RefID        string             `json:"refId"`
+	Expr         string             `json:"expr"`
+	LegendFormat string             `json:"legendFormat"`
+	Hide         bool               `json:"hide"`
+	Datasource   *grafanaDatasource `json:"datasource"`
+}
+
+type grafanaVariable struct {
+	Name       string             `json:"name"`
+	Label      string             `json:"label"`
+	Type       string             `json:"type"`
+	Query      any                `json:"query"`
+	Regex      string             `json:"regex"`
+	Multi      bool               `json:"multi"`
+	IncludeAll bool               `json:"includeAll"`
+	AllValue   string             `json:"allValue"`
+	Hide       int                `json:"hide"`
+	Datasource *grafanaDatasource `json:"datasource"`
+	Current    struct {
+		Value any `json:"value"`
+	} `json:"current"`
+}
+
+// grafanaDatasource is either a datasource name or a reference with a type
+// and a uid.
+type grafanaDatasource struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+func (d *grafanaDatasource) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		d.UID = name
+		return nil
+	}
+
+	type plain grafanaDatasource
+	return json.Unmarshal(data, (*plain)(d))
+}
+
+// isPrometheus returns whether the datasource may be a Prometheus datasource.
+// Datasources without a type are usually references to a datasource variable
+// or input.
+func (d *grafanaDatasource) isPrometheus() bool {
+	return d == nil || d.Type == "" || d.Type == "prometheus"
+}
+
+var (
+	grafanaLabelValuesRe = regexp.MustCompile(`^\s*label_values\(\s*(?:(.+)\s*,\s*)?([a-zA-Z_][a-zA-Z0-9_]*)\s*\)\s*$`)
+	grafanaLabelNamesRe  = regexp.MustCompile(`^\s*label_names\(\s*(.*?)\s*\)\s*$`)
+	grafanaQueryResultRe = regexp.MustCompile(`^\s*query_result\(\s*(.+)\s*\)\s*$`)
+	grafanaLegacyVarRe   = regexp.MustCompile(`\[\[([a-zA-Z0-9_]+)(?::([a-zA-Z0-9_]+))?\]\]`)
+	grafanaTimeFromRe    = regexp.MustCompile(`^now-([0-9]+[smhdwy])$`)
+	persesIDRe           = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+)
+
+// grafanaUnits maps the Grafana units to their Perses equivalent.
+var grafanaUnits = map[string]string{
+	"none":        "decimal",
+	"short":       "decimal",
+	"percent":     "percent",
+	"percentunit": "percent-decimal",
+	"bits":        "bits",
+	"decbits":     "decbits",
+	"bytes":       "bytes",
+	"decbytes":    "decbytes",
+	"bps":         "bits/sec",
+	"Bps":         "bytes/sec",
+	"binBps":      "bytes/sec",
+	"decbps":      "decbits/sec",
+	"decBps":      "decbytes/sec",
+	"ns":          "nanoseconds",
+	"µs":          "microseconds",
+	"ms":          "milliseconds",
+	"s":           "seconds",
+	"m":           "minutes",
+	"h":           "hours",
+	"d":           "days",
+	"reqps":       "requests/sec",
+	"ops":         "ops/sec",
+	"rps":         "reads/sec",
+	"wps":         "writes/sec",
+	"pps":         "packets/sec",
+	"cps":         "counts/sec",
+}
+
+// grafanaPanelKinds maps the Grafana panel types to the Perses panel plugins.
+var grafanaPanelKinds = map[string]string{
+	"timeseries": "TimeSeriesChart",
+	"graph":      "TimeSeriesChart",
+	"stat":       "StatChart",
+	"singlestat": "StatChart",
+	"gauge":      "GaugeChart",
+	"bargauge":   "BarChart",
+	"barchart":   "BarChart",
+	"table":      "Table",
+	"text":       "Markdown",
+}
+
+// grafanaConverter converts a Grafana dashboard and collects the warnings
+// about the parts which couldn't be converted.
+type grafanaConverter struct {
+	// datasource is the name of the PersesDatasource queried by the
+	// dashboard. The default datasource is used when it is empty.
+	datasource string
+	warnings   []string
+}
+
+func (c *grafanaConverter) warnf(format string, args ...any) {
+	c.warnings = append(c.warnings, fmt.Sprintf(format, args...))
+}
+
+// convertGrafanaDashboard converts the Grafana dashboard JSON model to a
+// Perses dashboard, using the title as display name when the Grafana
+// dashboard has none. The returned warnings list the parts of the dashboard
+// which were skipped or approximated.
+func convertGrafanaDashboard(name, title string, data []byte, datasource string) (*persesv1.Dashboard, []string, error) {
+	var gd grafanaDashboard
+	if err := json.Unmarshal(data, &gd); err != nil {
+		return nil, nil, fmt.Errorf("invalid Grafana dashboard: %w", err)
+	}
+
+	if len(gd.Rows) > 0 {
+		return nil, nil, errors.New("dashboards using the legacy rows format aren't supported, export the dashboard from a recent Grafana version")
+	}
+
+	if gd.Title != "" {
+		title = gd.Title
+	}
+
+	c := &grafanaConverter{datasource: datasource}
+
+	spec := map[string]any{
+		"display":  map[string]any{"name": title, "description": gd.Description},
+		"duration": c.duration(gd.Time.From),
+	}
+	if refresh, ok := gd.Refresh.(string); ok && refresh != "" {
+		spec["refreshInterval"] = refresh
+	}
+
+	variables := []any{}
+	for _, v := range gd.Templating.List {
+		if variable := c.variable(v); variable != nil {
+			variables = append(variables, variable)
+		}
+	}
+	spec["variables"] = variables
+
+	panels, layouts := c.layout(gd.Panels)
+	spec["panels"] = panels
+	spec["layouts"] = layouts
+
+	out, err := json.Marshal(map[string]any{
+		"kind":     "Dashboard",
+		"metadata": map[string]any{"name": name, "project": name},
+		"spec":     spec,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dashboard := &persesv1.Dashboard{}
+	if err := dashboard.UnmarshalJSON(out); err != nil {
+		return nil, nil, fmt.Errorf("invalid Perses dashboard: %w", err)
+	}
+
+	return dashboard, c.warnings, nil
+}
+
+func (c *grafanaConverter) duration(from string) string {
+	if from == "" {
+		return "1h"
+	}
+
+	m := grafanaTimeFromRe.FindStringSubmatch(from)
+	if m == nil {
+		c.warnf("time range %q isn't supported, using 1h", from)
+		return "1h"
+	}
+
+	return m[1]
+}
+
+// layout converts the panels and returns the Perses panels and grid
+// layouts. A new grid is started for each row.
+func (c *grafanaConverter) layout(gps []grafanaPanel) (map[string]any, []any) {
+	panels := map[string]any{}
+	layouts := []any{}
+
+	var (
+		items   []any
+		display map[string]any
+		offset  int
+	)
+	flush := func() {
+		if len(items) == 0 && display == nil {
+			return
+		}
+		spec := map[string]any{"items": items}
+		if display != nil {
+			spec["display"] = display
+		}
+		layouts = append(layouts, map[string]any{"kind": "Grid", "spec": spec})
+		items = []any{}
+	}
+
+	addPanel := func(gp grafanaPanel, offset int) {
+		key := c.panelKey(gp, panels)
+		panels[key] = c.panel(gp)
+		items = append(items, map[string]any{
+			"x":       gp.GridPos.X,
+			"y":       max(gp.GridPos.Y-offset, 0),
+			"width":   gp.GridPos.W,
+			"height":  gp.GridPos.H,
+			"content": map[string]any{"$ref": "#/spec/panels/" + key},
+		})
+	}
+
+	for _, gp := range gps {
+		if gp.Type != "row" {
+			addPanel(gp, offset)
+			continue
+		}
+
+		flush()
+		display = map[string]any{
+			"title":    gp.Title,
+			"collapse": map[string]any{"open": !gp.Collapsed},
+		}
+		offset = gp.GridPos.Y + gp.GridPos.H
+
+		// The panels of collapsed rows are nested in the row.
+		for _, child := range gp.Panels {
+			addPanel(child, offset)
+		}
+	}
+	flush()
+
+	return panels, layouts
+}
+
+func (c *grafanaConverter) panelKey(gp grafanaPanel, panels map[string]any) string {
+	key := persesIDRe.ReplaceAllString(strings.ToLower(gp.Title), "_")
+	if key == "" {
+		key = fmt.Sprintf("panel_%d", gp.ID)
+	}
+
+	unique := key
+	for i := 1; panels[unique] != nil; i++ {
+		unique = fmt.Sprintf("%s_%d", key, i)
+	}
+
+	return unique
+}
+
+func (c *grafanaConverter) panel(gp grafanaPanel) map[string]any {
+	display := map[string]any{"name": gp.Title}
+	if gp.Description != "" {
+		display["description"] = gp.Description
+	}
+
+	kind, ok := grafanaPanelKinds[gp.Type]
+	if !ok {
+		c.warnf("panel %q: type %q isn't supported, replaced by a text panel", gp.Title, gp.Type)
+		return map[string]any{
+			"kind": "Panel",
+			"spec": map[string]any{
+				"display": display,
+				"plugin": map[string]any{
+					"kind": "Markdown",
+					"spec": map[string]any{"text": fmt.Sprintf("The Grafana %q panel couldn't be converted.", gp.Type)},
+				},
+			},
+		}
+	}
+
+	pluginSpec := map[string]any{}
+	switch kind {
+	case "Markdown":
+		pluginSpec["text"] = gp.Options.Content
+	case "TimeSeriesChart":
+		pluginSpec["legend"] = map[string]any{"position": "bottom"}
+		if format := c.format(gp); format != nil {
+			yAxis := map[string]any{"format": format}
+			if gp.FieldConfig.Defaults.Min != nil {
+				yAxis["min"] = *gp.FieldConfig.Defaults.Min
+			}
+			if gp.FieldConfig.Defaults.Max != nil {
+				yAxis["max"] = *gp.FieldConfig.Defaults.Max
+			}
+			pluginSpec["yAxis"] = yAxis
+		}
+	case "StatChart", "GaugeChart", "BarChart":
+		pluginSpec["calculation"] = "last-number"
+		if format := c.format(gp); format != nil {
+			pluginSpec["format"] = format
+		}
+	}
+
+	spec := map[string]any{
+		"display": display,
+		"plugin":  map[string]any{"kind": kind, "spec": pluginSpec},
+	}
+
+	if queries := c.queries(gp); len(queries) > 0 {
+		spec["queries"] = queries
+	}
+
+	return map[string]any{"kind": "Panel", "spec": spec}
+}
+
+func (c *grafanaConverter) format(gp grafanaPanel) map[string]any {
+	unit := gp.FieldConfig.Defaults.Unit
+	if unit == "" {
+		return nil
+	}
+
+	perses, ok := grafanaUnits[unit]
+	if !ok {
+		c.warnf("panel %q: unit %q isn't supported", gp.Title, unit)
+		return nil
+	}
+
+	format := map[string]any{"unit": perses}
+	if d := gp.FieldConfig.Defaults.Decimals; d != nil {
+		format["decimalPlaces"] = *d
+	}
+
+	return format
+}
+
+func (c *grafanaConverter) queries(gp grafanaPanel) []any {
+	queries := []any{}
+	for _, t := range gp.Targets {
+		if t.Hide {
+			continue
+		}
+
+		ds := t.Datasource
+		if ds == nil {
+			ds = gp.Datasource
+		}
+		if !ds.isPrometheus() || t.Expr == "" {
+			c.warnf("panel %q: query %q skipped, only Prometheus queries are supported", gp.Title, t.RefID)
+			continue
+		}
+
+		querySpec := map[string]any{"query": c.expression(t.Expr)}
+		if t.LegendFormat != "" && t.LegendFormat != "__auto" {
+			querySpec["seriesNameFormat"] = t.LegendFormat
+		}
+		if c.datasource != "" {
+			querySpec["datasource"] = map[string]any{"kind": "PrometheusDatasource", "name": c.datasource}
+		}
+
+		queries = append(queries, map[string]any{
+			"kind": "TimeSeriesQuery",
+			"spec": map[string]any{
+				"plugin": map[string]any{"kind": "PrometheusTimeSeriesQuery", "spec": querySpec},
+			},
+		})
+	}
+
+	return queries
+}
+
+// expression converts the legacy [[var]] variable syntax which isn't
+// supported by Perses.
+func (c *grafanaConverter) expression(expr string) string {
+	return grafanaLegacyVarRe.ReplaceAllStringFunc(expr, func(s string) string {
+		m := grafanaLegacyVarRe.FindStringSubmatch(s)
+		if m[2] != "" {
+			return fmt.Sprintf("${%s:%s}", m[1], m[2])
+		}
+		return "$" + m[1]
+	})
+}
+
+func (c *grafanaConverter) variable(v grafanaVariable) map[string]any {
+	display := map[string]any{"name": v.Label, "hidden": v.Hide == 2}
+	if v.Label == "" {
+		display["name"] = v.Name
+	}
+
+	query := ""
+	switch q := v.Query.(type) {
+	case string:
+		query = q
+	case map[string]any:
+		query, _ = q["query"].(string)
+	}
+
+	var plugin map[string]any
+	switch v.Type {
+	case "query":
+		if !v.Datasource.isPrometheus() {
+			c.warnf("variable %q skipped, only Prometheus queries are supported", v.Name)
+			return nil
+		}
+		plugin = c.queryVariablePlugin(v.Name, query)
+		if plugin == nil {
+			return nil
+		}
+
+	case "custom", "interval":
+		plugin = map[string]any{
+			"kind": "StaticListVariable",
+			"spec": map[string]any{"values": customVariableValues(query)},
+		}
+
+	case "constant", "textbox":
+		return map[string]any{
+			"kind": "TextVariable",
+			"spec": map[string]any{
+				"name":     v.Name,
+				"display":  display,
+				"value":    query,
+				"constant": v.Type == "constant",
+			},
+		}
+
+	case "datasource":
+		c.warnf("variable %q skipped, the queries use the datasource of the dashboard", v.Name)
+		return nil
+
+	default:
+		c.warnf("variable %q skipped, type %q isn't supported", v.Name, v.Type)
+		return nil
+	}
+
+	spec := map[string]any{
+		"name":          v.Name,
+		"display":       display,
+		"allowMultiple": v.Multi,
+		"allowAllValue": v.IncludeAll,
+		"plugin":        plugin,
+	}
+	if v.IncludeAll && v.AllValue != "" {
+		spec["customAllValue"] = v.AllValue
+	}
+	if v.Regex != "" {
+		spec["capturingRegexp"] = strings.TrimSuffix(strings.TrimPrefix(v.Regex, "/"), "/")
+	}
+	if value := defaultVariableValue(v); value != nil {
+		spec["defaultValue"] = value
+	}
+
+	return map[string]any{"kind": "ListVariable", "spec": spec}
+}
+
+func (c *grafanaConverter) queryVariablePlugin(name, query string) map[string]any {
+	if m := grafanaLabelValuesRe.FindStringSubmatch(query); m != nil {
+		spec := map[string]any{"labelName": m[2]}
+		if m[1] != "" {
+			spec["matchers"] = []string{c.expression(strings.TrimSpace(m[1]))}
+		}
+		return map[string]any{"kind": "PrometheusLabelValuesVariable", "spec": spec}
+	}
+
+	if m := grafanaLabelNamesRe.FindStringSubmatch(query); m != nil {
+		spec := map[string]any{}
+		if m[1] != "" {
+			spec["matchers"] = []string{c.expression(m[1])}
+		}
+		return map[string]any{"kind": "PrometheusLabelNamesVariable", "spec": spec}
+	}
+
+	if m := grafanaQueryResultRe.FindStringSubmatch(query); m != nil {
+		c.warnf("variable %q: query_result() is converted to a PromQL variable returning the values of the %q label", name, "__name__")
+		return map[string]any{
+			"kind": "PrometheusPromQLVariable",
+			"spec": map[string]any{"expr": c.expression(m[1]), "labelName": "__name__"},
+		}
+	}
+
+	c.warnf("variable %q skipped, query %q isn't supported", name, query)
+	return nil
+}
+
+// customVariableValues splits the values of a custom variable. Values
+// defined as "text : value" keep the value.
+func customVariableValues(query string) []string {
+	values := []string{}
+	for _, v := range strings.Split(query, ",") {
+		if i := strings.Index(v, " : "); i >= 0 {
+			v = v[i+3:]
+		}
+		if v = strings.TrimSpace(v); v != "" && !slices.Contains(values, v) {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
+
+func defaultVariableValue(v grafanaVariable) any {
+	var values []string
+	switch current := v.Current.Value.(type) {
+	case string:
+		values = []string{current}
+	case []any:
+		for _, value := range current {
+			if s, ok := value.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	// The "All" value is selected with allowAllValue.
+	values = slices.DeleteFunc(values, func(s string) bool { return s == "" || s == "$__all" })
+	switch {
+	case len(values) == 0:
+		return nil
+	case len(values) == 1 || !v.Multi:
+		return values[0]
+	default:
+		return values
+	}
+}
@@ -0,0 +1,223 @@
+package uiplugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	persesv1alpha2 "github.com/rhobs/perses-operator/api/v1alpha2"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+)
+
+const grafanaTestDashboard = `{
+  "title": "Shop",
+  "time": {"from": "now-3h", "to": "now"},
+  "refresh": "30s",
+  "templating": {
+    "list": [
+      {
+        "name": "namespace",
+        "type": "query",
+        "datasource": {"type": "prometheus", "uid": "${datasource}"},
+        "query": {"query": "label_values(kube_pod_info{cluster=\"$cluster\"}, namespace)"},
+        "multi": true,
+        "includeAll": true,
+        "allValue": ".*",
+        "current": {"value": ["shop", "checkout"]}
+      },
+      {"name": "datasource", "type": "datasource", "query": "prometheus"},
+      {"name": "interval", "type": "custom", "query": "1m,5m : 5m,1h", "current": {"value": "5m"}},
+      {"name": "cluster", "type": "constant", "query": "local", "hide": 2}
+    ]
+  },
+  "panels": [
+    {
+      "id": 1,
+      "type": "stat",
+      "title": "Pods",
+      "gridPos": {"x": 0, "y": 0, "w": 6, "h": 4},
+      "fieldConfig": {"defaults": {"unit": "short"}},
+      "targets": [{"refId": "A", "expr": "count(kube_pod_info{namespace=~\"[[namespace]]\"})"}]
+    },
+    {
+      "id": 2,
+      "type": "row",
+      "title": "Traffic",
+      "collapsed": true,
+      "gridPos": {"x": 0, "y": 4, "w": 24, "h": 1},
+      "panels": [
+        {
+          "id": 3,
+          "type": "timeseries",
+          "title": "Requests",
+          "gridPos": {"x": 0, "y": 5, "w": 12, "h": 8},
+          "fieldConfig": {"defaults": {"unit": "reqps", "min": 0}},
+          "targets": [
+            {"refId": "A", "expr": "sum(rate(http_requests_total[$__rate_interval]))", "legendFormat": "{{code}}"},
+            {"refId": "B", "datasource": {"type": "loki", "uid": "loki"}, "expr": "{app=\"shop\"}"}
+          ]
+        },
+        {
+          "id": 4,
+          "type": "heatmap",
+          "title": "Latency",
+          "gridPos": {"x": 12, "y": 5, "w": 12, "h": 8}
+        },
+        {
+          "id": 5,
+          "type": "gauge",
+          "title": "Saturation",
+          "gridPos": {"x": 0, "y": 13, "w": 6, "h": 4},
+          "fieldConfig": {"defaults": {"unit": "unknown"}},
+          "targets": [{"refId": "A", "expr": "avg(saturation)"}]
+        }
+      ]
+    }
+  ]
+}`
+
+func TestConvertGrafanaDashboard(t *testing.T) {
+	dashboard, warnings, err := convertGrafanaDashboard("shop", "shop.json", []byte(grafanaTestDashboard), "")
+	assert.NilError(t, err)
+
+	spec := dashboard.Spec
+	assert.Equal(t, spec.Display.Name, "Shop")
+	assert.Equal(t, string(spec.Duration), "3h")
+	assert.Equal(t, string(spec.RefreshInterval), "30s")
+
+	assert.Equal(t, len(spec.Variables), 3)
+	assert.Equal(t, string(spec.Variables[0].Kind), "ListVariable")
+	assert.Equal(t, string(spec.Variables[1].Kind), "ListVariable")
+	assert.Equal(t, string(spec.Variables[2].Kind), "TextVariable")
+
+	assert.Equal(t, len(spec.Panels), 4)
+	assert.Equal(t, spec.Panels["pods"].Spec.Plugin.Kind, "StatChart")
+	assert.Equal(t, spec.Panels["latency"].Spec.Plugin.Kind, "Markdown")
+	requests := spec.Panels["requests"]
+	assert.Equal(t, requests.Spec.Plugin.Kind, "TimeSeriesChart")
+	assert.Equal(t, len(requests.Spec.Queries), 1)
+	query := requests.Spec.Queries[0].Spec.Plugin.Spec.(map[string]any)
+	assert.Equal(t, query["seriesNameFormat"], "{{code}}")
+	assert.Equal(t, query["datasource"], nil)
+
+	pods := spec.Panels["pods"].Spec.Queries[0].Spec.Plugin.Spec.(map[string]any)
+	assert.Equal(t, pods["query"], `count(kube_pod_info{namespace=~"$namespace"})`)
+
+	// The row panels are grouped in a collapsed grid.
+	assert.Equal(t, len(spec.Layouts), 2)
+
+	for _, expected := range []string{
+		`variable "datasource" skipped`,
+		`panel "Requests": query "B" skipped`,
+		`panel "Latency": type "heatmap" isn't supported`,
+		`panel "Saturation": unit "unknown" isn't supported`,
+	} {
+		found := false
+		for _, w := range warnings {
+			found = found || strings.Contains(w, expected)
+		}
+		assert.Assert(t, found, "expected warning %q in %v", expected, warnings)
+	}
+	assert.Equal(t, len(warnings), 4)
+
+	// The queries use the configured datasource.
+	dashboard, _, err = convertGrafanaDashboard("shop", "shop.json", []byte(grafanaTestDashboard), "thanos")
+	assert.NilError(t, err)
+	query = dashboard.Spec.Panels["requests"].Spec.Queries[0].Spec.Plugin.Spec.(map[string]any)
+	assert.DeepEqual(t, query["datasource"], map[string]any{"kind": "PrometheusDatasource", "name": "thanos"})
+
+	_, _, err = convertGrafanaDashboard("old", "old.json", []byte(`{"title": "Old", "rows": [{"panels": []}]}`), "")
+	assert.ErrorContains(t, err, "legacy rows format")
+
+	_, _, err = convertGrafanaDashboard("invalid", "invalid.json", []byte(`{`), "")
+	assert.ErrorContains(t, err, "invalid Grafana dashboard")
+}
+
+func TestConvertGrafanaDashboardSample(t *testing.T) {
+	data, err := os.ReadFile("../../../dashboards/grafana-dashboard-coo-telemetry.configmap.yaml")
+	assert.NilError(t, err)
+
+	cm := &corev1.ConfigMap{}
+	assert.NilError(t, yaml.Unmarshal(data, cm))
+
+	for key, value := range cm.Data {
+		dashboard, _, err := convertGrafanaDashboard("sample", key, []byte(value), "")
+		assert.NilError(t, err)
+		assert.Assert(t, len(dashboard.Spec.Panels) > 0)
+	}
+}
+
+func TestGrafanaDashboardName(t *testing.T) {
+	name := grafanaDashboardName("shop", "Shop_Overview.json")
+	assert.Assert(t, strings.HasPrefix(name, "shop-shop-overview-"), name)
+	assert.Equal(t, len(name), len("shop-shop-overview-")+8)
+	assert.Equal(t, len(grafanaDashboardName("shop", strings.Repeat("a", 300))), 253)
+
+	// Keys which only differ by invalid characters get distinct names.
+	assert.Assert(t, grafanaDashboardName("shop", "overview.json") != grafanaDashboardName("shop", "Overview.json"))
+	assert.Assert(t, grafanaDashboardName("a-b", "c") != grafanaDashboardName("a", "b-c"))
+}
+
+func TestReconcileGrafanaDashboards(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NilError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NilError(t, persesv1alpha2.AddToScheme(scheme))
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "shop",
+			Namespace: "ns",
+			UID:       "uid",
+			Labels:    map[string]string{grafanaDashboardLabel: "true"},
+		},
+		Data: map[string]string{
+			"shop.json":    grafanaTestDashboard,
+			"invalid.json": `{`,
+		},
+	}
+	k := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cm).Build()
+	recorder := record.NewFakeRecorder(10)
+	gm := grafanaDashboardManager{k8sClient: k, scheme: scheme, recorder: recorder, namespace: "ns"}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "shop", Namespace: "ns"}}
+	_, err := gm.Reconcile(context.Background(), req)
+	assert.NilError(t, err)
+
+	dashboardName := grafanaDashboardName("shop", "shop.json")
+	dashboard := &persesv1alpha2.PersesDashboard{}
+	assert.NilError(t, k.Get(context.Background(), types.NamespacedName{Name: dashboardName, Namespace: "ns"}, dashboard))
+	assert.Assert(t, metav1.IsControlledBy(dashboard, cm))
+	assert.Equal(t, dashboard.Spec.Config.Spec.Display.Name, "Shop")
+
+	close(recorder.Events)
+	var events []string
+	for e := range recorder.Events {
+		events = append(events, e)
+	}
+	assert.Equal(t, len(events), 6)
+	assert.Assert(t, strings.HasPrefix(events[0], "Warning ConversionFailed key \"invalid.json\""))
+	assert.Equal(t, events[5], fmt.Sprintf(`Normal Converted key "shop.json" converted to PersesDashboard %s with 4 warning(s)`, dashboardName))
+
+	// Removing the label deletes the converted dashboards.
+	assert.NilError(t, k.Get(context.Background(), req.NamespacedName, cm))
+	cm.Labels = nil
+	assert.NilError(t, k.Update(context.Background(), cm))
+	gm.recorder = record.NewFakeRecorder(10)
+	_, err = gm.Reconcile(context.Background(), req)
+	assert.NilError(t, err)
+
+	dashboards := &persesv1alpha2.PersesDashboardList{}
+	assert.NilError(t, k.List(context.Background(), dashboards))
+	assert.Equal(t, len(dashboards.Items), 0)
+}
@@ -0,0 +1,214 @@
+package uiplugin
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	persesv1alpha2 "github.com/rhobs/perses-operator/api/v1alpha2"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/rhobs/observability-operator/pkg/reconciler"
+)
+
+const (
+	// grafanaDashboardLabel selects the ConfigMaps containing Grafana
+	// dashboards to convert.
+	grafanaDashboardLabel = "observability.openshift.io/grafana-dashboard"
+	// grafanaDatasourceAnnotation is the name of the PersesDatasource
+	// queried by the converted dashboards.
+	grafanaDatasourceAnnotation = "observability.openshift.io/perses-datasource"
+
+	GrafanaDashboardConvertedReason = "Converted"
+	GrafanaDashboardWarningReason   = "ConversionWarning"
+	GrafanaDashboardFailedReason    = "ConversionFailed"
+)
+
+var invalidNameCharsRe = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// grafanaDashboardManager converts the Grafana dashboards stored in labelled
+// ConfigMaps of the Perses namespace into PersesDashboards.
+type grafanaDashboardManager struct {
+	k8sClient client.Client
+	scheme    *runtime.Scheme
+	recorder  record.EventRecorder
+	logger    logr.Logger
+	namespace string
+}
+
+// RBAC for reporting conversion events
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+func registerGrafanaDashboardsWithManager(mgr ctrl.Manager, namespace string) error {
+	gm := &grafanaDashboardManager{
+		k8sClient: mgr.GetClient(),
+		scheme:    mgr.GetScheme(),
+		recorder:  mgr.GetEventRecorderFor("observability-operator"),
+		logger:    ctrl.Log.WithName("grafana-dashboards"),
+		namespace: namespace,
+	}
+
+	// Only the ConfigMaps of the operator namespace are cached without
+	// label selector. Both the old and new objects are checked to clean up
+	// the dashboards when the label is removed.
+	isGrafanaDashboard := func(obj client.Object) bool {
+		return obj.GetNamespace() == namespace && obj.GetLabels()[grafanaDashboardLabel] == "true"
+	}
+	configMapPredicate := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return isGrafanaDashboard(e.Object)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return isGrafanaDashboard(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return isGrafanaDashboard(e.ObjectOld) || isGrafanaDashboard(e.ObjectNew)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return isGrafanaDashboard(e.Object)
+		},
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("grafana-dashboards").
+		For(&v1.ConfigMap{}, builder.WithPredicates(configMapPredicate)).
+		Owns(&persesv1alpha2.PersesDashboard{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(gm)
+}
+
+func (gm grafanaDashboardManager) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := gm.logger.WithValues("configmap", req.NamespacedName)
+	logger.V(6).Info("Reconciling Grafana dashboards")
+
+	cm := &v1.ConfigMap{}
+	if err := gm.k8sClient.Get(ctx, req.NamespacedName, cm); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		// The dashboards are garbage-collected with their owner.
+		return ctrl.Result{}, nil
+	}
+
+	keep := map[string]struct{}{}
+	if cm.DeletionTimestamp.IsZero() && cm.Labels[grafanaDashboardLabel] == "true" {
+		dashboards, failed := gm.convert(cm)
+		for _, name := range failed {
+			keep[name] = struct{}{}
+		}
+
+		for _, d := range dashboards {
+			keep[d.Name] = struct{}{}
+			err := reconciler.NewUpdater(d, cm).Reconcile(ctx, gm.k8sClient, gm.scheme)
+			if apierrors.IsAlreadyExists(err) || apierrors.IsConflict(err) {
+				logger.V(8).Info("skipping reconcile error", "err", err)
+				return ctrl.Result{RequeueAfter: 2 * time.Second}, nil
+			}
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	}
+
+	// Delete the dashboards of removed keys.
+	dashboards := &persesv1alpha2.PersesDashboardList{}
+	if err := gm.k8sClient.List(ctx, dashboards, client.InNamespace(cm.Namespace)); err != nil {
+		return ctrl.Result{}, err
+	}
+	for i := range dashboards.Items {
+		if _, found := keep[dashboards.Items[i].Name]; found || !metav1.IsControlledBy(&dashboards.Items[i], cm) {
+			continue
+		}
+		if err := reconciler.NewDeleter(&dashboards.Items[i]).Reconcile(ctx, gm.k8sClient, gm.scheme); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// convert returns a PersesDashboard for each key of the ConfigMap and the
+// names of the dashboards which couldn't be converted. The previous version
+// of these dashboards is kept. The conversion results are reported as events
+// on the ConfigMap.
+func (gm grafanaDashboardManager) convert(cm *v1.ConfigMap) ([]*persesv1alpha2.PersesDashboard, []string) {
+	keys := make([]string, 0, len(cm.Data))
+	for key := range cm.Data {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var (
+		dashboards []*persesv1alpha2.PersesDashboard
+		failed     []string
+	)
+	for _, key := range keys {
+		d := newGrafanaPersesDashboard(cm, key)
+
+		dashboard, warnings, err := convertGrafanaDashboard(d.Name, key, []byte(cm.Data[key]), cm.Annotations[grafanaDatasourceAnnotation])
+		if err != nil {
+			gm.recorder.Eventf(cm, v1.EventTypeWarning, GrafanaDashboardFailedReason, "key %q: %s", key, err)
+			failed = append(failed, d.Name)
+			continue
+		}
+
+		for _, w := range warnings {
+			gm.recorder.Eventf(cm, v1.EventTypeWarning, GrafanaDashboardWarningReason, "key %q: %s", key, w)
+		}
+
+		d.Spec.Config.Spec = dashboard.Spec
+		dashboards = append(dashboards, d)
+		gm.recorder.Eventf(cm, v1.EventTypeNormal, GrafanaDashboardConvertedReason, "key %q converted to PersesDashboard %s with %d warning(s)", key, d.Name, len(warnings))
+	}
+
+	return dashboards, failed
+}
+
+func newGrafanaPersesDashboard(cm *v1.ConfigMap, key string) *persesv1alpha2.PersesDashboard {
+	return &persesv1alpha2.PersesDashboard{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: persesv1alpha2.GroupVersion.String(),
+			Kind:       "PersesDashboard",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      grafanaDashboardName(cm.Name, key),
+			Namespace: cm.Namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "observability-operator",
+			},
+		},
+	}
+}
+
+// grafanaDashboardName returns a valid object name derived from the
+// ConfigMap name and key. The sanitization of the key isn't injective: a
+// hash of the ConfigMap name and key keeps the names unique.
+func grafanaDashboardName(configMap, key string) string {
+	h := fnv.New32a()
+	h.Write([]byte(configMap + "/" + key))
+	suffix := fmt.Sprintf("-%08x", h.Sum32())
+
+	key = strings.TrimSuffix(strings.ToLower(key), ".json")
+	key = strings.Trim(invalidNameCharsRe.ReplaceAllString(key, "-"), "-")
+
+	name := fmt.Sprintf("%s-%s", configMap, key)
+	if len(name)+len(suffix) > validation.DNS1123SubdomainMaxLength {
+		name = strings.TrimRight(name[:validation.DNS1123SubdomainMaxLength-len(suffix)], "-.")
+	}
+
+	return name + suffix
+}
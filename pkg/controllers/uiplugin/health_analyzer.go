@@ -1,18 +1,27 @@
 package uiplugin
 
 import (
+	"bytes"
+	"context"
 	_ "embed"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	monv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"gopkg.in/yaml.v3"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	uiv1alpha1 "github.com/rhobs/observability-operator/pkg/apis/uiplugin/v1alpha1"
 )
 
 const (
@@ -25,6 +34,34 @@ const (
 //go:embed config/health-analyzer.yaml
 var componentHealthConfig string
 
+// healthComponentsConfig is the component tree evaluated by the cluster
+// health analyzer.
+type healthComponentsConfig struct {
+	Components []healthComponent `yaml:"components"`
+}
+
+type healthComponent struct {
+	Name     string                 `yaml:"name"`
+	Children []healthComponent      `yaml:"children,omitempty"`
+	Alerts   *healthAlertsMatcher   `yaml:"alerts,omitempty"`
+	Objects  []healthObjectsMatcher `yaml:"objects,omitempty"`
+}
+
+type healthAlertsMatcher struct {
+	Selectors []healthSelector `yaml:"selectors"`
+}
+
+type healthObjectsMatcher struct {
+	Resource  string           `yaml:"resource"`
+	Group     string           `yaml:"group,omitempty"`
+	Namespace string           `yaml:"namespace,omitempty"`
+	Selectors []healthSelector `yaml:"selectors,omitempty"`
+}
+
+type healthSelector struct {
+	MatchLabels map[string][]string `yaml:"matchLabels"`
+}
+
 func newHealthAnalyzerPrometheusRole(namespace string) *rbacv1.Role {
 	role := &rbacv1.Role{
 		TypeMeta: metav1.TypeMeta{
@@ -126,6 +163,33 @@ func newHealthAnalyzerDeployment(namespace string,
 		args = append(args, fmt.Sprintf("--tls-min-version=%s", pluginInfo.TLSMinVersion))
 	}
 
+	if cfg := pluginInfo.HealthAnalyzerConfig; cfg != nil {
+		if cfg.LogLevel != "" {
+			args = append(args, fmt.Sprintf("--log-level=%s", cfg.LogLevel))
+		}
+		if cfg.Interval != "" {
+			args = append(args, fmt.Sprintf("--interval=%s", cfg.Interval))
+		}
+	}
+	settings := healthAnalyzerDeploymentSettings(pluginInfo)
+
+	env := []corev1.EnvVar{
+		{
+			Name:  "PROM_URL",
+			Value: "https://thanos-querier.openshift-monitoring.svc.cluster.local:9091/",
+		},
+		{
+			Name:  "ALERTMANAGER_URL",
+			Value: "https://alertmanager-main.openshift-monitoring.svc.cluster.local:9094",
+		},
+	}
+	if backends := pluginInfo.HealthAnalyzerBackends; backends != nil {
+		env = []corev1.EnvVar{{Name: "PROM_URL", Value: backends.PrometheusURL + "/"}}
+		if backends.AlertmanagerURL != "" {
+			env = append(env, corev1.EnvVar{Name: "ALERTMANAGER_URL", Value: backends.AlertmanagerURL})
+		}
+	}
+
 	deploy := &appsv1.Deployment{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: appsv1.SchemeGroupVersion.String(),
@@ -137,7 +201,7 @@ func newHealthAnalyzerDeployment(namespace string,
 			Labels:    componentLabels(name),
 		},
 		Spec: appsv1.DeploymentSpec{
-			Replicas: ptr.To(int32(1)),
+			Replicas: ptr.To(settings.replicas),
 			Selector: &metav1.LabelSelector{
 				MatchLabels: map[string]string{
 					"app.kubernetes.io/instance": name,
@@ -146,26 +210,25 @@ func newHealthAnalyzerDeployment(namespace string,
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels: componentLabels(name),
+					Annotations: map[string]string{
+						annotationPrefix + "config-hash": computeConfigMapHash(newComponentHealthConfig(namespace, pluginInfo.HealthAnalyzerComponents)),
+					},
 				},
 				Spec: corev1.PodSpec{
 					ServiceAccountName:           serviceAccountName,
 					AutomountServiceAccountToken: ptr.To(true),
+					NodeSelector:                 settings.nodeSelector,
+					Tolerations:                  settings.tolerations,
+					TopologySpreadConstraints:    settings.topologySpreadConstraints,
+					PriorityClassName:            settings.priorityClassName,
 					Containers: []corev1.Container{
 						{
 							Name:            name,
 							Image:           pluginInfo.HealthAnalyzerImage,
 							ImagePullPolicy: corev1.PullAlways,
 							Args:            args,
-							Env: []corev1.EnvVar{
-								{
-									Name:  "PROM_URL",
-									Value: "https://thanos-querier.openshift-monitoring.svc.cluster.local:9091/",
-								},
-								{
-									Name:  "ALERTMANAGER_URL",
-									Value: "https://alertmanager-main.openshift-monitoring.svc.cluster.local:9094",
-								},
-							},
+							Env:             env,
+							Resources:       settings.resources,
 							SecurityContext: &corev1.SecurityContext{
 								RunAsNonRoot:             ptr.To(true),
 								AllowPrivilegeEscalation: ptr.To(false),
@@ -224,6 +287,38 @@ func newHealthAnalyzerDeployment(namespace string,
 	return deploy
 }
 
+// healthAnalyzerDeploymentSettings returns the settings of the plugin
+// deployment overridden by the health analyzer configuration. The replicas
+// of the plugin aren't inherited since the health analyzer has no leader
+// election.
+func healthAnalyzerDeploymentSettings(info UIPluginInfo) deploymentSettings {
+	settings := newDeploymentSettings(info.DeploymentConfig)
+	settings.replicas = 1
+
+	cfg := info.HealthAnalyzerConfig
+	if cfg == nil {
+		return settings
+	}
+
+	if cfg.Replicas != nil {
+		settings.replicas = *cfg.Replicas
+	}
+	if cfg.Resources != nil {
+		settings.resources = *cfg.Resources
+	}
+	if cfg.NodeSelector != nil {
+		settings.nodeSelector = cfg.NodeSelector
+	}
+	if cfg.Tolerations != nil {
+		settings.tolerations = cfg.Tolerations
+	}
+	if cfg.TopologySpreadConstraints != nil {
+		settings.topologySpreadConstraints = cfg.TopologySpreadConstraints
+	}
+
+	return settings
+}
+
 func newHealthAnalyzerServiceMonitor(namespace string) *monv1.ServiceMonitor {
 	serviceMonitor := &monv1.ServiceMonitor{
 		TypeMeta: metav1.TypeMeta{
@@ -267,9 +362,147 @@ func newHealthAnalyzerServiceMonitor(namespace string) *monv1.ServiceMonitor {
 	return serviceMonitor
 }
 
+// getHealthAnalyzerComponents returns the component tree merging the
+// components defined in the ConfigMaps referenced by the configuration into
+// the default tree. An empty string is returned when no ConfigMap is
+// referenced.
+func getHealthAnalyzerComponents(ctx context.Context, k client.Client, cfg *uiv1alpha1.ClusterHealthAnalyzerReference, namespace string) (string, error) {
+	if cfg == nil || len(cfg.Components) == 0 {
+		return "", nil
+	}
+
+	components, err := parseHealthComponents(componentHealthConfig)
+	if err != nil {
+		return "", fmt.Errorf("invalid default health analyzer components: %w", err)
+	}
+
+	for _, ref := range cfg.Components {
+		cm := &corev1.ConfigMap{}
+		if err := k.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, cm); err != nil {
+			return "", fmt.Errorf("failed to get health analyzer components ConfigMap %s/%s: %w", namespace, ref.Name, err)
+		}
+
+		keys := make([]string, 0, len(cm.Data))
+		for key := range cm.Data {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+
+		for _, key := range keys {
+			custom, err := parseHealthComponents(cm.Data[key])
+			if err != nil {
+				return "", fmt.Errorf("invalid health analyzer components in ConfigMap %s/%s key %q: %w", namespace, ref.Name, key, err)
+			}
+			components = mergeHealthComponents(components, custom)
+		}
+	}
+
+	out, err := yaml.Marshal(healthComponentsConfig{Components: components})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal health analyzer components: %w", err)
+	}
+
+	return string(out), nil
+}
+
+// parseHealthComponents decodes and validates a component tree.
+func parseHealthComponents(data string) ([]healthComponent, error) {
+	var cfg healthComponentsConfig
+	dec := yaml.NewDecoder(bytes.NewBufferString(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, err
+	}
+
+	if len(cfg.Components) == 0 {
+		return nil, errors.New("no components defined")
+	}
+
+	if err := validateHealthComponents(cfg.Components, ""); err != nil {
+		return nil, err
+	}
+
+	return cfg.Components, nil
+}
+
+func validateHealthComponents(components []healthComponent, parent string) error {
+	names := make(map[string]struct{}, len(components))
+	for _, c := range components {
+		if c.Name == "" {
+			return fmt.Errorf("component under %q: name is required", parent+"/")
+		}
+
+		path := parent + "/" + c.Name
+		if _, found := names[c.Name]; found {
+			return fmt.Errorf("component %q: duplicate name", path)
+		}
+		names[c.Name] = struct{}{}
+
+		if len(c.Children) == 0 && c.Alerts == nil && len(c.Objects) == 0 {
+			return fmt.Errorf("component %q: at least one of children, alerts or objects is required", path)
+		}
+
+		if c.Alerts != nil {
+			if len(c.Alerts.Selectors) == 0 {
+				return fmt.Errorf("component %q: alerts require at least one selector", path)
+			}
+			if err := validateHealthSelectors(c.Alerts.Selectors); err != nil {
+				return fmt.Errorf("component %q: alerts: %w", path, err)
+			}
+		}
+
+		for _, o := range c.Objects {
+			if o.Resource == "" {
+				return fmt.Errorf("component %q: objects require a resource", path)
+			}
+			if err := validateHealthSelectors(o.Selectors); err != nil {
+				return fmt.Errorf("component %q: objects %q: %w", path, o.Resource, err)
+			}
+		}
+
+		if err := validateHealthComponents(c.Children, path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateHealthSelectors(selectors []healthSelector) error {
+	for _, s := range selectors {
+		if len(s.MatchLabels) == 0 {
+			return errors.New("selectors require matchLabels")
+		}
+	}
+
+	return nil
+}
+
+// mergeHealthComponents returns the base components where the top-level
+// components with the same name as a custom component are replaced. The other
+// custom components are appended.
+func mergeHealthComponents(base, custom []healthComponent) []healthComponent {
+	merged := slices.Clone(base)
+	for _, c := range custom {
+		i := slices.IndexFunc(merged, func(b healthComponent) bool { return b.Name == c.Name })
+		if i < 0 {
+			merged = append(merged, c)
+			continue
+		}
+		merged[i] = c
+	}
+
+	return merged
+}
+
 // newComponentHealthConfig creates a new ConfigMap
 // that defines the components whose health is evaluated.
-func newComponentHealthConfig(namespace string) *v1.ConfigMap {
+// The default component tree is used when components is empty.
+func newComponentHealthConfig(namespace string, components string) *v1.ConfigMap {
+	if components == "" {
+		components = componentHealthConfig
+	}
+
 	cm := v1.ConfigMap{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: v1.SchemeGroupVersion.String(),
@@ -281,7 +514,7 @@ func newComponentHealthConfig(namespace string) *v1.ConfigMap {
 			Labels:    componentLabels("monitoring"),
 		},
 		Data: map[string]string{
-			"components.yaml": componentHealthConfig,
+			"components.yaml": components,
 		},
 	}
 
@@ -0,0 +1,192 @@
+package uiplugin
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	monv1alpha1 "github.com/rhobs/observability-operator/pkg/apis/monitoring/v1alpha1"
+	uiv1alpha1 "github.com/rhobs/observability-operator/pkg/apis/uiplugin/v1alpha1"
+)
+
+func TestHealthAnalyzerComponents(t *testing.T) {
+	k := fake.NewClientBuilder().WithObjects(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "ns"},
+			Data: map[string]string{
+				"shop.yaml": `
+components:
+  - name: shop
+    children:
+    - name: checkout
+      alerts:
+        selectors:
+        - matchLabels:
+            namespace: ["shop-checkout"]
+      objects:
+      - resource: deployments
+        group: apps
+        namespace: shop-checkout
+`,
+				"addons.yaml": `
+components:
+  - name: addons
+    alerts:
+      selectors:
+      - matchLabels:
+          namespace: ["shop-addons"]
+`,
+			},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "invalid", Namespace: "ns"},
+			Data: map[string]string{
+				"components.yaml": `
+components:
+  - name: shop
+    children:
+    - name: checkout
+      objects:
+      - group: apps
+`,
+			},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "unknown-field", Namespace: "ns"},
+			Data: map[string]string{
+				"components.yaml": `
+components:
+  - name: shop
+    alert:
+      selectors: []
+`,
+			},
+		},
+	).Build()
+
+	// The default tree is used without ConfigMaps.
+	components, err := getHealthAnalyzerComponents(context.Background(), k, &uiv1alpha1.ClusterHealthAnalyzerReference{Enabled: true}, "ns")
+	assert.NilError(t, err)
+	assert.Equal(t, components, "")
+	assert.Equal(t, newComponentHealthConfig("ns", components).Data["components.yaml"], componentHealthConfig)
+
+	components, err = getHealthAnalyzerComponents(context.Background(), k, &uiv1alpha1.ClusterHealthAnalyzerReference{
+		Enabled:    true,
+		Components: []uiv1alpha1.HealthAnalyzerComponentsReference{{Name: "shop"}},
+	}, "ns")
+	assert.NilError(t, err)
+
+	merged, err := parseHealthComponents(components)
+	assert.NilError(t, err)
+	assert.Equal(t, len(merged), 3)
+	assert.Equal(t, merged[0].Name, "control-plane")
+	// The addons component is replaced.
+	assert.Equal(t, merged[1].Name, "addons")
+	assert.Equal(t, len(merged[1].Children), 0)
+	assert.DeepEqual(t, merged[1].Alerts.Selectors[0].MatchLabels["namespace"], []string{"shop-addons"})
+	assert.Equal(t, merged[2].Name, "shop")
+	assert.Equal(t, merged[2].Children[0].Objects[0].Namespace, "shop-checkout")
+
+	// Changing the components rolls out the health analyzer.
+	deployment := newHealthAnalyzerDeployment("ns", "sa", UIPluginInfo{HealthAnalyzerComponents: components})
+	defaultDeployment := newHealthAnalyzerDeployment("ns", "sa", UIPluginInfo{})
+	assert.Assert(t, deployment.Spec.Template.Annotations[annotationPrefix+"config-hash"] != defaultDeployment.Spec.Template.Annotations[annotationPrefix+"config-hash"])
+
+	for _, tc := range []struct {
+		configMap string
+		err       string
+	}{
+		{configMap: "invalid", err: `ConfigMap ns/invalid key "components.yaml": component "/shop/checkout": objects require a resource`},
+		{configMap: "unknown-field", err: "field alert not found"},
+		{configMap: "missing", err: "failed to get health analyzer components ConfigMap ns/missing"},
+	} {
+		t.Run(tc.configMap, func(t *testing.T) {
+			_, err := getHealthAnalyzerComponents(context.Background(), k, &uiv1alpha1.ClusterHealthAnalyzerReference{
+				Enabled:    true,
+				Components: []uiv1alpha1.HealthAnalyzerComponentsReference{{Name: tc.configMap}},
+			}, "ns")
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestHealthAnalyzerBackends(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NilError(t, monv1alpha1.AddToScheme(scheme))
+
+	k := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&monv1alpha1.MonitoringStack{
+			ObjectMeta: metav1.ObjectMeta{Name: "ms", Namespace: "team-a"},
+		},
+		&monv1alpha1.ThanosQuerier{
+			ObjectMeta: metav1.ObjectMeta{Name: "tq", Namespace: "team-a"},
+		},
+	).Build()
+
+	env := func(info UIPluginInfo) map[string]string {
+		vars := map[string]string{}
+		for _, e := range newHealthAnalyzerDeployment("ns", "sa", info).Spec.Template.Spec.Containers[0].Env {
+			vars[e.Name] = e.Value
+		}
+		return vars
+	}
+
+	// The platform monitoring stack is queried by default.
+	assert.DeepEqual(t, env(UIPluginInfo{}), map[string]string{
+		"PROM_URL":         "https://thanos-querier.openshift-monitoring.svc.cluster.local:9091/",
+		"ALERTMANAGER_URL": "https://alertmanager-main.openshift-monitoring.svc.cluster.local:9094",
+	})
+
+	backends, err := getMonitoringBackends(context.Background(), k, &uiv1alpha1.MonitoringBackendsReference{
+		MonitoringStack: &uiv1alpha1.NamespacedReference{Name: "ms", Namespace: "team-a"},
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, env(UIPluginInfo{HealthAnalyzerBackends: backends}), map[string]string{
+		"PROM_URL":         "http://ms-prometheus.team-a.svc:9090/",
+		"ALERTMANAGER_URL": "http://ms-alertmanager.team-a.svc:9093",
+	})
+
+	// Without a MonitoringStack, no Alertmanager is configured.
+	backends, err = getMonitoringBackends(context.Background(), k, &uiv1alpha1.MonitoringBackendsReference{
+		ThanosQuerier: &uiv1alpha1.NamespacedReference{Name: "tq", Namespace: "team-a"},
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, env(UIPluginInfo{HealthAnalyzerBackends: backends}), map[string]string{
+		"PROM_URL": "http://thanos-querier-tq.team-a.svc:10902/",
+	})
+}
+
+func TestHealthAnalyzerDeployment(t *testing.T) {
+	info := UIPluginInfo{
+		HealthAnalyzerConfig: &uiv1alpha1.ClusterHealthAnalyzerReference{
+			Enabled:  true,
+			Replicas: ptr.To(int32(1)),
+			Resources: &corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+			},
+			NodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""},
+			Tolerations:  []corev1.Toleration{{Key: "node-role.kubernetes.io/infra", Effect: corev1.TaintEffectNoSchedule}},
+			LogLevel:     "debug",
+			Interval:     "1m",
+		},
+	}
+
+	deployment := newHealthAnalyzerDeployment("ns", "sa", info)
+	assert.Equal(t, *deployment.Spec.Replicas, int32(1))
+
+	pod := deployment.Spec.Template.Spec
+	assert.DeepEqual(t, pod.NodeSelector, info.HealthAnalyzerConfig.NodeSelector)
+	assert.DeepEqual(t, pod.Tolerations, info.HealthAnalyzerConfig.Tolerations)
+	assert.Equal(t, pod.Containers[0].Resources.Limits.Memory().String(), "1Gi")
+	assert.DeepEqual(t, pod.Containers[0].Args[len(pod.Containers[0].Args)-2:], []string{"--log-level=debug", "--interval=1m"})
+
+	assert.Equal(t, healthAnalyzerDeploymentSettings(UIPluginInfo{}).replicas, int32(1))
+	assert.Equal(t, len(newHealthAnalyzerDeployment("ns", "sa", UIPluginInfo{}).Spec.Template.Spec.Containers[0].Args), 3)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -23,24 +24,57 @@ import (
 )
 
 type loggingConfig struct {
-	LogsLimit            int32         `yaml:"logsLimit,omitempty"`
-	Timeout              time.Duration `yaml:"timeout,omitempty"`
-	Schema               string        `yaml:"schema,omitempty"`
-	ShowTimezoneSelector bool          `yaml:"showTimezoneSelector,omitempty"`
+	LogsLimit            int32                    `yaml:"logsLimit,omitempty"`
+	Timeout              time.Duration            `yaml:"timeout,omitempty"`
+	Schema               string                   `yaml:"schema,omitempty"`
+	ShowTimezoneSelector bool                     `yaml:"showTimezoneSelector,omitempty"`
+	LokiStacks           []loggingLokiStackConfig `yaml:"lokiStacks,omitempty"`
+}
+
+// loggingLokiStackConfig describes a log source selectable in the plugin.
+type loggingLokiStackConfig struct {
+	Name        string   `yaml:"name"`
+	Namespace   string   `yaml:"namespace"`
+	DisplayName string   `yaml:"displayName"`
+	ProxyAlias  string   `yaml:"proxyAlias"`
+	Tenants     []string `yaml:"tenants"`
+}
+
+// lokiStackSource is a LokiStack queried by the logging plugin through the
+// console proxy identified by ProxyAlias.
+type lokiStackSource struct {
+	types.NamespacedName
+	DisplayName string
+	ProxyAlias  string
+	Tenants     []uiv1alpha1.LoggingTenant
+	// Service is the backend of the console proxy. It defaults to the
+	// gateway of the LokiStack.
+	Service *types.NamespacedName
+}
+
+// proxyService returns the service and port of the console proxy.
+func (source lokiStackSource) proxyService() (types.NamespacedName, int32) {
+	if source.Service != nil {
+		return *source.Service, port
+	}
+	return types.NamespacedName{Name: fmt.Sprintf("%s-gateway-http", source.Name), Namespace: source.Namespace}, 8080
+}
+
+var allLoggingTenants = []uiv1alpha1.LoggingTenant{
+	uiv1alpha1.ApplicationLoggingTenant,
+	uiv1alpha1.InfrastructureLoggingTenant,
+	uiv1alpha1.AuditLoggingTenant,
 }
 
 func createLoggingPluginInfo(plugin *uiv1alpha1.UIPlugin, namespace, name, image string, features []string, ctx context.Context, dk dynamic.Interface, logger logr.Logger, korrel8rImage string) (*UIPluginInfo, error) {
-	lokiStack, err := getLokiStack(plugin, ctx, dk, logger)
+	sources, err := getLokiStackSources(plugin, namespace, ctx, dk, logger)
 	if err != nil {
 		return nil, err
 	}
 
-	lokiStackName := lokiStack.Name
-	lokiStackNamespace := lokiStack.Namespace
-
 	config := plugin.Spec.Logging
 
-	configYaml, err := marshalLoggingPluginConfig(config)
+	configYaml, err := marshalLoggingPluginConfig(config, sources)
 	if err != nil {
 		return nil, fmt.Errorf("error creating plugin configuration file: %w", err)
 	}
@@ -53,17 +87,37 @@ func createLoggingPluginInfo(plugin *uiv1alpha1.UIPlugin, namespace, name, image
 		extraArgs = append(extraArgs, fmt.Sprintf("-features=%s", strings.Join(features, ",")))
 	}
 
-	legacyProxies := []osv1alpha1.ConsolePluginProxy{
-		{
+	var (
+		legacyProxies []osv1alpha1.ConsolePluginProxy
+		proxies       []osv1.ConsolePluginProxy
+	)
+	for _, source := range sources {
+		// The Loki proxy also requires the user token: it checks the
+		// permissions of the user before adding its own credentials.
+		service, servicePort := source.proxyService()
+
+		legacyProxies = append(legacyProxies, osv1alpha1.ConsolePluginProxy{
 			Type:      "Service",
-			Alias:     "backend",
+			Alias:     source.ProxyAlias,
 			Authorize: true,
 			Service: osv1alpha1.ConsolePluginProxyServiceConfig{
-				Name:      fmt.Sprintf("%s-gateway-http", lokiStackName),
-				Namespace: lokiStackNamespace,
-				Port:      8080,
+				Name:      service.Name,
+				Namespace: service.Namespace,
+				Port:      servicePort,
 			},
-		},
+		})
+		proxies = append(proxies, osv1.ConsolePluginProxy{
+			Alias:         source.ProxyAlias,
+			Authorization: osv1.UserToken,
+			Endpoint: osv1.ConsolePluginProxyEndpoint{
+				Type: osv1.ProxyTypeService,
+				Service: &osv1.ConsolePluginProxyServiceConfig{
+					Name:      service.Name,
+					Namespace: service.Namespace,
+					Port:      servicePort,
+				},
+			},
+		})
 	}
 
 	if korrel8rImage != "" {
@@ -79,21 +133,6 @@ func createLoggingPluginInfo(plugin *uiv1alpha1.UIPlugin, namespace, name, image
 		})
 	}
 
-	proxies := []osv1.ConsolePluginProxy{
-		{
-			Alias:         "backend",
-			Authorization: "UserToken",
-			Endpoint: osv1.ConsolePluginProxyEndpoint{
-				Type: osv1.ProxyTypeService,
-				Service: &osv1.ConsolePluginProxyServiceConfig{
-					Name:      fmt.Sprintf("%s-gateway-http", lokiStackName),
-					Namespace: lokiStackNamespace,
-					Port:      8080,
-				},
-			},
-		},
-	}
-
 	if korrel8rImage != "" {
 		proxies = append(proxies, osv1.ConsolePluginProxy{
 			Alias:         "korrel8r",
@@ -115,6 +154,7 @@ func createLoggingPluginInfo(plugin *uiv1alpha1.UIPlugin, namespace, name, image
 		ConsoleName:       pluginTypeToConsoleName[plugin.Spec.Type],
 		DisplayName:       "Logging View",
 		ExtraArgs:         extraArgs,
+		Features:          features,
 		ResourceNamespace: namespace,
 		LegacyProxies:     legacyProxies,
 		Proxies:           proxies,
@@ -132,22 +172,18 @@ func createLoggingPluginInfo(plugin *uiv1alpha1.UIPlugin, namespace, name, image
 				"config.yaml": configYaml,
 			},
 		},
-		ClusterRoles: []*rbacv1.ClusterRole{
-			loggingClusterRole("application"),
-			loggingClusterRole("infrastructure"),
-			loggingClusterRole("audit"),
-		},
+		LoggingTenants: loggingTenants(sources),
 	}
 
 	return pluginInfo, nil
 }
 
-func marshalLoggingPluginConfig(cfg *uiv1alpha1.LoggingConfig) (string, error) {
+func marshalLoggingPluginConfig(cfg *uiv1alpha1.LoggingConfig, sources []lokiStackSource) (string, error) {
 	if cfg == nil {
 		return "", nil
 	}
 
-	if cfg.LogsLimit == 0 && cfg.Timeout == "" && cfg.Schema == "" && !cfg.ShowTimezoneSelector {
+	if cfg.LogsLimit == 0 && cfg.Timeout == "" && cfg.Schema == "" && !cfg.ShowTimezoneSelector && len(cfg.LokiStacks) == 0 {
 		return "", nil
 	}
 
@@ -167,6 +203,25 @@ func marshalLoggingPluginConfig(cfg *uiv1alpha1.LoggingConfig) (string, error) {
 		ShowTimezoneSelector: cfg.ShowTimezoneSelector,
 	}
 
+	// The list of sources is only rendered when the LokiStacks are
+	// explicitly configured: the plugin uses the "backend" proxy otherwise.
+	if len(cfg.LokiStacks) > 0 {
+		for _, source := range sources {
+			tenants := make([]string, 0, len(source.Tenants))
+			for _, tenant := range source.Tenants {
+				tenants = append(tenants, string(tenant))
+			}
+
+			pluginCfg.LokiStacks = append(pluginCfg.LokiStacks, loggingLokiStackConfig{
+				Name:        source.Name,
+				Namespace:   source.Namespace,
+				DisplayName: source.DisplayName,
+				ProxyAlias:  source.ProxyAlias,
+				Tenants:     tenants,
+			})
+		}
+	}
+
 	buf := &bytes.Buffer{}
 	if err := yaml.NewEncoder(buf).Encode(pluginCfg); err != nil {
 		return "", err
@@ -189,6 +244,22 @@ func parseTimeoutValue(timeout string) (time.Duration, error) {
 	return time.Duration(seconds) * time.Second, nil
 }
 
+// loggingTenants returns the tenants of the LokiStacks. A cluster role
+// granting access to the logs is created for each of them.
+func loggingTenants(sources []lokiStackSource) []uiv1alpha1.LoggingTenant {
+	var tenants []uiv1alpha1.LoggingTenant
+	for _, tenant := range allLoggingTenants {
+		for _, source := range sources {
+			if slices.Contains(source.Tenants, tenant) {
+				tenants = append(tenants, tenant)
+				break
+			}
+		}
+	}
+
+	return tenants
+}
+
 func loggingClusterRole(tenant string) *rbacv1.ClusterRole {
 	return &rbacv1.ClusterRole{
 		TypeMeta: metav1.TypeMeta{
@@ -221,6 +292,79 @@ var lokiStackResource = schema.GroupVersionResource{
 	Group: "loki.grafana.com", Version: "v1", Resource: "lokistacks",
 }
 
+// getLokiStackSources returns the LokiStacks queried by the logging plugin.
+// When no list of LokiStacks is configured, it falls back to the Loki proxy
+// or to the single LokiStack returned by getLokiStack.
+func getLokiStackSources(plugin *uiv1alpha1.UIPlugin, namespace string, ctx context.Context, client dynamic.Interface, logger logr.Logger) ([]lokiStackSource, error) {
+	config := plugin.Spec.Logging
+
+	if config != nil && config.Loki != nil {
+		// The access to Loki is granted like the application logs of a
+		// LokiStack.
+		return []lokiStackSource{
+			{
+				NamespacedName: types.NamespacedName{Name: lokiProxyName, Namespace: namespace},
+				ProxyAlias:     "backend",
+				Tenants:        []uiv1alpha1.LoggingTenant{uiv1alpha1.ApplicationLoggingTenant},
+				Service:        &types.NamespacedName{Name: lokiProxyName, Namespace: namespace},
+			},
+		}, nil
+	}
+
+	if config == nil || len(config.LokiStacks) == 0 {
+		lokiStack, err := getLokiStack(plugin, ctx, client, logger)
+		if err != nil {
+			return nil, err
+		}
+
+		return []lokiStackSource{
+			{
+				NamespacedName: *lokiStack,
+				ProxyAlias:     "backend",
+				Tenants:        allLoggingTenants,
+			},
+		}, nil
+	}
+
+	sources := make([]lokiStackSource, 0, len(config.LokiStacks))
+	for i, ref := range config.LokiStacks {
+		namespace := ref.Namespace
+		if namespace == "" {
+			namespace = OpenshiftLoggingNs
+		}
+
+		if _, err := client.Resource(lokiStackResource).Namespace(namespace).Get(ctx, ref.Name, metav1.GetOptions{}); err != nil {
+			return nil, fmt.Errorf("failed to get LokiStack %s in namespace %s: %w", ref.Name, namespace, err)
+		}
+
+		displayName := ref.DisplayName
+		if displayName == "" {
+			displayName = fmt.Sprintf("%s/%s", namespace, ref.Name)
+		}
+
+		tenants := ref.Tenants
+		if len(tenants) == 0 {
+			tenants = allLoggingTenants
+		}
+
+		// The first LokiStack keeps the alias used by the single LokiStack
+		// configuration.
+		alias := "backend"
+		if i > 0 {
+			alias = fmt.Sprintf("backend-%d", i)
+		}
+
+		sources = append(sources, lokiStackSource{
+			NamespacedName: types.NamespacedName{Name: ref.Name, Namespace: namespace},
+			DisplayName:    displayName,
+			ProxyAlias:     alias,
+			Tenants:        tenants,
+		})
+	}
+
+	return sources, nil
+}
+
 // getLokiStack returns the LokiStack resource to use for the logging plugin.
 // It either uses the explicitly configured LokiStack or discovers one from the cluster.
 func getLokiStack(plugin *uiv1alpha1.UIPlugin, ctx context.Context, client dynamic.Interface, logger logr.Logger) (*types.NamespacedName, error) {
@@ -0,0 +1,202 @@
+package uiplugin
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	osv1 "github.com/openshift/api/console/v1"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	uiv1alpha1 "github.com/rhobs/observability-operator/pkg/apis/uiplugin/v1alpha1"
+)
+
+func newLokiStack(name, namespace string) *unstructured.Unstructured {
+	lokiStack := &unstructured.Unstructured{}
+	lokiStack.SetAPIVersion("loki.grafana.com/v1")
+	lokiStack.SetKind("LokiStack")
+	lokiStack.SetName(name)
+	lokiStack.SetNamespace(namespace)
+	return lokiStack
+}
+
+func TestCreateLoggingPluginInfoLokiStacks(t *testing.T) {
+	dk := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{lokiStackResource: "LokiStackList"},
+		newLokiStack("logging-loki", OpenshiftLoggingNs),
+		newLokiStack("audit-loki", "audit"),
+	)
+
+	plugin := &uiv1alpha1.UIPlugin{
+		ObjectMeta: metav1.ObjectMeta{Name: "logging"},
+		Spec: uiv1alpha1.UIPluginSpec{
+			Type: uiv1alpha1.TypeLogging,
+			Logging: &uiv1alpha1.LoggingConfig{
+				LokiStacks: []uiv1alpha1.LoggingLokiStack{
+					{
+						Name:    "logging-loki",
+						Tenants: []uiv1alpha1.LoggingTenant{uiv1alpha1.ApplicationLoggingTenant},
+					},
+					{
+						Name:        "audit-loki",
+						Namespace:   "audit",
+						DisplayName: "Audit",
+						Tenants:     []uiv1alpha1.LoggingTenant{uiv1alpha1.AuditLoggingTenant},
+					},
+				},
+			},
+		},
+	}
+
+	info, err := createLoggingPluginInfo(plugin, "ns", plugin.Name, "image", nil, context.Background(), dk, logr.Discard(), "")
+	assert.NilError(t, err)
+
+	assert.Equal(t, len(info.Proxies), 2)
+	assert.Equal(t, info.Proxies[0].Alias, "backend")
+	assert.Equal(t, info.Proxies[0].Endpoint.Service.Name, "logging-loki-gateway-http")
+	assert.Equal(t, info.Proxies[1].Alias, "backend-1")
+	assert.Equal(t, info.Proxies[1].Endpoint.Service.Namespace, "audit")
+	assert.Equal(t, info.LegacyProxies[1].Service.Name, "audit-loki-gateway-http")
+
+	assert.DeepEqual(t, info.LoggingTenants, []uiv1alpha1.LoggingTenant{uiv1alpha1.ApplicationLoggingTenant, uiv1alpha1.AuditLoggingTenant})
+
+	config := info.ConfigMap.Data["config.yaml"]
+	for _, expected := range []string{
+		"      displayName: openshift-logging/logging-loki\n      proxyAlias: backend\n      tenants:\n        - application\n",
+		"      displayName: Audit\n      proxyAlias: backend-1\n      tenants:\n        - audit\n",
+	} {
+		assert.Assert(t, strings.Contains(config, expected), "expected %q in:\n%s", expected, config)
+	}
+
+	// A missing LokiStack is reported.
+	plugin.Spec.Logging.LokiStacks[1].Name = "missing"
+	_, err = createLoggingPluginInfo(plugin, "ns", plugin.Name, "image", nil, context.Background(), dk, logr.Discard(), "")
+	assert.ErrorContains(t, err, "failed to get LokiStack missing in namespace audit")
+
+	// Without a list of LokiStacks, a single LokiStack is discovered.
+	plugin.Spec.Logging = &uiv1alpha1.LoggingConfig{}
+	info, err = createLoggingPluginInfo(plugin, "ns", plugin.Name, "image", nil, context.Background(), dk, logr.Discard(), "")
+	assert.NilError(t, err)
+	assert.Equal(t, len(info.Proxies), 1)
+	assert.Equal(t, info.Proxies[0].Endpoint.Service.Name, "logging-loki-gateway-http")
+	assert.Equal(t, len(info.LoggingTenants), 3)
+	assert.Equal(t, info.ConfigMap.Data["config.yaml"], "")
+}
+
+func TestLokiProxy(t *testing.T) {
+	k := fake.NewClientBuilder().WithObjects(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "loki-token", Namespace: "ns"},
+			Data:       map[string][]byte{"token": []byte("abc.def\n")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "loki-basic", Namespace: "ns"},
+			Data:       map[string][]byte{"username": []byte("user"), "password": []byte("pass")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "loki-tls", Namespace: "ns"},
+			Data:       map[string][]byte{"ca.crt": []byte("ca"), "tls.crt": []byte("cert"), "tls.key": []byte("key")},
+		},
+	).Build()
+
+	secretKey := func(name, key string) *corev1.SecretKeySelector {
+		return &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: key}
+	}
+
+	loki := &uiv1alpha1.LokiReference{
+		URL:         "https://loki.example.com/",
+		TenantID:    "team-a",
+		BearerToken: secretKey("loki-token", "token"),
+		TLS: &uiv1alpha1.LokiTLSConfig{
+			CA:   secretKey("loki-tls", "ca.crt"),
+			Cert: secretKey("loki-tls", "tls.crt"),
+			Key:  secretKey("loki-tls", "tls.key"),
+		},
+	}
+
+	cfg, err := getLokiProxyConfig(context.Background(), k, loki, "ns", "nginx", "kube-rbac-proxy")
+	assert.NilError(t, err)
+	for _, expected := range []string{
+		"proxy_pass https://loki.example.com/;\n",
+		"proxy_set_header Authorization \"Bearer abc.def\";\n",
+		"proxy_set_header X-Scope-OrgID \"team-a\";\n",
+		"proxy_ssl_trusted_certificate /etc/loki-proxy/tls/ca.crt;\n",
+		"proxy_ssl_certificate /etc/loki-proxy/tls/tls.crt;\n",
+	} {
+		assert.Assert(t, strings.Contains(cfg.Config, expected), "expected %q in:\n%s", expected, cfg.Config)
+	}
+
+	// nginx is only reachable through kube-rbac-proxy.
+	assert.Assert(t, strings.Contains(cfg.Config, "listen 127.0.0.1:8080;\n"))
+	assert.Assert(t, strings.Contains(cfg.AuthorizationConfig, "resource: application\n"), cfg.AuthorizationConfig)
+
+	deployment := newLokiProxyDeployment("ns", cfg, nil)
+	volumes := deployment.Spec.Template.Spec.Volumes
+	assert.Equal(t, len(volumes[len(volumes)-1].Projected.Sources), 3)
+	assert.Equal(t, deployment.Spec.Template.Annotations[annotationPrefix+"config-hash"], cfg.Hash)
+	containers := deployment.Spec.Template.Spec.Containers
+	assert.Equal(t, containers[0].Image, "kube-rbac-proxy")
+	assert.Equal(t, containers[0].Ports[0].ContainerPort, int32(port))
+	assert.Equal(t, len(containers[1].Ports), 0)
+
+	policy := newLokiProxyNetworkPolicy("ns")
+	assert.Equal(t, policy.Spec.Ingress[0].From[0].NamespaceSelector.MatchLabels[corev1.LabelMetadataName], "openshift-console")
+
+	// Rotating the client certificate rolls out the proxy.
+	secret := &corev1.Secret{}
+	assert.NilError(t, k.Get(context.Background(), types.NamespacedName{Name: "loki-tls", Namespace: "ns"}, secret))
+	secret.Data["tls.crt"] = []byte("new-cert")
+	assert.NilError(t, k.Update(context.Background(), secret))
+	rotated, err := getLokiProxyConfig(context.Background(), k, loki, "ns", "nginx", "kube-rbac-proxy")
+	assert.NilError(t, err)
+	assert.Assert(t, rotated.Hash != cfg.Hash)
+
+	cfg, err = getLokiProxyConfig(context.Background(), k, &uiv1alpha1.LokiReference{
+		URL:          "http://loki.example.com",
+		TenantID:     "team-a",
+		TenantHeader: "X-Tenant",
+		BasicAuth: &uiv1alpha1.LokiBasicAuth{
+			Username: *secretKey("loki-basic", "username"),
+			Password: *secretKey("loki-basic", "password"),
+		},
+	}, "ns", "nginx", "kube-rbac-proxy")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(cfg.Config, "proxy_set_header Authorization \"Basic dXNlcjpwYXNz\";\n"))
+	assert.Assert(t, strings.Contains(cfg.Config, "proxy_set_header X-Tenant \"team-a\";\n"))
+	assert.Assert(t, !strings.Contains(cfg.Config, "proxy_ssl"))
+
+	_, err = getLokiProxyConfig(context.Background(), k, &uiv1alpha1.LokiReference{
+		URL:         "https://loki.example.com",
+		BearerToken: secretKey("loki-basic", "token"),
+	}, "ns", "nginx", "kube-rbac-proxy")
+	assert.ErrorContains(t, err, `key "token" not found in secret ns/loki-basic`)
+
+	// The console proxies the plugin queries to the Loki proxy.
+	plugin := &uiv1alpha1.UIPlugin{
+		ObjectMeta: metav1.ObjectMeta{Name: "logging"},
+		Spec: uiv1alpha1.UIPluginSpec{
+			Type:    uiv1alpha1.TypeLogging,
+			Logging: &uiv1alpha1.LoggingConfig{Loki: loki},
+		},
+	}
+	info, err := createLoggingPluginInfo(plugin, "ns", plugin.Name, "image", nil, context.Background(), nil, logr.Discard(), "")
+	assert.NilError(t, err)
+	assert.Equal(t, len(info.Proxies), 1)
+	assert.Equal(t, info.Proxies[0].Endpoint.Service.Name, lokiProxyName)
+	assert.Equal(t, info.Proxies[0].Endpoint.Service.Port, int32(port))
+	assert.Equal(t, info.Proxies[0].Authorization, osv1.UserToken)
+	assert.Equal(t, info.LegacyProxies[0].Authorize, true)
+	assert.DeepEqual(t, info.LoggingTenants, []uiv1alpha1.LoggingTenant{uiv1alpha1.ApplicationLoggingTenant})
+
+	_, err = getLokiProxyConfig(context.Background(), k, loki, "ns", "nginx", "")
+	assert.ErrorContains(t, err, "no image provided")
+}
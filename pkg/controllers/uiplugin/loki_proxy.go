@@ -0,0 +1,461 @@
+package uiplugin
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/base64"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+	"text/template"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	uiv1alpha1 "github.com/rhobs/observability-operator/pkg/apis/uiplugin/v1alpha1"
+)
+
+const (
+	lokiProxyName       = "logging-loki-proxy"
+	lokiProxyConfigFile = "nginx.conf"
+	lokiProxyConfigDir  = "/etc/loki-proxy/config"
+	lokiProxyTLSDir     = "/etc/loki-proxy/tls"
+	// lokiProxyAuthorizationFile is the configuration of the
+	// kube-rbac-proxy container authorizing the requests of the console.
+	lokiProxyAuthorizationFile = "kube-rbac-proxy.yaml"
+	// lokiProxyUpstreamPort is the local port of nginx. Only the
+	// kube-rbac-proxy container listens on the pod network.
+	lokiProxyUpstreamPort = 8080
+	// consoleNamespace is the namespace of the OpenShift console pods.
+	consoleNamespace = "openshift-console"
+	// systemCAFile is the CA bundle of the UBI based proxy image.
+	systemCAFile = "/etc/pki/tls/certs/ca-bundle.crt"
+)
+
+var (
+	//go:embed config/loki-proxy.conf
+	lokiProxyConfigTmplFile embed.FS
+
+	lokiProxyConfigTmpl = template.Must(template.ParseFS(lokiProxyConfigTmplFile, "config/loki-proxy.conf"))
+
+	// bearerTokenRegexp matches the token68 syntax of RFC 7235 which is
+	// safe to render in the proxy configuration.
+	bearerTokenRegexp = regexp.MustCompile(`^[A-Za-z0-9._~+/-]+=*$`)
+)
+
+// lokiProxyConfig is the configuration of the proxy forwarding the queries
+// of the logging plugin to a Loki URL.
+//
+// The proxy adds its own credentials to the queries: a kube-rbac-proxy
+// container checks that the user forwarded by the console is allowed to
+// read the application logs before they reach nginx.
+type lokiProxyConfig struct {
+	Image              string
+	KubeRBACProxyImage string
+	// Config is the rendered proxy configuration. It contains the
+	// credentials and is stored in a secret.
+	Config string
+	// AuthorizationConfig is the configuration of kube-rbac-proxy.
+	AuthorizationConfig string
+	// Hash changes whenever the configuration or the TLS secrets change.
+	Hash string
+	TLS  *uiv1alpha1.LokiTLSConfig
+}
+
+// getLokiProxyConfig renders the configuration of the Loki proxy. The
+// secrets are read from the given namespace.
+func getLokiProxyConfig(ctx context.Context, k client.Client, loki *uiv1alpha1.LokiReference, namespace, image, kubeRBACProxyImage string) (*lokiProxyConfig, error) {
+	if kubeRBACProxyImage == "" {
+		return nil, fmt.Errorf("no image provided for the authorization proxy of the Loki proxy")
+	}
+
+	authorization := ""
+	switch {
+	case loki.BearerToken != nil:
+		token, err := getSecretValue(ctx, k, namespace, *loki.BearerToken)
+		if err != nil {
+			return nil, err
+		}
+		if !bearerTokenRegexp.MatchString(token) {
+			return nil, fmt.Errorf("invalid bearer token in secret %s/%s", namespace, loki.BearerToken.Name)
+		}
+		authorization = "Bearer " + token
+
+	case loki.BasicAuth != nil:
+		username, err := getSecretValue(ctx, k, namespace, loki.BasicAuth.Username)
+		if err != nil {
+			return nil, err
+		}
+		password, err := getSecretValue(ctx, k, namespace, loki.BasicAuth.Password)
+		if err != nil {
+			return nil, err
+		}
+		authorization = "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+	}
+
+	tenantHeader := loki.TenantHeader
+	if tenantHeader == "" {
+		tenantHeader = "X-Scope-OrgID"
+	}
+
+	data := map[string]any{
+		"Port":               lokiProxyUpstreamPort,
+		"URL":                strings.TrimSuffix(loki.URL, "/") + "/",
+		"Authorization":      authorization,
+		"TenantID":           loki.TenantID,
+		"TenantHeader":       tenantHeader,
+		"HTTPS":              strings.HasPrefix(loki.URL, "https://"),
+		"InsecureSkipVerify": false,
+		"CAFile":             systemCAFile,
+		"ClientCertificate":  false,
+		"TLSDir":             lokiProxyTLSDir,
+	}
+
+	h := fnv.New32a()
+	if tls := loki.TLS; tls != nil {
+		data["InsecureSkipVerify"] = tls.InsecureSkipVerify
+		data["ClientCertificate"] = tls.Cert != nil && tls.Key != nil
+		if tls.CA != nil {
+			data["CAFile"] = lokiProxyTLSDir + "/ca.crt"
+		}
+
+		for _, selector := range []*corev1.SecretKeySelector{tls.CA, tls.Cert, tls.Key} {
+			if selector == nil {
+				continue
+			}
+			value, err := getSecretValue(ctx, k, namespace, *selector)
+			if err != nil {
+				return nil, err
+			}
+			h.Write([]byte(value))
+			h.Write(hashSeparator)
+		}
+	}
+
+	w := bytes.NewBuffer(nil)
+	if err := lokiProxyConfigTmpl.Execute(w, data); err != nil {
+		return nil, err
+	}
+	h.Write(w.Bytes())
+
+	authorizationConfig, err := yaml.Marshal(newKubeRBACProxyConfig(lokiProxyAuthorization))
+	if err != nil {
+		return nil, err
+	}
+	h.Write(authorizationConfig)
+
+	return &lokiProxyConfig{
+		Image:               image,
+		KubeRBACProxyImage:  kubeRBACProxyImage,
+		Config:              w.String(),
+		AuthorizationConfig: string(authorizationConfig),
+		Hash:                fmt.Sprintf("%x", h.Sum(nil)),
+		TLS:                 loki.TLS,
+	}, nil
+}
+
+// lokiProxyAuthorization is the permission required to query Loki through
+// the proxy. It is granted by the cluster-logging-application-view cluster
+// role, like the application logs of a LokiStack.
+var lokiProxyAuthorization = proxyAuthorization{
+	Group:    "loki.grafana.com",
+	Resource: string(uiv1alpha1.ApplicationLoggingTenant),
+	Name:     "logs",
+}
+
+// lokiSecretNames returns the names of the secrets referenced by the Loki
+// configuration.
+func lokiSecretNames(loki *uiv1alpha1.LokiReference) []string {
+	var names []string
+	if loki.BearerToken != nil {
+		names = append(names, loki.BearerToken.Name)
+	}
+	if loki.BasicAuth != nil {
+		names = append(names, loki.BasicAuth.Username.Name, loki.BasicAuth.Password.Name)
+	}
+	if tls := loki.TLS; tls != nil {
+		for _, selector := range []*corev1.SecretKeySelector{tls.CA, tls.Cert, tls.Key} {
+			if selector != nil {
+				names = append(names, selector.Name)
+			}
+		}
+	}
+
+	return names
+}
+
+func getSecretValue(ctx context.Context, k client.Client, namespace string, selector corev1.SecretKeySelector) (string, error) {
+	secret := &corev1.Secret{}
+	if err := k.Get(ctx, types.NamespacedName{Name: selector.Name, Namespace: namespace}, secret); err != nil {
+		return "", fmt.Errorf("failed to get secret %s/%s: %w", namespace, selector.Name, err)
+	}
+
+	value, found := secret.Data[selector.Key]
+	if !found {
+		return "", fmt.Errorf("key %q not found in secret %s/%s", selector.Key, namespace, selector.Name)
+	}
+
+	return strings.TrimSpace(string(value)), nil
+}
+
+func newLokiProxySecret(namespace string, cfg *lokiProxyConfig) *corev1.Secret {
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      lokiProxyName,
+			Namespace: namespace,
+			Labels:    componentLabels(lokiProxyName),
+		},
+		StringData: map[string]string{
+			lokiProxyConfigFile:        cfg.Config,
+			lokiProxyAuthorizationFile: cfg.AuthorizationConfig,
+		},
+	}
+}
+
+// newLokiProxyNetworkPolicy only lets the console reach the Loki proxy.
+func newLokiProxyNetworkPolicy(namespace string) *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: networkingv1.SchemeGroupVersion.String(),
+			Kind:       "NetworkPolicy",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      lokiProxyName,
+			Namespace: namespace,
+			Labels:    componentLabels(lokiProxyName),
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: componentLabels(lokiProxyName),
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{
+					From: []networkingv1.NetworkPolicyPeer{
+						{
+							NamespaceSelector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{
+									corev1.LabelMetadataName: consoleNamespace,
+								},
+							},
+						},
+					},
+					Ports: []networkingv1.NetworkPolicyPort{
+						{
+							Protocol: ptr.To(corev1.ProtocolTCP),
+							Port:     ptr.To(intstr.FromInt32(port)),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func newLokiProxyService(namespace string) *corev1.Service {
+	return &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Service",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      lokiProxyName,
+			Namespace: namespace,
+			Labels:    componentLabels(lokiProxyName),
+			Annotations: map[string]string{
+				"service.alpha.openshift.io/serving-cert-secret-name": lokiProxyName + "-tls",
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{
+					Port:       port,
+					Name:       "https",
+					Protocol:   corev1.ProtocolTCP,
+					TargetPort: intstr.FromInt32(port),
+				},
+			},
+			Selector: componentLabels(lokiProxyName),
+			Type:     corev1.ServiceTypeClusterIP,
+		},
+	}
+}
+
+// lokiProxyTLSVolume projects the TLS secrets of the Loki connection at the
+// paths expected by the proxy configuration.
+func lokiProxyTLSVolume(tls *uiv1alpha1.LokiTLSConfig) *corev1.Volume {
+	if tls == nil {
+		return nil
+	}
+
+	var sources []corev1.VolumeProjection
+	for _, file := range []struct {
+		path     string
+		selector *corev1.SecretKeySelector
+	}{
+		{path: "ca.crt", selector: tls.CA},
+		{path: "tls.crt", selector: tls.Cert},
+		{path: "tls.key", selector: tls.Key},
+	} {
+		if file.selector == nil {
+			continue
+		}
+		sources = append(sources, corev1.VolumeProjection{
+			Secret: &corev1.SecretProjection{
+				LocalObjectReference: file.selector.LocalObjectReference,
+				Items:                []corev1.KeyToPath{{Key: file.selector.Key, Path: file.path}},
+			},
+		})
+	}
+	if len(sources) == 0 {
+		return nil
+	}
+
+	return &corev1.Volume{
+		Name: "loki-tls",
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{
+				Sources:     sources,
+				DefaultMode: ptr.To(int32(0440)),
+			},
+		},
+	}
+}
+
+func newLokiProxyDeployment(namespace string, cfg *lokiProxyConfig, config *uiv1alpha1.DeploymentConfig) *appsv1.Deployment {
+	volumes := []corev1.Volume{
+		{
+			Name: "config",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: lokiProxyName,
+				},
+			},
+		},
+		{
+			Name: servingCertVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: lokiProxyName + "-tls",
+				},
+			},
+		},
+		{
+			Name: "tmp",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+	}
+	volumeMounts := []corev1.VolumeMount{
+		{Name: "config", ReadOnly: true, MountPath: lokiProxyConfigDir},
+		{Name: "tmp", MountPath: "/tmp"},
+	}
+
+	if volume := lokiProxyTLSVolume(cfg.TLS); volume != nil {
+		volumes = append(volumes, *volume)
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: volume.Name, ReadOnly: true, MountPath: lokiProxyTLSDir})
+	}
+
+	nodeSelector, tolerations := createNodeSelectorAndTolerations(config)
+
+	return &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: appsv1.SchemeGroupVersion.String(),
+			Kind:       "Deployment",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      lokiProxyName,
+			Namespace: namespace,
+			Labels:    componentLabels(lokiProxyName),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To(int32(1)),
+			Selector: &metav1.LabelSelector{
+				MatchLabels: componentLabels(lokiProxyName),
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: componentLabels(lokiProxyName),
+					Annotations: map[string]string{
+						annotationPrefix + "config-hash": cfg.Hash,
+					},
+				},
+				Spec: corev1.PodSpec{
+					// kube-rbac-proxy authenticates and authorizes the users
+					// with the token of the service account.
+					ServiceAccountName: lokiProxyName + serviceAccountSuffix,
+					Containers: []corev1.Container{
+						{
+							Name:  "kube-rbac-proxy",
+							Image: cfg.KubeRBACProxyImage,
+							Args: []string{
+								fmt.Sprintf("--secure-listen-address=0.0.0.0:%d", port),
+								fmt.Sprintf("--upstream=http://127.0.0.1:%d/", lokiProxyUpstreamPort),
+								fmt.Sprintf("--config-file=%s/%s", lokiProxyConfigDir, lokiProxyAuthorizationFile),
+								"--tls-cert-file=/var/serving-cert/tls.crt",
+								"--tls-private-key-file=/var/serving-cert/tls.key",
+							},
+							Ports: []corev1.ContainerPort{
+								{
+									ContainerPort: port,
+									Name:          "https",
+								},
+							},
+							TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+							SecurityContext: &corev1.SecurityContext{
+								RunAsNonRoot:             ptr.To(true),
+								AllowPrivilegeEscalation: ptr.To(false),
+								ReadOnlyRootFilesystem:   ptr.To(true),
+								Capabilities: &corev1.Capabilities{
+									Drop: []corev1.Capability{"ALL"},
+								},
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{Name: "config", ReadOnly: true, MountPath: lokiProxyConfigDir},
+								{Name: servingCertVolumeName, ReadOnly: true, MountPath: "/var/serving-cert"},
+							},
+						},
+						{
+							Name:                     "proxy",
+							Image:                    cfg.Image,
+							Command:                  []string{"nginx", "-c", lokiProxyConfigDir + "/" + lokiProxyConfigFile, "-g", "daemon off;"},
+							TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+							SecurityContext: &corev1.SecurityContext{
+								RunAsNonRoot:             ptr.To(true),
+								AllowPrivilegeEscalation: ptr.To(false),
+								ReadOnlyRootFilesystem:   ptr.To(true),
+								Capabilities: &corev1.Capabilities{
+									Drop: []corev1.Capability{"ALL"},
+								},
+							},
+							VolumeMounts: volumeMounts,
+						},
+					},
+					Volumes:      volumes,
+					NodeSelector: nodeSelector,
+					Tolerations:  tolerations,
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsNonRoot: ptr.To(true),
+						SeccompProfile: &corev1.SeccompProfile{
+							Type: corev1.SeccompProfileTypeRuntimeDefault,
+						},
+					},
+				},
+			},
+		},
+	}
+}
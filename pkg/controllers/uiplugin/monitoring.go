@@ -1,7 +1,9 @@
 package uiplugin
 
 import (
+	"context"
 	"fmt"
+	"hash/fnv"
 	"strings"
 
 	osv1 "github.com/openshift/api/console/v1"
@@ -12,17 +14,25 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	monv1alpha1 "github.com/rhobs/observability-operator/pkg/apis/monitoring/v1alpha1"
 	uiv1alpha1 "github.com/rhobs/observability-operator/pkg/apis/uiplugin/v1alpha1"
 )
 
 const (
 	persesServiceName   = "perses"
 	PersesUserFSGroupID = int64(65534)
+	persesDatabaseDir   = "/etc/perses-database"
 )
 
+// persesDatabaseSecretKeys are the keys of the secret holding the connection
+// settings of the Perses SQL database.
+var persesDatabaseSecretKeys = []string{"address", "username", "password"}
+
 /*
 Requirements for ACM enablement
 1. UIPlugin configuration requires acm.enabled, acm.thanosQuerier.Url, and acm.alertmanager.Url
@@ -43,6 +53,10 @@ func validateACMConfig(config *uiv1alpha1.MonitoringConfig) bool {
 	return isValidAcmAlertingConfig && enabled
 }
 
+func validateBackendsConfig(config *uiv1alpha1.MonitoringConfig) bool {
+	return config.Backends != nil && (config.Backends.MonitoringStack != nil || config.Backends.ThanosQuerier != nil)
+}
+
 func validatePersesConfig(config *uiv1alpha1.MonitoringConfig) bool {
 	return config.Perses != nil && config.Perses.Enabled
 }
@@ -75,6 +89,7 @@ func validateIncidentsConfig(config *uiv1alpha1.MonitoringConfig, clusterVersion
 func addFeatureFlags(plugin *UIPluginInfo, features []string) {
 	featureField := fmt.Sprintf("-features=%s", strings.Join(features, ","))
 	plugin.ExtraArgs = append(plugin.ExtraArgs, featureField)
+	plugin.Features = features
 }
 
 func getBasePluginInfo(namespace, name, image string) *UIPluginInfo {
@@ -143,58 +158,138 @@ func addPersesProxy(pluginInfo *UIPluginInfo, namespace string) {
 }
 
 func addAcmAlertingProxy(pluginInfo *UIPluginInfo, name string, namespace string, config *uiv1alpha1.MonitoringConfig) {
-	pluginInfo.ExtraArgs = append(pluginInfo.ExtraArgs,
-		fmt.Sprintf("-alertmanager=%s", config.ACM.Alertmanager.Url),
-		fmt.Sprintf("-thanos-querier=%s", config.ACM.ThanosQuerier.Url),
-	)
-	pluginInfo.Proxies = append(pluginInfo.Proxies,
-		osv1.ConsolePluginProxy{
-			Alias:         "alertmanager-proxy",
-			Authorization: "UserToken",
-			Endpoint: osv1.ConsolePluginProxyEndpoint{
-				Type: osv1.ProxyTypeService,
-				Service: &osv1.ConsolePluginProxyServiceConfig{
-					Name:      name,
-					Namespace: namespace,
-					Port:      9444,
-				},
-			},
-		},
-		osv1.ConsolePluginProxy{
-			Alias:         "thanos-proxy",
+	addMonitoringProxies(pluginInfo, name, namespace, config.ACM.Alertmanager.Url, config.ACM.ThanosQuerier.Url)
+}
+
+// addMonitoringProxies configures the plugin backend to proxy the requests
+// to the given Alertmanager and Thanos Querier URLs. Empty URLs are skipped.
+func addMonitoringProxies(pluginInfo *UIPluginInfo, name string, namespace string, alertmanagerURL, thanosQuerierURL string) {
+	for _, p := range []struct {
+		flag  string
+		url   string
+		alias string
+		port  int32
+	}{
+		{flag: "alertmanager", url: alertmanagerURL, alias: "alertmanager-proxy", port: 9444},
+		{flag: "thanos-querier", url: thanosQuerierURL, alias: "thanos-proxy", port: 9445},
+	} {
+		if p.url == "" {
+			continue
+		}
+
+		pluginInfo.ExtraArgs = append(pluginInfo.ExtraArgs, fmt.Sprintf("-%s=%s", p.flag, p.url))
+		pluginInfo.Proxies = append(pluginInfo.Proxies, osv1.ConsolePluginProxy{
+			Alias:         p.alias,
 			Authorization: "UserToken",
 			Endpoint: osv1.ConsolePluginProxyEndpoint{
 				Type: osv1.ProxyTypeService,
 				Service: &osv1.ConsolePluginProxyServiceConfig{
 					Name:      name,
 					Namespace: namespace,
-					Port:      9445,
+					Port:      p.port,
 				},
 			},
-		},
-	)
-	pluginInfo.LegacyProxies = append(pluginInfo.LegacyProxies,
-		osv1alpha1.ConsolePluginProxy{
-			Type:      "Service",
-			Alias:     "alertmanager-proxy",
-			Authorize: true,
-			Service: osv1alpha1.ConsolePluginProxyServiceConfig{
-				Name:      name,
-				Namespace: namespace,
-				Port:      9444,
-			},
-		},
-		osv1alpha1.ConsolePluginProxy{
+		})
+		pluginInfo.LegacyProxies = append(pluginInfo.LegacyProxies, osv1alpha1.ConsolePluginProxy{
 			Type:      "Service",
-			Alias:     "thanos-proxy",
+			Alias:     p.alias,
 			Authorize: true,
 			Service: osv1alpha1.ConsolePluginProxyServiceConfig{
 				Name:      name,
 				Namespace: namespace,
-				Port:      9445,
+				Port:      p.port,
 			},
-		},
-	)
+		})
+	}
+}
+
+// monitoringBackends holds the endpoints of the MonitoringStack and
+// ThanosQuerier referenced by a MonitoringBackendsReference.
+type monitoringBackends struct {
+	AlertmanagerURL string
+	PrometheusURL   string
+}
+
+// getMonitoringBackends resolves the endpoints of the referenced
+// MonitoringStack and ThanosQuerier. The ThanosQuerier takes precedence over
+// the Prometheus of the MonitoringStack for querying metrics.
+func getMonitoringBackends(ctx context.Context, k client.Client, ref *uiv1alpha1.MonitoringBackendsReference) (*monitoringBackends, error) {
+	backends := &monitoringBackends{}
+
+	if ref.MonitoringStack != nil {
+		ms := &monv1alpha1.MonitoringStack{}
+		if err := k.Get(ctx, types.NamespacedName{Name: ref.MonitoringStack.Name, Namespace: ref.MonitoringStack.Namespace}, ms); err != nil {
+			return nil, fmt.Errorf("failed to get MonitoringStack %s/%s: %w", ref.MonitoringStack.Namespace, ref.MonitoringStack.Name, err)
+		}
+
+		prometheusScheme, alertmanagerScheme := "http", "http"
+		if ms.Spec.PrometheusConfig != nil && ms.Spec.PrometheusConfig.WebTLSConfig != nil {
+			prometheusScheme = "https"
+		}
+		if ms.Spec.AlertmanagerConfig.WebTLSConfig != nil {
+			alertmanagerScheme = "https"
+		}
+
+		backends.PrometheusURL = fmt.Sprintf("%s://%s-prometheus.%s.svc:9090", prometheusScheme, ms.Name, ms.Namespace)
+		if !ms.Spec.AlertmanagerConfig.Disabled {
+			backends.AlertmanagerURL = fmt.Sprintf("%s://%s-alertmanager.%s.svc:9093", alertmanagerScheme, ms.Name, ms.Namespace)
+		}
+	}
+
+	if ref.ThanosQuerier != nil {
+		tq := &monv1alpha1.ThanosQuerier{}
+		if err := k.Get(ctx, types.NamespacedName{Name: ref.ThanosQuerier.Name, Namespace: ref.ThanosQuerier.Namespace}, tq); err != nil {
+			return nil, fmt.Errorf("failed to get ThanosQuerier %s/%s: %w", ref.ThanosQuerier.Namespace, ref.ThanosQuerier.Name, err)
+		}
+
+		scheme := "http"
+		if tq.Spec.WebTLSConfig != nil {
+			scheme = "https"
+		}
+		backends.PrometheusURL = fmt.Sprintf("%s://thanos-querier-%s.%s.svc:10902", scheme, tq.Name, tq.Namespace)
+	}
+
+	return backends, nil
+}
+
+// addMonitoringBackends configures the proxies of the plugin for the
+// MonitoringStack and ThanosQuerier referenced by the configuration.
+//
+// The backends don't authenticate the requests: the proxies are routed
+// through the authorization proxy which checks that the user may access
+// the "api" subresource of the referenced resource.
+func addMonitoringBackends(ctx context.Context, k client.Client, pluginInfo *UIPluginInfo, name string, namespace string, ref *uiv1alpha1.MonitoringBackendsReference) error {
+	backends, err := getMonitoringBackends(ctx, k, ref)
+	if err != nil {
+		return err
+	}
+
+	addMonitoringProxies(pluginInfo, name, namespace, backends.AlertmanagerURL, backends.PrometheusURL)
+
+	if backends.AlertmanagerURL != "" {
+		pluginInfo.ProxyAuthorization = append(pluginInfo.ProxyAuthorization,
+			newMonitoringBackendAuthorization("alertmanager-proxy", "monitoringstacks", ref.MonitoringStack))
+	}
+	if ref.ThanosQuerier != nil {
+		pluginInfo.ProxyAuthorization = append(pluginInfo.ProxyAuthorization,
+			newMonitoringBackendAuthorization("thanos-proxy", "thanosqueriers", ref.ThanosQuerier))
+	} else {
+		pluginInfo.ProxyAuthorization = append(pluginInfo.ProxyAuthorization,
+			newMonitoringBackendAuthorization("thanos-proxy", "monitoringstacks", ref.MonitoringStack))
+	}
+
+	return nil
+}
+
+func newMonitoringBackendAuthorization(alias, resource string, ref *uiv1alpha1.NamespacedReference) proxyAuthorization {
+	return proxyAuthorization{
+		Alias:       alias,
+		Group:       monv1alpha1.GroupVersion.Group,
+		Resource:    resource,
+		Subresource: "api",
+		Name:        ref.Name,
+		Namespace:   ref.Namespace,
+	}
 }
 
 func createMonitoringPluginInfo(plugin *uiv1alpha1.UIPlugin, namespace, name, image string, features []string, clusterVersion string, healthAnalyzerImage string, persesImage string) (*UIPluginInfo, error) {
@@ -208,8 +303,9 @@ func createMonitoringPluginInfo(plugin *uiv1alpha1.UIPlugin, namespace, name, im
 	isValidPersesConfig := validatePersesConfig(config)
 	isValidIncidentsConfig := validateIncidentsConfig(config, clusterVersion)
 	isValidHealthAnalyzerConfig := validateHealthanalyzerConfig(config, clusterVersion)
+	isValidBackendsConfig := validateBackendsConfig(config)
 
-	atLeastOneValidConfig := isValidAcmConfig || isValidPersesConfig || isValidIncidentsConfig || isValidHealthAnalyzerConfig
+	atLeastOneValidConfig := isValidAcmConfig || isValidPersesConfig || isValidIncidentsConfig || isValidHealthAnalyzerConfig || isValidBackendsConfig
 
 	pluginInfo := getBasePluginInfo(namespace, name, image)
 	if !atLeastOneValidConfig {
@@ -236,6 +332,11 @@ func createMonitoringPluginInfo(plugin *uiv1alpha1.UIPlugin, namespace, name, im
 		pluginInfo.HealthAnalyzerImage = healthAnalyzerImage
 		features = append(features, "cluster-health-analyzer")
 	}
+	if isValidBackendsConfig {
+		// The frontend only queries the alertmanager and thanos-querier
+		// proxies with this feature.
+		features = append(features, "acm-alerting")
+	}
 	addFeatureFlags(pluginInfo, features)
 
 	return pluginInfo, nil
@@ -284,9 +385,64 @@ func newMonitoringService(name string, namespace string) *corev1.Service {
 	}
 }
 
-func newPerses(namespace string, persesImage string) *persesv1alpha2.Perses {
+// getPersesDatabaseHash checks that the secret of the Perses SQL database
+// holds the connection settings and returns a hash of its content.
+func getPersesDatabaseHash(ctx context.Context, k client.Client, sql *uiv1alpha1.PersesSQLStorage, namespace string) (string, error) {
+	h := fnv.New32a()
+	for _, key := range persesDatabaseSecretKeys {
+		value, err := getSecretValue(ctx, k, namespace, corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: sql.Secret},
+			Key:                  key,
+		})
+		if err != nil {
+			return "", err
+		}
+		h.Write([]byte(value))
+		h.Write(hashSeparator)
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// persesPodLabels select the pods of the Perses instance deployed by the
+// Perses operator.
+var persesPodLabels = map[string]string{
+	"app.kubernetes.io/name":     "perses",
+	"app.kubernetes.io/instance": "perses",
+}
+
+func persesReplicas(info UIPluginInfo) int32 {
+	if info.PersesConfig == nil || info.PersesConfig.Replicas == nil {
+		return 1
+	}
+	return *info.PersesConfig.Replicas
+}
+
+func newPerses(namespace string, pluginInfo UIPluginInfo, caps clusterCapabilities) *persesv1alpha2.Perses {
 	name := "perses"
-	return &persesv1alpha2.Perses{
+	// The service CA of OpenShift signs the certificates of the backend
+	// services. Elsewhere, the serving certificate of Perses comes with its
+	// CA.
+	caCert := persesv1alpha2.Certificate{
+		SecretSource: persesv1alpha2.SecretSource{
+			Type:      persesv1alpha2.SecretSourceTypeConfigMap,
+			Name:      ptr.To(serviceCAConfigMapName),
+			Namespace: ptr.To(namespace),
+		},
+		CertPath: serviceCAConfigMapKey,
+	}
+	if !caps.openShift {
+		caCert = persesv1alpha2.Certificate{
+			SecretSource: persesv1alpha2.SecretSource{
+				Type:      persesv1alpha2.SecretSourceTypeSecret,
+				Name:      ptr.To(name),
+				Namespace: ptr.To(namespace),
+			},
+			CertPath: servingCertificateCAKey,
+		}
+	}
+
+	perses := &persesv1alpha2.Perses{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: persesv1alpha2.GroupVersion.String(),
 			Kind:       "Perses",
@@ -331,7 +487,7 @@ func newPerses(namespace string, persesImage string) *persesv1alpha2.Perses {
 					},
 				},
 			},
-			Image:         ptr.To(persesImage),
+			Image:         ptr.To(pluginInfo.PersesImage),
 			ContainerPort: ptr.To(int32(8080)),
 			// Set PodSecurityContext to run as non-root user (nobody/65534) for OpenShift SCC compatibility
 			PodSecurityContext: &corev1.PodSecurityContext{
@@ -350,26 +506,12 @@ func newPerses(namespace string, persesImage string) *persesv1alpha2.Perses {
 					CertPath:       "tls.crt",
 					PrivateKeyPath: ptr.To("tls.key"),
 				},
-				CaCert: &persesv1alpha2.Certificate{
-					SecretSource: persesv1alpha2.SecretSource{
-						Type:      persesv1alpha2.SecretSourceTypeConfigMap,
-						Name:      ptr.To("openshift-service-ca.crt"),
-						Namespace: ptr.To(namespace),
-					},
-					CertPath: "service-ca.crt",
-				},
+				CaCert: ptr.To(caCert),
 			},
 			Client: &persesv1alpha2.Client{
 				TLS: &persesv1alpha2.TLS{
 					Enable: ptr.To(true),
-					CaCert: &persesv1alpha2.Certificate{
-						SecretSource: persesv1alpha2.SecretSource{
-							Type:      persesv1alpha2.SecretSourceTypeConfigMap,
-							Name:      ptr.To("openshift-service-ca.crt"),
-							Namespace: ptr.To(namespace),
-						},
-						CertPath: "service-ca.crt",
-					},
+					CaCert: ptr.To(caCert),
 				},
 				KubernetesAuth: &persesv1alpha2.KubernetesAuth{
 					Enable: ptr.To(true),
@@ -383,6 +525,76 @@ func newPerses(namespace string, persesImage string) *persesv1alpha2.Perses {
 			ServiceAccountName: ptr.To("perses" + serviceAccountSuffix),
 		},
 	}
+
+	// The resources and scheduling settings of the plugin deployment apply
+	// to Perses unless overridden. The Perses CRD doesn't support topology
+	// spread constraints and priority classes.
+	perses.Spec.NodeSelector, perses.Spec.Tolerations = createNodeSelectorAndTolerations(pluginInfo.DeploymentConfig)
+	if deployment := pluginInfo.DeploymentConfig; deployment != nil {
+		perses.Spec.Resources = deployment.Resources
+	}
+
+	cfg := pluginInfo.PersesConfig
+	if cfg == nil {
+		return perses
+	}
+
+	perses.Spec.Replicas = cfg.Replicas
+	if cfg.Resources != nil {
+		perses.Spec.Resources = cfg.Resources
+	}
+	if cfg.NodeSelector != nil {
+		perses.Spec.NodeSelector = cfg.NodeSelector
+	}
+	if cfg.Tolerations != nil {
+		perses.Spec.Tolerations = cfg.Tolerations
+	}
+	perses.Spec.Affinity = cfg.Affinity
+
+	if cfg.Storage == nil {
+		return perses
+	}
+
+	if cfg.Storage.PersistentVolumeClaim != nil {
+		perses.Spec.Storage = &persesv1alpha2.StorageConfiguration{
+			PersistentVolumeClaimTemplate: cfg.Storage.PersistentVolumeClaim,
+		}
+	}
+
+	if sql := cfg.Storage.SQL; sql != nil {
+		// The credentials are read from the mounted secret.
+		perses.Spec.Config.Database = persesconfig.Database{
+			SQL: &persesconfig.SQL{
+				Net:                  "tcp",
+				AddrFile:             persesDatabaseDir + "/address",
+				UserFile:             persesDatabaseDir + "/username",
+				PasswordFile:         persesDatabaseDir + "/password",
+				DBName:               sql.Database,
+				AllowNativePasswords: true,
+				CheckConnLiveness:    true,
+				ParseTime:            true,
+			},
+		}
+		perses.Spec.Volumes = []corev1.Volume{{
+			Name: "database",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: sql.Secret},
+			},
+		}}
+		perses.Spec.VolumeMounts = []corev1.VolumeMount{{
+			Name:      "database",
+			MountPath: persesDatabaseDir,
+			ReadOnly:  true,
+		}}
+		// Rotating the credentials rolls out Perses.
+		perses.Spec.Metadata = &persesv1alpha2.Metadata{
+			Annotations: map[string]string{
+				annotationPrefix + "config-hash": pluginInfo.PersesDatabaseHash,
+			},
+		}
+	}
+
+	return perses
 }
 
 func newPersesClusterRole() *rbacv1.ClusterRole {
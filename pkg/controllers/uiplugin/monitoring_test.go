@@ -1,13 +1,22 @@
 package uiplugin
 
 import (
+	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"testing"
 
+	persesv1alpha2 "github.com/rhobs/perses-operator/api/v1alpha2"
 	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	monv1alpha1 "github.com/rhobs/observability-operator/pkg/apis/monitoring/v1alpha1"
 	uiv1alpha1 "github.com/rhobs/observability-operator/pkg/apis/uiplugin/v1alpha1"
 )
 
@@ -439,6 +448,8 @@ func TestCreateMonitoringPluginInfo(t *testing.T) {
 				assert.Equal(t, actualFlags.acmAlerting, tc.featureFlags.acmAlerting, "ACM alerting flag mismatch")
 				assert.Equal(t, actualFlags.perses, tc.featureFlags.perses, "Perses flag mismatch")
 				assert.Equal(t, actualFlags.incidents, tc.featureFlags.incidents, "Incidents flag mismatch")
+				assert.Equal(t, slices.Contains(pluginInfo.Features, "acm-alerting"), tc.featureFlags.acmAlerting, "ACM alerting feature mismatch")
+				assert.Equal(t, slices.Contains(pluginInfo.Features, "perses-dashboards"), tc.featureFlags.perses, "Perses feature mismatch")
 
 				assert.Equal(t, containsHealthAnalyzer(pluginInfo), tc.components.healthAnalyzer, "Health analyzer mismatch")
 				assert.Equal(t, containsPerses(pluginInfo), tc.components.persesImage, "Perses image mismatch")
@@ -511,3 +522,137 @@ func TestCreateMonitoringPluginInfo(t *testing.T) {
 		assert.Assert(t, validateIncidentsConfig(pluginConfigIncidents.Spec.Monitoring, "4.18.0") == false)
 	})
 }
+
+func TestAddMonitoringBackends(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NilError(t, monv1alpha1.AddToScheme(scheme))
+
+	k := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&monv1alpha1.MonitoringStack{
+			ObjectMeta: metav1.ObjectMeta{Name: "ms", Namespace: "team-a"},
+		},
+		&monv1alpha1.ThanosQuerier{
+			ObjectMeta: metav1.ObjectMeta{Name: "tq", Namespace: "team-a"},
+			Spec: monv1alpha1.ThanosQuerierSpec{
+				WebTLSConfig: &monv1alpha1.WebTLSConfig{},
+			},
+		},
+	).Build()
+
+	backends := &uiv1alpha1.MonitoringBackendsReference{
+		MonitoringStack: &uiv1alpha1.NamespacedReference{Name: "ms", Namespace: "team-a"},
+	}
+
+	info := &UIPluginInfo{}
+	assert.NilError(t, addMonitoringBackends(context.Background(), k, info, "monitoring", "ns", backends))
+	assert.DeepEqual(t, info.ExtraArgs, []string{
+		"-alertmanager=http://ms-alertmanager.team-a.svc:9093",
+		"-thanos-querier=http://ms-prometheus.team-a.svc:9090",
+	})
+	assert.Equal(t, len(info.Proxies), 2)
+	assert.Equal(t, info.Proxies[0].Alias, "alertmanager-proxy")
+	assert.Equal(t, info.Proxies[1].Alias, "thanos-proxy")
+	assert.DeepEqual(t, info.ProxyAuthorization, []proxyAuthorization{
+		{Alias: "alertmanager-proxy", Group: "monitoring.rhobs", Resource: "monitoringstacks", Subresource: "api", Name: "ms", Namespace: "team-a"},
+		{Alias: "thanos-proxy", Group: "monitoring.rhobs", Resource: "monitoringstacks", Subresource: "api", Name: "ms", Namespace: "team-a"},
+	})
+
+	// The ThanosQuerier takes precedence over the Prometheus of the MonitoringStack.
+	backends.ThanosQuerier = &uiv1alpha1.NamespacedReference{Name: "tq", Namespace: "team-a"}
+	info = &UIPluginInfo{}
+	assert.NilError(t, addMonitoringBackends(context.Background(), k, info, "monitoring", "ns", backends))
+	assert.DeepEqual(t, info.ExtraArgs, []string{
+		"-alertmanager=http://ms-alertmanager.team-a.svc:9093",
+		"-thanos-querier=https://thanos-querier-tq.team-a.svc:10902",
+	})
+	assert.Equal(t, info.ProxyAuthorization[1].Resource, "thanosqueriers")
+	assert.Equal(t, info.ProxyAuthorization[1].Name, "tq")
+
+	// The proxies are routed through the authorization proxy.
+	info.Name = "monitoring"
+	assert.NilError(t, addProxyAuthorization(info, info.ProxyAuthorization, "ns", "kube-rbac-proxy"))
+	assert.Equal(t, info.Proxies[1].Endpoint.Service.Name, "monitoring-authz")
+
+	// Without a MonitoringStack, the alerting proxy isn't configured.
+	backends.MonitoringStack = nil
+	info = &UIPluginInfo{}
+	assert.NilError(t, addMonitoringBackends(context.Background(), k, info, "monitoring", "ns", backends))
+	assert.Equal(t, len(info.Proxies), 1)
+	assert.Equal(t, info.Proxies[0].Alias, "thanos-proxy")
+
+	backends.ThanosQuerier.Name = "missing"
+	err := addMonitoringBackends(context.Background(), k, &UIPluginInfo{}, "monitoring", "ns", backends)
+	assert.ErrorContains(t, err, "failed to get ThanosQuerier team-a/missing")
+}
+
+func TestPerses(t *testing.T) {
+	k := fake.NewClientBuilder().WithObjects(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "perses-db", Namespace: "ns"},
+			Data: map[string][]byte{
+				"address":  []byte("mysql.db.svc:3306"),
+				"username": []byte("perses"),
+				"password": []byte("secret"),
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "incomplete", Namespace: "ns"},
+			Data:       map[string][]byte{"address": []byte("mysql.db.svc:3306")},
+		},
+	).Build()
+
+	// The file database isn't persisted by default.
+	perses := newPerses("ns", UIPluginInfo{PersesImage: "perses"}, clusterCapabilities{openShift: true})
+	assert.Assert(t, perses.Spec.Config.Database.File != nil)
+	assert.Assert(t, perses.Spec.Storage == nil)
+	assert.Assert(t, perses.Spec.Replicas == nil)
+	assert.Equal(t, *perses.Spec.Client.TLS.CaCert.Name, "openshift-service-ca.crt")
+
+	// Outside of OpenShift, the CA is provided with the serving certificate.
+	perses = newPerses("ns", UIPluginInfo{PersesImage: "perses"}, clusterCapabilities{})
+	for _, ca := range []*persesv1alpha2.Certificate{perses.Spec.TLS.CaCert, perses.Spec.Client.TLS.CaCert} {
+		assert.Equal(t, ca.Type, persesv1alpha2.SecretSourceTypeSecret)
+		assert.Equal(t, *ca.Name, "perses")
+		assert.Equal(t, ca.CertPath, "ca.crt")
+	}
+
+	cfg := &uiv1alpha1.PersesReference{
+		Enabled: true,
+		Storage: &uiv1alpha1.PersesStorage{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimSpec{
+				Resources: corev1.VolumeResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+				},
+			},
+		},
+		Resources: &corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+		},
+		NodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""},
+	}
+	perses = newPerses("ns", UIPluginInfo{PersesImage: "perses", PersesConfig: cfg}, clusterCapabilities{openShift: true})
+	assert.Assert(t, perses.Spec.Config.Database.File != nil)
+	assert.DeepEqual(t, perses.Spec.Storage.PersistentVolumeClaimTemplate, cfg.Storage.PersistentVolumeClaim)
+	assert.DeepEqual(t, perses.Spec.Resources, cfg.Resources)
+	assert.DeepEqual(t, perses.Spec.NodeSelector, cfg.NodeSelector)
+
+	cfg.Replicas = ptr.To(int32(2))
+	cfg.Storage = &uiv1alpha1.PersesStorage{
+		SQL: &uiv1alpha1.PersesSQLStorage{Secret: "perses-db", Database: "perses"},
+	}
+	hash, err := getPersesDatabaseHash(context.Background(), k, cfg.Storage.SQL, "ns")
+	assert.NilError(t, err)
+
+	perses = newPerses("ns", UIPluginInfo{PersesImage: "perses", PersesConfig: cfg, PersesDatabaseHash: hash}, clusterCapabilities{openShift: true})
+	assert.Equal(t, *perses.Spec.Replicas, int32(2))
+	assert.Assert(t, perses.Spec.Storage == nil)
+	assert.Assert(t, perses.Spec.Config.Database.File == nil)
+	assert.Equal(t, perses.Spec.Config.Database.SQL.DBName, "perses")
+	assert.Equal(t, perses.Spec.Config.Database.SQL.PasswordFile, "/etc/perses-database/password")
+	assert.Equal(t, perses.Spec.Volumes[0].Secret.SecretName, "perses-db")
+	assert.Equal(t, perses.Spec.VolumeMounts[0].MountPath, "/etc/perses-database")
+	assert.Equal(t, perses.Spec.Metadata.Annotations[annotationPrefix+"config-hash"], hash)
+
+	_, err = getPersesDatabaseHash(context.Background(), k, &uiv1alpha1.PersesSQLStorage{Secret: "incomplete", Database: "perses"}, "ns")
+	assert.ErrorContains(t, err, `key "username" not found in secret ns/incomplete`)
+}
@@ -20,24 +20,42 @@ type UIPluginInfo struct {
 	Image                      string
 	Korrel8rImage              string
 	HealthAnalyzerImage        string
+	HealthAnalyzerComponents   string
+	HealthAnalyzerBackends     *monitoringBackends
+	HealthAnalyzerConfig       *uiv1alpha1.ClusterHealthAnalyzerReference
 	LokiServiceNames           map[string]string
 	TempoServiceNames          map[string]string
+	Korrel8rStores             []korrel8rStore
+	Korrel8rRules              []*corev1.ConfigMap
+	Korrel8rConfig             *uiv1alpha1.Korrel8rConfig
+	LokiProxy                  *lokiProxyConfig
+	AuthorizationProxy         *authorizationProxyConfig
 	Name                       string
 	ConsoleName                string
 	DisplayName                string
 	ExtraArgs                  []string
+	Args                       []string
+	Port                       int32
+	Features                   []string
 	LegacyProxies              []osv1alpha1.ConsolePluginProxy
 	Proxies                    []osv1.ConsolePluginProxy
 	Role                       *rbacv1.Role
 	RoleBinding                *rbacv1.RoleBinding
 	ClusterRoles               []*rbacv1.ClusterRole
 	ClusterRoleBindings        []*rbacv1.ClusterRoleBinding
+	CustomRules                []rbacv1.PolicyRule
+	LoggingTenants             []uiv1alpha1.LoggingTenant
+	ProxyAuthorization         []proxyAuthorization
 	ConfigMap                  *corev1.ConfigMap
 	ResourceNamespace          string
 	PersesImage                string
+	DeploymentConfig           *uiv1alpha1.DeploymentConfig
+	PersesConfig               *uiv1alpha1.PersesReference
+	PersesDatabaseHash         string
 	AreMonitoringFeatsDisabled bool
 	TLSMinVersion              string
 	TLSCiphers                 []string
+	ServingCertificateCAs      map[string]string
 }
 
 var pluginTypeToConsoleName = map[uiv1alpha1.UIPluginType]string{
@@ -48,8 +66,31 @@ var pluginTypeToConsoleName = map[uiv1alpha1.UIPluginType]string{
 	uiv1alpha1.TypeMonitoring:           "monitoring-console-plugin",
 }
 
+// pluginConsoleName returns the name of the ConsolePlugin registered for the
+// UIPlugin.
+func pluginConsoleName(plugin *uiv1alpha1.UIPlugin) string {
+	if plugin.Spec.Type == uiv1alpha1.TypeCustom {
+		return customPluginName(plugin.Name)
+	}
+	return pluginTypeToConsoleName[plugin.Spec.Type]
+}
+
+// pluginPort returns the port on which the plugin serves its assets.
+func (info UIPluginInfo) pluginPort() int32 {
+	if info.Port != 0 {
+		return info.Port
+	}
+	return port
+}
+
 func PluginInfoBuilder(ctx context.Context, k client.Client, dk dynamic.Interface, plugin *uiv1alpha1.UIPlugin, pluginConf UIPluginsConfiguration, compatibilityInfo CompatibilityEntry, clusterVersion string, logger logr.Logger) (*UIPluginInfo, error) {
 	image := pluginConf.Images[compatibilityInfo.ImageKey]
+	if compatibilityInfo.Image != "" {
+		image = compatibilityInfo.Image
+	}
+	if plugin.Spec.Type == uiv1alpha1.TypeCustom && plugin.Spec.Custom != nil {
+		image = plugin.Spec.Custom.Image
+	}
 	if image == "" {
 		return nil, fmt.Errorf("no image provided for plugin type %s with key %s", plugin.Spec.Type, compatibilityInfo.ImageKey)
 	}
@@ -88,6 +129,20 @@ func PluginInfoBuilder(ctx context.Context, k client.Client, dk dynamic.Interfac
 			return nil, err
 		}
 
+		pluginInfo.Korrel8rStores, err = korrel8rStores(ctx, k, plugin.Spec.TroubleshootingPanel, *pluginInfo)
+		if err != nil {
+			return nil, err
+		}
+
+		pluginInfo.Korrel8rRules, err = getKorrel8rRules(ctx, k, plugin.Spec.TroubleshootingPanel, namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		if plugin.Spec.TroubleshootingPanel != nil {
+			pluginInfo.Korrel8rConfig = plugin.Spec.TroubleshootingPanel.Korrel8r
+		}
+
 	case uiv1alpha1.TypeDistributedTracing:
 		pluginInfo, err = createDistributedTracingPluginInfo(plugin, namespace, plugin.Name, image, []string{})
 		if err != nil {
@@ -95,21 +150,78 @@ func PluginInfoBuilder(ctx context.Context, k client.Client, dk dynamic.Interfac
 		}
 
 	case uiv1alpha1.TypeLogging:
+		if plugin.Spec.Logging != nil && len(plugin.Spec.Logging.LokiStacks) > 0 && !compatibilityInfo.SupportsLokiStacks {
+			return nil, fmt.Errorf("lokiStacks is not supported by the logging plugin image for cluster version %s", clusterVersion)
+		}
+
 		pluginInfo, err = createLoggingPluginInfo(plugin, namespace, plugin.Name, image, compatibilityInfo.Features, ctx, dk, logger, pluginConf.Images["korrel8r"])
 		if err != nil {
 			return nil, err
 		}
 
+		if plugin.Spec.Logging != nil && plugin.Spec.Logging.Loki != nil {
+			pluginInfo.LokiProxy, err = getLokiProxyConfig(ctx, k, plugin.Spec.Logging.Loki, namespace, pluginConf.Images["loki-proxy"], pluginConf.Images["kube-rbac-proxy"])
+			if err != nil {
+				return nil, err
+			}
+		}
+
 	case uiv1alpha1.TypeMonitoring:
 		pluginInfo, err = createMonitoringPluginInfo(plugin, namespace, plugin.Name, image, compatibilityInfo.Features, clusterVersion, pluginConf.Images["health-analyzer"], pluginConf.Images["perses"])
 		if err != nil {
 			return nil, err
 		}
 
+		if pluginInfo.HealthAnalyzerImage != "" {
+			pluginInfo.HealthAnalyzerConfig = plugin.Spec.Monitoring.ClusterHealthAnalyzer
+
+			pluginInfo.HealthAnalyzerComponents, err = getHealthAnalyzerComponents(ctx, k, plugin.Spec.Monitoring.ClusterHealthAnalyzer, namespace)
+			if err != nil {
+				return nil, err
+			}
+
+			if cfg := plugin.Spec.Monitoring.ClusterHealthAnalyzer; cfg != nil && cfg.Backends != nil {
+				pluginInfo.HealthAnalyzerBackends, err = getMonitoringBackends(ctx, k, cfg.Backends)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if cfg := plugin.Spec.Monitoring.Perses; cfg != nil && cfg.Enabled {
+			pluginInfo.PersesConfig = cfg
+
+			if cfg.Storage != nil && cfg.Storage.SQL != nil {
+				pluginInfo.PersesDatabaseHash, err = getPersesDatabaseHash(ctx, k, cfg.Storage.SQL, namespace)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if validateBackendsConfig(plugin.Spec.Monitoring) {
+			if err := addMonitoringBackends(ctx, k, pluginInfo, plugin.Name, namespace, plugin.Spec.Monitoring.Backends); err != nil {
+				return nil, err
+			}
+		}
+
+	case uiv1alpha1.TypeCustom:
+		pluginInfo, err = createCustomPluginInfo(plugin, namespace, customPluginName(plugin.Name))
+		if err != nil {
+			return nil, err
+		}
+
 	default:
 		return nil, fmt.Errorf("plugin type not supported: %s", plugin.Spec.Type)
 	}
 
+	pluginInfo.DeploymentConfig = plugin.Spec.Deployment
+
+	authorizations := mergeProxyAuthorization(pluginInfo.ProxyAuthorization, plugin.Spec.ProxyAuthorization)
+	if err := addProxyAuthorization(pluginInfo, authorizations, namespace, pluginConf.Images["kube-rbac-proxy"]); err != nil {
+		return nil, err
+	}
+
 	if compatibilityInfo.SupportsTLSProfile {
 		pluginInfo.TLSMinVersion = string(pluginConf.TLSProfile.MinTLSVersion)
 		pluginInfo.TLSCiphers = libgocrypto.OpenSSLToIANACipherSuites(pluginConf.TLSProfile.Ciphers)
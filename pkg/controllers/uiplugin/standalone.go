@@ -0,0 +1,540 @@
+package uiplugin
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	"sigs.k8s.io/yaml"
+
+	uiv1alpha1 "github.com/rhobs/observability-operator/pkg/apis/uiplugin/v1alpha1"
+	"github.com/rhobs/observability-operator/pkg/reconciler"
+)
+
+const (
+	consolePluginComponentName = "console-plugin"
+	// servingCertificateCAKey is the key of the CA certificate in the
+	// secrets of the serving certificates, as generated by cert-manager.
+	servingCertificateCAKey = "ca.crt"
+	// serviceCAConfigMapName is the ConfigMap containing the service CA
+	// bundle which OpenShift injects in every namespace.
+	serviceCAConfigMapName = "openshift-service-ca.crt"
+	serviceCAConfigMapKey  = "service-ca.crt"
+	// ingressBackendProtocolAnnotation configures ingress-nginx to connect
+	// to the backends over HTTPS.
+	ingressBackendProtocolAnnotation = "nginx.ingress.kubernetes.io/backend-protocol"
+)
+
+// clusterCapabilities are the APIs available in the cluster on which the
+// components of the plugins depend.
+type clusterCapabilities struct {
+	openShift bool
+	// console is false when the OpenShift console isn't available. The
+	// plugins run in standalone mode: only their backend services are
+	// deployed.
+	console         bool
+	perses          bool
+	serviceMonitors bool
+	gatewayAPI      bool
+}
+
+// backendComponent is a backend service deployed for a plugin.
+type backendComponent struct {
+	name string
+	port int32
+	// secret is the name of the secret containing the serving certificate
+	// of the service.
+	secret string
+	// exposurePort is the port of the authorization proxy forwarding the
+	// requests to the exposed service.
+	exposurePort int32
+	active       bool
+	message      string
+}
+
+// supportsStandalone returns true for the plugin types with backend services
+// which are useful without the console.
+func supportsStandalone(pluginType uiv1alpha1.UIPluginType) bool {
+	return pluginType == uiv1alpha1.TypeTroubleshootingPanel || pluginType == uiv1alpha1.TypeMonitoring
+}
+
+func persesEnabled(plugin *uiv1alpha1.UIPlugin) bool {
+	config := plugin.Spec.Monitoring
+	return config != nil && config.Perses != nil && config.Perses.Enabled
+}
+
+func healthAnalyzerEnabled(plugin *uiv1alpha1.UIPlugin, info UIPluginInfo) bool {
+	config := plugin.Spec.Monitoring
+	if config == nil || info.HealthAnalyzerImage == "" {
+		return false
+	}
+
+	incidentsEnabled := config.Incidents != nil && config.Incidents.Enabled
+	healthAnalyzerEnabled := config.ClusterHealthAnalyzer != nil && config.ClusterHealthAnalyzer.Enabled
+
+	return incidentsEnabled || healthAnalyzerEnabled
+}
+
+// healthAnalyzerActive returns true when the health analyzer is deployed.
+func healthAnalyzerActive(plugin *uiv1alpha1.UIPlugin, info UIPluginInfo, caps clusterCapabilities) bool {
+	return backendComponentActive(plugin, info, caps, name)
+}
+
+// backendComponentActive returns true when the backend service is deployed.
+func backendComponentActive(plugin *uiv1alpha1.UIPlugin, info UIPluginInfo, caps clusterCapabilities, component string) bool {
+	for _, c := range backendComponents(plugin, info, caps) {
+		if c.name == component {
+			return c.active
+		}
+	}
+	return false
+}
+
+// backendComponents returns the backend services enabled for the plugin.
+func backendComponents(plugin *uiv1alpha1.UIPlugin, info UIPluginInfo, caps clusterCapabilities) []backendComponent {
+	var components []backendComponent
+
+	switch plugin.Spec.Type {
+	case uiv1alpha1.TypeTroubleshootingPanel:
+		if info.Korrel8rImage != "" {
+			components = append(components, backendComponent{name: korrel8rName, port: port, secret: korrel8rName, active: true})
+		}
+
+	case uiv1alpha1.TypeMonitoring:
+		if persesEnabled(plugin) {
+			perses := backendComponent{name: persesServiceName, port: 8080, secret: persesServiceName, active: caps.perses}
+			if !caps.perses {
+				perses.message = "the Perses API isn't available in the cluster"
+			}
+			components = append(components, perses)
+		}
+
+		// Outside of OpenShift, there is no platform monitoring stack to
+		// query and the backends of the health analyzer must be configured.
+		if healthAnalyzerEnabled(plugin, info) {
+			healthAnalyzer := backendComponent{name: name, port: 8443, secret: volumeMountName, active: caps.openShift || info.HealthAnalyzerBackends != nil}
+			if !healthAnalyzer.active {
+				healthAnalyzer.message = "the backends of the health analyzer must be configured outside of OpenShift"
+			}
+			components = append(components, healthAnalyzer)
+		}
+	}
+
+	// The serving certificates are generated by OpenShift. Elsewhere, the
+	// services can't start until their secret is provided.
+	for i := range components {
+		c := &components[i]
+		c.exposurePort = authorizationProxyBasePort + int32(i)
+		if c.active && !servingCertificateAvailable(info, caps, c.secret) {
+			c.active = false
+			c.message = missingServingCertificateMessage(info.ResourceNamespace, c.secret)
+		}
+	}
+
+	return components
+}
+
+func servingCertificateAvailable(info UIPluginInfo, caps clusterCapabilities, secret string) bool {
+	if caps.openShift {
+		return true
+	}
+	_, found := info.ServingCertificateCAs[secret]
+	return found
+}
+
+func missingServingCertificateMessage(namespace, secret string) string {
+	return fmt.Sprintf("the secret %s/%s containing the serving certificate (tls.crt, tls.key and ca.crt) must be provided outside of OpenShift", namespace, secret)
+}
+
+// servingCertificateSecrets returns the names of the secrets containing the
+// serving certificates of the backend services of the plugin and of the
+// authorization proxy exposing them.
+func servingCertificateSecrets(plugin *uiv1alpha1.UIPlugin) []string {
+	var secrets []string
+	switch plugin.Spec.Type {
+	case uiv1alpha1.TypeTroubleshootingPanel:
+		secrets = []string{korrel8rName}
+	case uiv1alpha1.TypeMonitoring:
+		secrets = []string{persesServiceName, volumeMountName}
+	}
+
+	if len(secrets) > 0 && plugin.Spec.Exposure != nil {
+		secrets = append(secrets, exposureServingCertificateSecret(plugin))
+	}
+
+	return secrets
+}
+
+// exposureServingCertificateSecret returns the name of the secret containing
+// the serving certificate of the authorization proxy.
+func exposureServingCertificateSecret(plugin *uiv1alpha1.UIPlugin) string {
+	return authorizationProxyName(plugin.Name) + "-tls"
+}
+
+// getServingCertificateCAs returns the CA certificates of the serving
+// certificates by secret name. On OpenShift, the service CA signs all of
+// them. Elsewhere, the secrets are provided by the user (e.g. with
+// cert-manager) and the ones without a complete certificate are ignored.
+func getServingCertificateCAs(ctx context.Context, k client.Client, plugin *uiv1alpha1.UIPlugin, namespace string, caps clusterCapabilities) (map[string]string, error) {
+	cas := map[string]string{}
+	if caps.openShift {
+		cm := &corev1.ConfigMap{}
+		if err := k.Get(ctx, types.NamespacedName{Name: serviceCAConfigMapName, Namespace: namespace}, cm); err != nil {
+			if apierrors.IsNotFound(err) {
+				return cas, nil
+			}
+			return nil, fmt.Errorf("failed to get configmap %s/%s: %w", namespace, serviceCAConfigMapName, err)
+		}
+
+		for _, name := range servingCertificateSecrets(plugin) {
+			cas[name] = cm.Data[serviceCAConfigMapKey]
+		}
+		return cas, nil
+	}
+
+	for _, name := range servingCertificateSecrets(plugin) {
+		secret := &corev1.Secret{}
+		if err := k.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, secret); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get secret %s/%s: %w", namespace, name, err)
+		}
+
+		if len(secret.Data[corev1.TLSCertKey]) == 0 || len(secret.Data[corev1.TLSPrivateKeyKey]) == 0 || len(secret.Data[servingCertificateCAKey]) == 0 {
+			continue
+		}
+		cas[name] = string(secret.Data[servingCertificateCAKey])
+	}
+
+	return cas, nil
+}
+
+// componentStatuses reports which components of the plugin are active and
+// the hosts exposing them in standalone mode.
+func componentStatuses(plugin *uiv1alpha1.UIPlugin, info UIPluginInfo, caps clusterCapabilities) []uiv1alpha1.UIPluginComponentStatus {
+	consolePlugin := uiv1alpha1.UIPluginComponentStatus{Name: consolePluginComponentName, Active: caps.console}
+	if !caps.console {
+		consolePlugin.Message = "the OpenShift console isn't available"
+	}
+	statuses := []uiv1alpha1.UIPluginComponentStatus{consolePlugin}
+
+	for _, c := range backendComponents(plugin, info, caps) {
+		status := uiv1alpha1.UIPluginComponentStatus{
+			Name:    c.name,
+			Active:  c.active,
+			Message: c.message,
+		}
+
+		if exposure := plugin.Spec.Exposure; c.active && !caps.console && exposure != nil {
+			if message := exposureUnavailableMessage(plugin, info, caps); message != "" {
+				status.Message = message
+			} else {
+				status.Host = exposureHost(c.name, exposure)
+			}
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
+
+// exposureUnavailableMessage explains why the backend services can't be
+// exposed. It is empty when they can.
+func exposureUnavailableMessage(plugin *uiv1alpha1.UIPlugin, info UIPluginInfo, caps clusterCapabilities) string {
+	if plugin.Spec.Exposure.Type == uiv1alpha1.HTTPRouteExposure && !caps.gatewayAPI {
+		return "the Gateway API (HTTPRoute and BackendTLSPolicy) isn't available in the cluster"
+	}
+
+	if secret := exposureServingCertificateSecret(plugin); !servingCertificateAvailable(info, caps, secret) {
+		return missingServingCertificateMessage(info.ResourceNamespace, secret)
+	}
+
+	return ""
+}
+
+func exposureHost(component string, exposure *uiv1alpha1.ExposureConfig) string {
+	return fmt.Sprintf("%s.%s", component, exposure.Domain)
+}
+
+// addExposureAuthorization routes the exposed backend services through the
+// authorization proxy of the plugin. Without the console, the proxy isn't
+// used by any console proxy and it only authorizes the clients of the exposed
+// services.
+func addExposureAuthorization(pluginInfo *UIPluginInfo, plugin *uiv1alpha1.UIPlugin, caps clusterCapabilities, image string) error {
+	pluginInfo.AuthorizationProxy = nil
+
+	exposure := plugin.Spec.Exposure
+	if caps.console || exposure == nil || exposureUnavailableMessage(plugin, *pluginInfo, caps) != "" {
+		return nil
+	}
+
+	cfg := &authorizationProxyConfig{
+		Image:  image,
+		Config: map[string]string{},
+	}
+	for _, c := range backendComponents(plugin, *pluginInfo, caps) {
+		if !c.active {
+			continue
+		}
+
+		upstream := authorizedUpstream{
+			Alias:  c.name,
+			Port:   c.exposurePort,
+			URL:    fmt.Sprintf("https://%s.%s.svc:%d/", c.name, pluginInfo.ResourceNamespace, c.port),
+			CAFile: serviceCAFile,
+		}
+		if !caps.openShift {
+			cfg.Config[c.name+"-ca.crt"] = pluginInfo.ServingCertificateCAs[c.secret]
+			upstream.CAFile = fmt.Sprintf("%s/%s-ca.crt", authorizationProxyConfigDir, c.name)
+		}
+
+		config, err := yaml.Marshal(newKubeRBACProxyConfig(proxyAuthorization{
+			Alias:       c.name,
+			Group:       exposure.Authorization.Group,
+			Resource:    exposure.Authorization.Resource,
+			Subresource: exposure.Authorization.Subresource,
+			Name:        exposure.Authorization.Name,
+			Namespace:   exposure.Authorization.Namespace,
+		}))
+		if err != nil {
+			return err
+		}
+		cfg.Config[c.name+".yaml"] = string(config)
+		cfg.Upstreams = append(cfg.Upstreams, upstream)
+	}
+
+	if len(cfg.Upstreams) == 0 {
+		return nil
+	}
+	if image == "" {
+		return fmt.Errorf("no image provided for the authorization proxy")
+	}
+
+	pluginInfo.AuthorizationProxy = cfg
+	return nil
+}
+
+// exposureReconcilers returns the reconcilers of the Ingresses and
+// HTTPRoutes exposing the backend services in standalone mode through the
+// authorization proxy. HTTPRoutes and BackendTLSPolicies are skipped when the
+// Gateway API isn't available since they can't exist.
+func exposureReconcilers(plugin *uiv1alpha1.UIPlugin, info UIPluginInfo, caps clusterCapabilities) []reconciler.Reconciler {
+	exposure := plugin.Spec.Exposure
+	proxy := authorizationProxyName(plugin.Name)
+	exposedAs := func(t uiv1alpha1.ExposureType) bool {
+		return !caps.console && exposure != nil && exposure.Type == t && info.AuthorizationProxy != nil
+	}
+
+	var reconcilers []reconciler.Reconciler
+	for _, c := range backendComponents(plugin, info, caps) {
+		reconcilers = append(reconcilers,
+			reconciler.NewOptionalUpdater(newBackendIngress(c, proxy, info.ResourceNamespace, exposure), plugin, c.active && exposedAs(uiv1alpha1.IngressExposure)))
+
+		if caps.gatewayAPI {
+			reconcilers = append(reconcilers,
+				reconciler.NewOptionalUpdater(newBackendHTTPRoute(c, proxy, info.ResourceNamespace, exposure), plugin, c.active && exposedAs(uiv1alpha1.HTTPRouteExposure)))
+		}
+	}
+
+	if caps.gatewayAPI {
+		ca := info.ServingCertificateCAs[exposureServingCertificateSecret(plugin)]
+		reconcilers = append(reconcilers,
+			reconciler.NewOptionalUpdater(newBackendCAConfigMap(proxy, info.ResourceNamespace, ca), plugin, exposedAs(uiv1alpha1.HTTPRouteExposure)),
+			reconciler.NewOptionalUpdater(newBackendTLSPolicy(proxy, info.ResourceNamespace), plugin, exposedAs(uiv1alpha1.HTTPRouteExposure)),
+		)
+	}
+
+	return reconcilers
+}
+
+// newBackendIngress returns the Ingress exposing a backend service through
+// the authorization proxy.
+func newBackendIngress(c backendComponent, proxy string, namespace string, exposure *uiv1alpha1.ExposureConfig) *networkingv1.Ingress {
+	ingress := &networkingv1.Ingress{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: networkingv1.SchemeGroupVersion.String(),
+			Kind:       "Ingress",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.name,
+			Namespace: namespace,
+			Labels:    componentLabels(c.name),
+		},
+	}
+
+	if exposure == nil {
+		return ingress
+	}
+
+	host := exposureHost(c.name, exposure)
+	ingress.Annotations = map[string]string{ingressBackendProtocolAnnotation: "HTTPS"}
+	ingress.Spec = networkingv1.IngressSpec{
+		Rules: []networkingv1.IngressRule{
+			{
+				Host: host,
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{
+							{
+								Path:     "/",
+								PathType: ptr.To(networkingv1.PathTypePrefix),
+								Backend: networkingv1.IngressBackend{
+									Service: &networkingv1.IngressServiceBackend{
+										Name: proxy,
+										Port: networkingv1.ServiceBackendPort{Number: c.exposurePort},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if cfg := exposure.Ingress; cfg != nil {
+		maps.Copy(ingress.Annotations, cfg.Annotations)
+		ingress.Spec.IngressClassName = cfg.IngressClassName
+		if cfg.TLSSecretName != "" {
+			ingress.Spec.TLS = []networkingv1.IngressTLS{
+				{
+					Hosts:      []string{host},
+					SecretName: cfg.TLSSecretName,
+				},
+			}
+		}
+	}
+
+	return ingress
+}
+
+// newBackendHTTPRoute returns the HTTPRoute exposing a backend service
+// through the authorization proxy.
+func newBackendHTTPRoute(c backendComponent, proxy string, namespace string, exposure *uiv1alpha1.ExposureConfig) *gatewayv1.HTTPRoute {
+	httpRoute := &gatewayv1.HTTPRoute{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: gatewayv1.GroupVersion.String(),
+			Kind:       "HTTPRoute",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.name,
+			Namespace: namespace,
+			Labels:    componentLabels(c.name),
+		},
+	}
+
+	if exposure == nil {
+		return httpRoute
+	}
+
+	if exposure.HTTPRoute != nil {
+		for _, ref := range exposure.HTTPRoute.ParentRefs {
+			parentRef := gatewayv1.ParentReference{
+				Name: gatewayv1.ObjectName(ref.Name),
+			}
+			if ref.Namespace != "" {
+				parentRef.Namespace = ptr.To(gatewayv1.Namespace(ref.Namespace))
+			}
+			if ref.SectionName != "" {
+				parentRef.SectionName = ptr.To(gatewayv1.SectionName(ref.SectionName))
+			}
+			httpRoute.Spec.ParentRefs = append(httpRoute.Spec.ParentRefs, parentRef)
+		}
+	}
+
+	httpRoute.Spec.Hostnames = []gatewayv1.Hostname{gatewayv1.Hostname(exposureHost(c.name, exposure))}
+	httpRoute.Spec.Rules = []gatewayv1.HTTPRouteRule{
+		{
+			BackendRefs: []gatewayv1.HTTPBackendRef{
+				{
+					BackendRef: gatewayv1.BackendRef{
+						BackendObjectReference: gatewayv1.BackendObjectReference{
+							Name: gatewayv1.ObjectName(proxy),
+							Port: ptr.To(gatewayv1.PortNumber(c.exposurePort)),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	return httpRoute
+}
+
+// newBackendCAConfigMap returns the ConfigMap containing the CA certificate
+// which the Gateways trust to connect to the authorization proxy. The
+// BackendTLSPolicies require the `ca.crt` key.
+func newBackendCAConfigMap(proxy string, namespace string, ca string) *corev1.ConfigMap {
+	name := proxy + "-ca"
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    componentLabels(name),
+		},
+		Data: map[string]string{
+			servingCertificateCAKey: ca,
+		},
+	}
+}
+
+// newBackendTLSPolicy returns the BackendTLSPolicy configuring the Gateways
+// to connect to the authorization proxy over HTTPS.
+func newBackendTLSPolicy(proxy string, namespace string) *gatewayv1.BackendTLSPolicy {
+	return &gatewayv1.BackendTLSPolicy{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: gatewayv1.GroupVersion.String(),
+			Kind:       "BackendTLSPolicy",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      proxy,
+			Namespace: namespace,
+			Labels:    componentLabels(proxy),
+		},
+		Spec: gatewayv1.BackendTLSPolicySpec{
+			TargetRefs: []gatewayv1.LocalPolicyTargetReferenceWithSectionName{
+				{
+					LocalPolicyTargetReference: gatewayv1.LocalPolicyTargetReference{
+						Group: "",
+						Kind:  "Service",
+						Name:  gatewayv1.ObjectName(proxy),
+					},
+				},
+			},
+			Validation: gatewayv1.BackendTLSPolicyValidation{
+				CACertificateRefs: []gatewayv1.LocalObjectReference{
+					{
+						Group: "",
+						Kind:  "ConfigMap",
+						Name:  gatewayv1.ObjectName(proxy + "-ca"),
+					},
+				},
+				Hostname: gatewayv1.PreciseHostname(fmt.Sprintf("%s.%s.svc", proxy, namespace)),
+			},
+		},
+	}
+}
+
+// standaloneReconciledMessage is reported in the Reconciled condition of the
+// plugins running without the console.
+const standaloneReconciledMessage = "Plugin reconciled in standalone mode: the OpenShift console isn't available, only the backend services are deployed"
+
+// errStandaloneNotSupported is returned for the plugin types without backend
+// services when the console isn't available.
+func errStandaloneNotSupported(pluginType uiv1alpha1.UIPluginType) error {
+	return fmt.Errorf("plugin type %s requires the OpenShift console which isn't available in the cluster", pluginType)
+}
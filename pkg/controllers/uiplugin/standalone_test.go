@@ -0,0 +1,269 @@
+package uiplugin
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	uiv1alpha1 "github.com/rhobs/observability-operator/pkg/apis/uiplugin/v1alpha1"
+)
+
+func TestComponentStatuses(t *testing.T) {
+	monitoring := &uiv1alpha1.UIPlugin{
+		ObjectMeta: metav1.ObjectMeta{Name: "monitoring"},
+		Spec: uiv1alpha1.UIPluginSpec{
+			Type: uiv1alpha1.TypeMonitoring,
+			Monitoring: &uiv1alpha1.MonitoringConfig{
+				Perses:                &uiv1alpha1.PersesReference{Enabled: true},
+				ClusterHealthAnalyzer: &uiv1alpha1.ClusterHealthAnalyzerReference{Enabled: true},
+			},
+			Exposure: &uiv1alpha1.ExposureConfig{
+				Type:   uiv1alpha1.IngressExposure,
+				Domain: "example.com",
+			},
+		},
+	}
+	info := UIPluginInfo{HealthAnalyzerImage: "health-analyzer"}
+	servingCertificateCAs := map[string]string{"korrel8r": "ca", "perses": "ca", "health-analyzer-tls": "ca", "monitoring-authz-tls": "ca"}
+
+	for _, tc := range []struct {
+		name     string
+		plugin   *uiv1alpha1.UIPlugin
+		info     UIPluginInfo
+		caps     clusterCapabilities
+		expected []uiv1alpha1.UIPluginComponentStatus
+	}{
+		{
+			name:   "console",
+			plugin: monitoring,
+			info:   info,
+			caps:   clusterCapabilities{openShift: true, console: true, perses: true},
+			expected: []uiv1alpha1.UIPluginComponentStatus{
+				{Name: "console-plugin", Active: true},
+				{Name: "perses", Active: true},
+				{Name: "health-analyzer", Active: true},
+			},
+		},
+		{
+			name:   "standalone without backends",
+			plugin: monitoring,
+			info:   info,
+			caps:   clusterCapabilities{},
+			expected: []uiv1alpha1.UIPluginComponentStatus{
+				{Name: "console-plugin", Message: "the OpenShift console isn't available"},
+				{Name: "perses", Message: "the Perses API isn't available in the cluster"},
+				{Name: "health-analyzer", Message: "the backends of the health analyzer must be configured outside of OpenShift"},
+			},
+		},
+		{
+			name:   "standalone without serving certificates",
+			plugin: monitoring,
+			info:   UIPluginInfo{HealthAnalyzerImage: "health-analyzer", HealthAnalyzerBackends: &monitoringBackends{}, ResourceNamespace: "ns"},
+			caps:   clusterCapabilities{perses: true},
+			expected: []uiv1alpha1.UIPluginComponentStatus{
+				{Name: "console-plugin", Message: "the OpenShift console isn't available"},
+				{Name: "perses", Message: "the secret ns/perses containing the serving certificate (tls.crt, tls.key and ca.crt) must be provided outside of OpenShift"},
+				{Name: "health-analyzer", Message: "the secret ns/health-analyzer-tls containing the serving certificate (tls.crt, tls.key and ca.crt) must be provided outside of OpenShift"},
+			},
+		},
+		{
+			name:   "standalone",
+			plugin: monitoring,
+			info:   UIPluginInfo{HealthAnalyzerImage: "health-analyzer", HealthAnalyzerBackends: &monitoringBackends{}, ServingCertificateCAs: servingCertificateCAs},
+			caps:   clusterCapabilities{perses: true},
+			expected: []uiv1alpha1.UIPluginComponentStatus{
+				{Name: "console-plugin", Message: "the OpenShift console isn't available"},
+				{Name: "perses", Active: true, Host: "perses.example.com"},
+				{Name: "health-analyzer", Active: true, Host: "health-analyzer.example.com"},
+			},
+		},
+		{
+			name: "standalone without Gateway API",
+			plugin: &uiv1alpha1.UIPlugin{
+				Spec: uiv1alpha1.UIPluginSpec{
+					Type: uiv1alpha1.TypeTroubleshootingPanel,
+					Exposure: &uiv1alpha1.ExposureConfig{
+						Type:   uiv1alpha1.HTTPRouteExposure,
+						Domain: "example.com",
+					},
+				},
+			},
+			info: UIPluginInfo{Korrel8rImage: "korrel8r", ServingCertificateCAs: servingCertificateCAs},
+			caps: clusterCapabilities{},
+			expected: []uiv1alpha1.UIPluginComponentStatus{
+				{Name: "console-plugin", Message: "the OpenShift console isn't available"},
+				{Name: "korrel8r", Active: true, Message: "the Gateway API (HTTPRoute and BackendTLSPolicy) isn't available in the cluster"},
+			},
+		},
+		{
+			name: "standalone without the serving certificate of the authorization proxy",
+			plugin: &uiv1alpha1.UIPlugin{
+				ObjectMeta: metav1.ObjectMeta{Name: "troubleshooting-panel"},
+				Spec: uiv1alpha1.UIPluginSpec{
+					Type: uiv1alpha1.TypeTroubleshootingPanel,
+					Exposure: &uiv1alpha1.ExposureConfig{
+						Type:   uiv1alpha1.IngressExposure,
+						Domain: "example.com",
+					},
+				},
+			},
+			info: UIPluginInfo{Korrel8rImage: "korrel8r", ServingCertificateCAs: servingCertificateCAs, ResourceNamespace: "ns"},
+			caps: clusterCapabilities{},
+			expected: []uiv1alpha1.UIPluginComponentStatus{
+				{Name: "console-plugin", Message: "the OpenShift console isn't available"},
+				{Name: "korrel8r", Active: true, Message: "the secret ns/troubleshooting-panel-authz-tls containing the serving certificate (tls.crt, tls.key and ca.crt) must be provided outside of OpenShift"},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.DeepEqual(t, componentStatuses(tc.plugin, tc.info, tc.caps), tc.expected)
+		})
+	}
+}
+
+func TestGetServingCertificateCAs(t *testing.T) {
+	newSecret := func(name string, data map[string][]byte) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"}, Data: data}
+	}
+	k := fake.NewClientBuilder().WithObjects(
+		newSecret("perses", map[string][]byte{"tls.crt": []byte("crt"), "tls.key": []byte("key"), "ca.crt": []byte("ca")}),
+		// The CA certificate is missing.
+		newSecret("health-analyzer-tls", map[string][]byte{"tls.crt": []byte("crt"), "tls.key": []byte("key")}),
+		newSecret("korrel8r", map[string][]byte{"tls.crt": []byte("crt"), "tls.key": []byte("key"), "ca.crt": []byte("ca")}),
+	).Build()
+
+	plugin := &uiv1alpha1.UIPlugin{Spec: uiv1alpha1.UIPluginSpec{Type: uiv1alpha1.TypeMonitoring}}
+	cas, err := getServingCertificateCAs(context.Background(), k, plugin, "ns", clusterCapabilities{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, cas, map[string]string{"perses": "ca"})
+
+	// On OpenShift, the service CA signs all the certificates, including
+	// the one of the authorization proxy of the exposed services.
+	k = fake.NewClientBuilder().WithObjects(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "openshift-service-ca.crt", Namespace: "ns"},
+		Data:       map[string]string{"service-ca.crt": "service-ca"},
+	}).Build()
+	plugin = &uiv1alpha1.UIPlugin{
+		ObjectMeta: metav1.ObjectMeta{Name: "troubleshooting-panel"},
+		Spec: uiv1alpha1.UIPluginSpec{
+			Type:     uiv1alpha1.TypeTroubleshootingPanel,
+			Exposure: &uiv1alpha1.ExposureConfig{Type: uiv1alpha1.IngressExposure, Domain: "example.com"},
+		},
+	}
+	cas, err = getServingCertificateCAs(context.Background(), k, plugin, "ns", clusterCapabilities{openShift: true})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, cas, map[string]string{"korrel8r": "service-ca", "troubleshooting-panel-authz-tls": "service-ca"})
+}
+
+func TestAddExposureAuthorization(t *testing.T) {
+	plugin := &uiv1alpha1.UIPlugin{
+		ObjectMeta: metav1.ObjectMeta{Name: "troubleshooting-panel"},
+		Spec: uiv1alpha1.UIPluginSpec{
+			Type: uiv1alpha1.TypeTroubleshootingPanel,
+			Exposure: &uiv1alpha1.ExposureConfig{
+				Type:          uiv1alpha1.IngressExposure,
+				Domain:        "example.com",
+				Authorization: uiv1alpha1.ExposureAuthorization{Resource: "services", Subresource: "proxy", Name: "korrel8r", Namespace: "ns"},
+			},
+		},
+	}
+	info := &UIPluginInfo{
+		Korrel8rImage:         "korrel8r",
+		ResourceNamespace:     "ns",
+		AuthorizationProxy:    &authorizationProxyConfig{},
+		ServingCertificateCAs: map[string]string{"korrel8r": "korrel8r-ca", "troubleshooting-panel-authz-tls": "ca"},
+	}
+
+	assert.NilError(t, addExposureAuthorization(info, plugin, clusterCapabilities{}, "kube-rbac-proxy"))
+	cfg := info.AuthorizationProxy
+	assert.Equal(t, cfg.Image, "kube-rbac-proxy")
+	assert.DeepEqual(t, cfg.Upstreams, []authorizedUpstream{
+		{Alias: "korrel8r", Port: 9443, URL: "https://korrel8r.ns.svc:9443/", CAFile: "/etc/authorization-proxy/korrel8r-ca.crt"},
+	})
+	assert.Equal(t, cfg.Config["korrel8r-ca.crt"], "korrel8r-ca")
+	assert.Assert(t, strings.Contains(cfg.Config["korrel8r.yaml"], "subresource: proxy"))
+
+	// The authorization proxy isn't deployed without its serving
+	// certificate.
+	delete(info.ServingCertificateCAs, "troubleshooting-panel-authz-tls")
+	assert.NilError(t, addExposureAuthorization(info, plugin, clusterCapabilities{}, "kube-rbac-proxy"))
+	assert.Assert(t, info.AuthorizationProxy == nil)
+
+	// The service CA signs the certificates on OpenShift.
+	assert.NilError(t, addExposureAuthorization(info, plugin, clusterCapabilities{openShift: true}, "kube-rbac-proxy"))
+	assert.Equal(t, info.AuthorizationProxy.Upstreams[0].CAFile, serviceCAFile)
+
+	assert.ErrorContains(t, addExposureAuthorization(info, plugin, clusterCapabilities{openShift: true}, ""), "no image provided")
+}
+
+func TestExposure(t *testing.T) {
+	c := backendComponent{name: korrel8rName, port: port, exposurePort: 9444, active: true}
+
+	ingress := newBackendIngress(c, "plugin-authz", "ns", &uiv1alpha1.ExposureConfig{
+		Type:   uiv1alpha1.IngressExposure,
+		Domain: "example.com",
+		Ingress: &uiv1alpha1.ExposureIngressConfig{
+			IngressClassName: ptr.To("nginx"),
+			TLSSecretName:    "tls",
+			Annotations:      map[string]string{"nginx.ingress.kubernetes.io/proxy-ssl-verify": "on"},
+		},
+	})
+	assert.Equal(t, ingress.Namespace, "ns")
+	assert.Equal(t, *ingress.Spec.IngressClassName, "nginx")
+	assert.DeepEqual(t, ingress.Annotations, map[string]string{
+		"nginx.ingress.kubernetes.io/backend-protocol": "HTTPS",
+		"nginx.ingress.kubernetes.io/proxy-ssl-verify": "on",
+	})
+	assert.DeepEqual(t, ingress.Spec.TLS, []networkingv1.IngressTLS{{Hosts: []string{"korrel8r.example.com"}, SecretName: "tls"}})
+	rule := ingress.Spec.Rules[0]
+	assert.Equal(t, rule.Host, "korrel8r.example.com")
+	assert.DeepEqual(t, *rule.HTTP.Paths[0].Backend.Service, networkingv1.IngressServiceBackend{Name: "plugin-authz", Port: networkingv1.ServiceBackendPort{Number: 9444}})
+
+	// The annotations of the user take precedence.
+	ingress = newBackendIngress(c, "plugin-authz", "ns", &uiv1alpha1.ExposureConfig{
+		Type:    uiv1alpha1.IngressExposure,
+		Domain:  "example.com",
+		Ingress: &uiv1alpha1.ExposureIngressConfig{Annotations: map[string]string{"nginx.ingress.kubernetes.io/backend-protocol": "GRPCS"}},
+	})
+	assert.Equal(t, ingress.Annotations["nginx.ingress.kubernetes.io/backend-protocol"], "GRPCS")
+
+	httpRoute := newBackendHTTPRoute(c, "plugin-authz", "ns", &uiv1alpha1.ExposureConfig{
+		Type:   uiv1alpha1.HTTPRouteExposure,
+		Domain: "example.com",
+		HTTPRoute: &uiv1alpha1.ExposureHTTPRouteConfig{
+			ParentRefs: []uiv1alpha1.ExposureParentReference{{Name: "gateway", Namespace: "gateways", SectionName: "https"}},
+		},
+	})
+	assert.DeepEqual(t, httpRoute.Spec.ParentRefs, []gatewayv1.ParentReference{
+		{
+			Name:        "gateway",
+			Namespace:   ptr.To(gatewayv1.Namespace("gateways")),
+			SectionName: ptr.To(gatewayv1.SectionName("https")),
+		},
+	})
+	assert.DeepEqual(t, httpRoute.Spec.Hostnames, []gatewayv1.Hostname{"korrel8r.example.com"})
+	assert.Equal(t, httpRoute.Spec.Rules[0].BackendRefs[0].Name, gatewayv1.ObjectName("plugin-authz"))
+	assert.Equal(t, *httpRoute.Spec.Rules[0].BackendRefs[0].Port, gatewayv1.PortNumber(9444))
+
+	policy := newBackendTLSPolicy("plugin-authz", "ns")
+	assert.Equal(t, policy.Spec.TargetRefs[0].Kind, gatewayv1.Kind("Service"))
+	assert.Equal(t, policy.Spec.TargetRefs[0].Name, gatewayv1.ObjectName("plugin-authz"))
+	assert.Equal(t, policy.Spec.Validation.Hostname, gatewayv1.PreciseHostname("plugin-authz.ns.svc"))
+	assert.Equal(t, policy.Spec.Validation.CACertificateRefs[0].Name, gatewayv1.ObjectName("plugin-authz-ca"))
+	assert.DeepEqual(t, newBackendCAConfigMap("plugin-authz", "ns", "ca").Data, map[string]string{"ca.crt": "ca"})
+
+	// HTTPRoutes and BackendTLSPolicies can't be reconciled without the
+	// Gateway API.
+	plugin := &uiv1alpha1.UIPlugin{Spec: uiv1alpha1.UIPluginSpec{Type: uiv1alpha1.TypeTroubleshootingPanel}}
+	info := UIPluginInfo{Korrel8rImage: "korrel8r"}
+	assert.Equal(t, len(exposureReconcilers(plugin, info, clusterCapabilities{})), 1)
+	assert.Equal(t, len(exposureReconcilers(plugin, info, clusterCapabilities{gatewayAPI: true})), 4)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 
 	osv1 "github.com/openshift/api/console/v1"
@@ -12,8 +13,10 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	monv1alpha1 "github.com/rhobs/observability-operator/pkg/apis/monitoring/v1alpha1"
 	uiv1alpha1 "github.com/rhobs/observability-operator/pkg/apis/uiplugin/v1alpha1"
 	"github.com/rhobs/observability-operator/pkg/reconciler"
 )
@@ -21,6 +24,11 @@ import (
 const (
 	korrel8rSvcName      = "korrel8r"
 	alertmanagerRoleName = "monitoring-alertmanager-view"
+
+	korrel8rServiceCAPath = "./run/secrets/kubernetes.io/serviceaccount/service-ca.crt"
+	korrel8rCAMountDir    = "/etc/korrel8r/ca"
+	korrel8rRulesMountDir = "/etc/korrel8r/custom-rules"
+	korrel8rDefaultRules  = "/etc/korrel8r/rules/all.yaml"
 )
 
 func createTroubleshootingPanelPluginInfo(plugin *uiv1alpha1.UIPlugin, namespace, name, image string, features []string) (*UIPluginInfo, error) {
@@ -46,6 +54,7 @@ func createTroubleshootingPanelPluginInfo(plugin *uiv1alpha1.UIPlugin, namespace
 		ConsoleName:       pluginTypeToConsoleName[plugin.Spec.Type],
 		DisplayName:       "Troubleshooting Panel Console Plugin",
 		ResourceNamespace: namespace,
+		Features:          features,
 		LokiServiceNames:  make(map[string]string),
 		TempoServiceNames: make(map[string]string),
 		ExtraArgs:         extraArgs,
@@ -146,6 +155,229 @@ func marshalTroubleshootingPanelPluginConfig(cfg *uiv1alpha1.TroubleshootingPane
 	return buf.String(), nil
 }
 
+// korrel8rStore is the korrel8r configuration of a domain store.
+type korrel8rStore struct {
+	Domain string
+	// Endpoints are the korrel8r store fields holding the backend URLs.
+	Endpoints []korrel8rEndpoint
+	// CertificateAuthority is the path of the CA bundle in the korrel8r
+	// container. It is empty when the backend doesn't use TLS.
+	CertificateAuthority string
+	// CAVolume mounts a user-provided CA bundle.
+	CAVolume *corev1.Volume
+}
+
+type korrel8rEndpoint struct {
+	Key string
+	URL string
+}
+
+// defaultKorrel8rStores returns the stores of the OpenShift platform
+// components installed in their default locations.
+func defaultKorrel8rStores(info UIPluginInfo) []korrel8rStore {
+	logService := "logging-loki-gateway-http"
+	if info.LokiServiceNames[OpenshiftLoggingNs] != "" {
+		logService = info.LokiServiceNames[OpenshiftLoggingNs]
+	}
+	netflowService := "loki-gateway-http"
+	if info.LokiServiceNames[OpenshiftNetobservNs] != "" {
+		netflowService = info.LokiServiceNames[OpenshiftNetobservNs]
+	}
+	traceService := "tempo-platform-gateway"
+	if info.TempoServiceNames[OpenshiftTracingNs] != "" {
+		traceService = info.TempoServiceNames[OpenshiftTracingNs]
+	}
+
+	thanosURL := fmt.Sprintf("https://thanos-querier.%s.svc:9091", reconciler.OpenshiftMonitoringNamespace)
+	return []korrel8rStore{
+		{
+			Domain: string(uiv1alpha1.Korrel8rAlertDomain),
+			Endpoints: []korrel8rEndpoint{
+				{Key: "metrics", URL: thanosURL},
+				{Key: "alertmanager", URL: fmt.Sprintf("https://alertmanager-main.%s.svc:9094", reconciler.OpenshiftMonitoringNamespace)},
+			},
+			CertificateAuthority: korrel8rServiceCAPath,
+		},
+		{
+			Domain:               string(uiv1alpha1.Korrel8rLogDomain),
+			Endpoints:            []korrel8rEndpoint{{Key: "lokiStack", URL: fmt.Sprintf("https://%s.%s.svc:8080", logService, OpenshiftLoggingNs)}},
+			CertificateAuthority: korrel8rServiceCAPath,
+		},
+		{
+			Domain:               string(uiv1alpha1.Korrel8rMetricDomain),
+			Endpoints:            []korrel8rEndpoint{{Key: "metric", URL: thanosURL}},
+			CertificateAuthority: korrel8rServiceCAPath,
+		},
+		{
+			Domain:               string(uiv1alpha1.Korrel8rNetflowDomain),
+			Endpoints:            []korrel8rEndpoint{{Key: "lokiStack", URL: fmt.Sprintf("https://%s.%s.svc:8080", netflowService, OpenshiftNetobservNs)}},
+			CertificateAuthority: korrel8rServiceCAPath,
+		},
+		{
+			Domain:               string(uiv1alpha1.Korrel8rTraceDomain),
+			Endpoints:            []korrel8rEndpoint{{Key: "tempoStack", URL: tempoStackSearchURL(traceService, OpenshiftTracingNs, "platform")}},
+			CertificateAuthority: korrel8rServiceCAPath,
+		},
+	}
+}
+
+// korrel8rStores returns the default korrel8r stores with the domains
+// configured in the TroubleshootingPanel configuration replaced.
+func korrel8rStores(ctx context.Context, k client.Client, cfg *uiv1alpha1.TroubleshootingPanelConfig, info UIPluginInfo) ([]korrel8rStore, error) {
+	stores := defaultKorrel8rStores(info)
+	if cfg == nil {
+		return stores, nil
+	}
+
+	for _, storeCfg := range cfg.Stores {
+		store, err := newKorrel8rStore(ctx, k, storeCfg)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range stores {
+			if stores[i].Domain == store.Domain {
+				stores[i] = store
+			}
+		}
+	}
+
+	return stores, nil
+}
+
+func newKorrel8rStore(ctx context.Context, k client.Client, cfg uiv1alpha1.Korrel8rStore) (korrel8rStore, error) {
+	store := korrel8rStore{Domain: string(cfg.Domain)}
+
+	switch {
+	case cfg.MonitoringStack != nil:
+		ms := &monv1alpha1.MonitoringStack{}
+		if err := k.Get(ctx, types.NamespacedName{Name: cfg.MonitoringStack.Name, Namespace: cfg.MonitoringStack.Namespace}, ms); err != nil {
+			return store, fmt.Errorf("failed to get MonitoringStack for the %s store: %w", cfg.Domain, err)
+		}
+
+		prometheusScheme, alertmanagerScheme := "http", "http"
+		if ms.Spec.PrometheusConfig != nil && ms.Spec.PrometheusConfig.WebTLSConfig != nil {
+			prometheusScheme = "https"
+		}
+		if ms.Spec.AlertmanagerConfig.WebTLSConfig != nil {
+			alertmanagerScheme = "https"
+		}
+
+		prometheusURL := fmt.Sprintf("%s://%s-prometheus.%s.svc:9090", prometheusScheme, ms.Name, ms.Namespace)
+		if cfg.Domain == uiv1alpha1.Korrel8rMetricDomain {
+			store.Endpoints = []korrel8rEndpoint{{Key: "metric", URL: prometheusURL}}
+			break
+		}
+		store.Endpoints = []korrel8rEndpoint{
+			{Key: "metrics", URL: prometheusURL},
+			{Key: "alertmanager", URL: fmt.Sprintf("%s://%s-alertmanager.%s.svc:9093", alertmanagerScheme, ms.Name, ms.Namespace)},
+		}
+
+	case cfg.ThanosQuerier != nil:
+		tq := &monv1alpha1.ThanosQuerier{}
+		if err := k.Get(ctx, types.NamespacedName{Name: cfg.ThanosQuerier.Name, Namespace: cfg.ThanosQuerier.Namespace}, tq); err != nil {
+			return store, fmt.Errorf("failed to get ThanosQuerier for the %s store: %w", cfg.Domain, err)
+		}
+
+		scheme := "http"
+		if tq.Spec.WebTLSConfig != nil {
+			scheme = "https"
+		}
+		store.Endpoints = []korrel8rEndpoint{{Key: "metric", URL: fmt.Sprintf("%s://thanos-querier-%s.%s.svc:10902", scheme, tq.Name, tq.Namespace)}}
+
+	case cfg.LokiStack != nil:
+		store.Endpoints = []korrel8rEndpoint{{Key: "lokiStack", URL: fmt.Sprintf("https://%s-gateway-http.%s.svc:8080", cfg.LokiStack.Name, cfg.LokiStack.Namespace)}}
+
+	case cfg.TempoStack != nil:
+		tenant := cfg.TempoStack.Tenant
+		if tenant == "" {
+			tenant = "platform"
+		}
+		store.Endpoints = []korrel8rEndpoint{{Key: "tempoStack", URL: tempoStackSearchURL("tempo-"+cfg.TempoStack.Name+"-gateway", cfg.TempoStack.Namespace, tenant)}}
+
+	default:
+		return store, fmt.Errorf("no backend configured for the %s store", cfg.Domain)
+	}
+
+	// Plain HTTP backends don't need a certificate authority.
+	if !slices.ContainsFunc(store.Endpoints, func(e korrel8rEndpoint) bool { return strings.HasPrefix(e.URL, "https://") }) {
+		return store, nil
+	}
+
+	store.CertificateAuthority = korrel8rServiceCAPath
+	if ca := cfg.CertificateAuthority; ca != nil {
+		volumeName := "korrel8r-ca-" + string(cfg.Domain)
+		switch {
+		case ca.ConfigMap != nil:
+			store.CertificateAuthority = fmt.Sprintf("%s/%s/%s", korrel8rCAMountDir, cfg.Domain, ca.ConfigMap.Key)
+			store.CAVolume = &corev1.Volume{
+				Name: volumeName,
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: ca.ConfigMap.LocalObjectReference,
+						Items:                []corev1.KeyToPath{{Key: ca.ConfigMap.Key, Path: ca.ConfigMap.Key}},
+					},
+				},
+			}
+		case ca.Secret != nil:
+			store.CertificateAuthority = fmt.Sprintf("%s/%s/%s", korrel8rCAMountDir, cfg.Domain, ca.Secret.Key)
+			store.CAVolume = &corev1.Volume{
+				Name: volumeName,
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: ca.Secret.Name,
+						Items:      []corev1.KeyToPath{{Key: ca.Secret.Key, Path: ca.Secret.Key}},
+					},
+				},
+			}
+		}
+	}
+
+	return store, nil
+}
+
+// getKorrel8rRules returns the ConfigMaps containing the custom korrel8r
+// rules referenced by the TroubleshootingPanel configuration.
+func getKorrel8rRules(ctx context.Context, k client.Client, cfg *uiv1alpha1.TroubleshootingPanelConfig, namespace string) ([]*corev1.ConfigMap, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	rules := make([]*corev1.ConfigMap, 0, len(cfg.Rules))
+	for _, ref := range cfg.Rules {
+		cm := &corev1.ConfigMap{}
+		if err := k.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, cm); err != nil {
+			return nil, fmt.Errorf("failed to get korrel8r rules ConfigMap %s/%s: %w", namespace, ref.Name, err)
+		}
+		rules = append(rules, cm)
+	}
+
+	return rules, nil
+}
+
+// korrel8rIncludes returns the rule files included by the korrel8r
+// configuration.
+func korrel8rIncludes(rules []*corev1.ConfigMap) []string {
+	includes := []string{korrel8rDefaultRules}
+	for _, cm := range rules {
+		keys := make([]string, 0, len(cm.Data))
+		for key := range cm.Data {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+
+		for _, key := range keys {
+			includes = append(includes, fmt.Sprintf("%s/%s/%s", korrel8rRulesMountDir, cm.Name, key))
+		}
+	}
+
+	return includes
+}
+
+func tempoStackSearchURL(service, namespace, tenant string) string {
+	return fmt.Sprintf("https://%s.%s.svc.cluster.local:8080/api/traces/v1/%s/tempo/api/search", service, namespace, tenant)
+}
+
 func getLokiServiceName(ctx context.Context, k client.Client, ns string) (string, error) {
 
 	serviceList := &corev1.ServiceList{}
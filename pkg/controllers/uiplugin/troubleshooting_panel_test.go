@@ -0,0 +1,165 @@
+package uiplugin
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	monv1alpha1 "github.com/rhobs/observability-operator/pkg/apis/monitoring/v1alpha1"
+	uiv1alpha1 "github.com/rhobs/observability-operator/pkg/apis/uiplugin/v1alpha1"
+)
+
+func TestKorrel8rStores(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NilError(t, monv1alpha1.AddToScheme(scheme))
+
+	k := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&monv1alpha1.MonitoringStack{
+			ObjectMeta: metav1.ObjectMeta{Name: "ms", Namespace: "team-a"},
+		},
+		&monv1alpha1.ThanosQuerier{
+			ObjectMeta: metav1.ObjectMeta{Name: "tq", Namespace: "team-a"},
+			Spec: monv1alpha1.ThanosQuerierSpec{
+				WebTLSConfig: &monv1alpha1.WebTLSConfig{},
+			},
+		},
+	).Build()
+
+	cfg := &uiv1alpha1.TroubleshootingPanelConfig{
+		Stores: []uiv1alpha1.Korrel8rStore{
+			{
+				Domain:        uiv1alpha1.Korrel8rMetricDomain,
+				ThanosQuerier: &uiv1alpha1.NamespacedReference{Name: "tq", Namespace: "team-a"},
+				CertificateAuthority: &uiv1alpha1.CertificateAuthorityReference{
+					ConfigMap: &corev1.ConfigMapKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "thanos-ca"},
+						Key:                  "ca.crt",
+					},
+				},
+			},
+			{
+				Domain:          uiv1alpha1.Korrel8rAlertDomain,
+				MonitoringStack: &uiv1alpha1.NamespacedReference{Name: "ms", Namespace: "team-a"},
+			},
+			{
+				Domain:     uiv1alpha1.Korrel8rTraceDomain,
+				TempoStack: &uiv1alpha1.TempoStackReference{Name: "traces", Namespace: "team-b", Tenant: "dev"},
+			},
+		},
+	}
+
+	info := UIPluginInfo{}
+	stores, err := korrel8rStores(context.Background(), k, cfg, info)
+	assert.NilError(t, err)
+	info.Korrel8rStores = stores
+
+	cm, err := newKorrel8rConfigMap("korrel8r", "ns", info)
+	assert.NilError(t, err)
+	config := cm.Data[Korrel8rConfigFileName]
+
+	for _, expected := range []string{
+		"  - domain: metric\n    metric: https://thanos-querier-tq.team-a.svc:10902\n    certificateAuthority: /etc/korrel8r/ca/metric/ca.crt\n",
+		"  - domain: alert\n    metrics: http://ms-prometheus.team-a.svc:9090\n    alertmanager: http://ms-alertmanager.team-a.svc:9093\n  - domain: log\n",
+		"  - domain: trace\n    tempoStack: https://tempo-traces-gateway.team-b.svc.cluster.local:8080/api/traces/v1/dev/tempo/api/search\n",
+		"    lokiStack: https://logging-loki-gateway-http.openshift-logging.svc:8080\n",
+	} {
+		assert.Assert(t, strings.Contains(config, expected), "expected %q in:\n%s", expected, config)
+	}
+
+	deployment := newKorrel8rDeployment("korrel8r", "ns", info, cm)
+	volumes := deployment.Spec.Template.Spec.Volumes
+	assert.Equal(t, volumes[len(volumes)-1].Name, "korrel8r-ca-metric")
+	mounts := deployment.Spec.Template.Spec.Containers[0].VolumeMounts
+	assert.Equal(t, mounts[len(mounts)-1].MountPath, "/etc/korrel8r/ca/metric")
+
+	_, err = korrel8rStores(context.Background(), k, &uiv1alpha1.TroubleshootingPanelConfig{
+		Stores: []uiv1alpha1.Korrel8rStore{
+			{
+				Domain:          uiv1alpha1.Korrel8rMetricDomain,
+				MonitoringStack: &uiv1alpha1.NamespacedReference{Name: "missing", Namespace: "team-a"},
+			},
+		},
+	}, UIPluginInfo{})
+	assert.ErrorContains(t, err, "failed to get MonitoringStack")
+}
+
+func TestKorrel8rRules(t *testing.T) {
+	rules := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-rules", Namespace: "ns"},
+		Data: map[string]string{
+			"b.yaml": "rules: []",
+			"a.yaml": "rules: []",
+		},
+	}
+	k := fake.NewClientBuilder().WithObjects(rules).Build()
+
+	cfg := &uiv1alpha1.TroubleshootingPanelConfig{
+		Rules: []uiv1alpha1.Korrel8rRulesReference{{Name: "my-rules"}},
+	}
+	info := UIPluginInfo{}
+	var err error
+	info.Korrel8rRules, err = getKorrel8rRules(context.Background(), k, cfg, "ns")
+	assert.NilError(t, err)
+
+	cm, err := newKorrel8rConfigMap("korrel8r", "ns", info)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasSuffix(cm.Data[Korrel8rConfigFileName],
+		"include:\n  - /etc/korrel8r/rules/all.yaml\n  - /etc/korrel8r/custom-rules/my-rules/a.yaml\n  - /etc/korrel8r/custom-rules/my-rules/b.yaml\n"))
+
+	deployment := newKorrel8rDeployment("korrel8r", "ns", info, cm)
+	mounts := deployment.Spec.Template.Spec.Containers[0].VolumeMounts
+	assert.Equal(t, mounts[len(mounts)-1].MountPath, "/etc/korrel8r/custom-rules/my-rules")
+
+	// Updating the rules rolls out the deployment.
+	hash := deployment.Spec.Template.Annotations[annotationPrefix+"config-hash"]
+	info.Korrel8rRules[0].Data["a.yaml"] = "rules: [{}]"
+	deployment = newKorrel8rDeployment("korrel8r", "ns", info, cm)
+	assert.Assert(t, hash != deployment.Spec.Template.Annotations[annotationPrefix+"config-hash"])
+
+	_, err = getKorrel8rRules(context.Background(), k, &uiv1alpha1.TroubleshootingPanelConfig{
+		Rules: []uiv1alpha1.Korrel8rRulesReference{{Name: "missing"}},
+	}, "ns")
+	assert.ErrorContains(t, err, "failed to get korrel8r rules ConfigMap ns/missing")
+}
+
+func TestKorrel8rDeploymentConfig(t *testing.T) {
+	info := UIPluginInfo{
+		Korrel8rConfig: &uiv1alpha1.Korrel8rConfig{
+			Replicas:  ptr.To(int32(2)),
+			Verbosity: ptr.To(int32(3)),
+			Timeout:   "30s",
+			Resources: &corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")},
+			},
+			TopologySpreadConstraints: []corev1.TopologySpreadConstraint{
+				{MaxSkew: 1, TopologyKey: "topology.kubernetes.io/zone", WhenUnsatisfiable: corev1.ScheduleAnyway},
+			},
+		},
+	}
+
+	cm, err := newKorrel8rConfigMap("korrel8r", "ns", info)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasSuffix(cm.Data[Korrel8rConfigFileName], "\ntuning:\n  requestTimeout: 30s\n"))
+
+	deployment := newKorrel8rDeployment("korrel8r", "ns", info, cm)
+	assert.Equal(t, *deployment.Spec.Replicas, int32(2))
+	container := deployment.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, container.Command[len(container.Command)-1], "--verbose=3")
+	assert.Equal(t, container.Resources.Requests.Memory().String(), "128Mi")
+	assert.Equal(t, len(deployment.Spec.Template.Spec.TopologySpreadConstraints), 1)
+	assert.Equal(t, korrel8rDeploymentSettings(info).replicas, int32(2))
+
+	assert.Equal(t, korrel8rDeploymentSettings(UIPluginInfo{}).replicas, int32(1))
+	deployment = newKorrel8rDeployment("korrel8r", "ns", UIPluginInfo{}, cm)
+	assert.Equal(t, *deployment.Spec.Replicas, int32(1))
+	command := deployment.Spec.Template.Spec.Containers[0].Command
+	assert.Equal(t, command[len(command)-1], "--config=/config/korrel8r.yaml")
+}
@@ -109,6 +109,12 @@ func WithThanosQuerierImage(image string) func(*OperatorConfiguration) {
 	}
 }
 
+func WithOAuthProxyImage(image string) func(*OperatorConfiguration) {
+	return func(oc *OperatorConfiguration) {
+		oc.ThanosQuerier.OAuthProxyImage = image
+	}
+}
+
 func WithMetricsAddr(addr string) func(*OperatorConfiguration) {
 	return func(oc *OperatorConfiguration) {
 		oc.MetricsAddr = addr
@@ -264,6 +270,22 @@ func New(ctx context.Context, cfg *OperatorConfiguration) (*Operator, error) {
 			&v1.Secret{}: cache.ByObject{
 				Label: labels.Everything(),
 			},
+			// User-provided ConfigMaps (e.g. korrel8r rules)
+			// are read from the operator namespace.
+			&v1.ConfigMap{}: cache.ByObject{
+				Namespaces: map[string]cache.Config{
+					cfg.Namespace: {
+						LabelSelector: labels.Everything(),
+					},
+					cache.AllNamespaces: {},
+				},
+			},
+			// Namespaces are watched by the ThanosQuerier
+			// controller to select MonitoringStacks by
+			// namespace labels.
+			&v1.Namespace{}: cache.ByObject{
+				Label: labels.Everything(),
+			},
 			// The user-facing CRDs need to be
 			// cached in absence of any labels.
 			&stack.MonitoringStack{}: cache.ByObject{
@@ -317,7 +339,10 @@ func New(ctx context.Context, cfg *OperatorConfiguration) (*Operator, error) {
 		return nil, fmt.Errorf("unable to register monitoring stack controller: %w", err)
 	}
 
-	if err := tqctrl.RegisterWithManager(mgr, tqctrl.Options{Thanos: cfg.ThanosQuerier}); err != nil {
+	if err := tqctrl.RegisterWithManager(mgr, tqctrl.Options{
+		Thanos:           cfg.ThanosQuerier,
+		OpenShiftEnabled: cfg.FeatureGates.OpenShift.Enabled,
+	}); err != nil {
 		return nil, fmt.Errorf("unable to register the thanos querier controller with the manager: %w", err)
 	}
 
@@ -337,13 +362,14 @@ func New(ctx context.Context, cfg *OperatorConfiguration) (*Operator, error) {
 		if err = watcher.SetupWithManager(mgr); err != nil {
 			return nil, fmt.Errorf("unable to setup TLS profile watcher: %w", err)
 		}
+	}
 
-		if err := uictrl.RegisterWithManager(mgr, uictrl.Options{PluginsConf: cfg.UIPlugins}); err != nil {
-			return nil, fmt.Errorf("unable to register observability-ui-plugin controller: %w", err)
-		}
-	} else {
-		setupLog := ctrl.Log.WithName("setup")
-		setupLog.Info("OpenShift feature gate is disabled, UIPlugins are not enabled")
+	// Without OpenShift, the UIPlugins run in standalone mode.
+	if err := uictrl.RegisterWithManager(mgr, uictrl.Options{
+		PluginsConf:      cfg.UIPlugins,
+		OpenShiftEnabled: cfg.FeatureGates.OpenShift.Enabled,
+	}); err != nil {
+		return nil, fmt.Errorf("unable to register observability-ui-plugin controller: %w", err)
 	}
 
 	if cfg.FeatureGates.OpenShift.Enabled {
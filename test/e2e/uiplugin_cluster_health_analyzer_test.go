@@ -98,6 +98,10 @@ func resetMonitoringUIPlugin(t *testing.T) *uiv1.UIPlugin {
 			Monitoring: &uiv1.MonitoringConfig{
 				ClusterHealthAnalyzer: &uiv1.ClusterHealthAnalyzerReference{
 					Enabled: true,
+					// The deployment only becomes ready when the analyzer
+					// accepts the flags.
+					LogLevel: "debug",
+					Interval: "30s",
 				},
 			},
 		},